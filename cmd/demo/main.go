@@ -2,24 +2,50 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/baseline"
+	"github.com/shenbo/sql-review-learning-demo/pkg/config"
 	"github.com/shenbo/sql-review-learning-demo/pkg/rules/mysql"
+	"github.com/shenbo/sql-review-learning-demo/pkg/schemaload"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var (
 	version = "1.0.0"
 
 	// Global flags
-	verbose bool
-	format  string
+	verbose    bool
+	format     string
+	configDir  string
+	policyName string
+
+	// Review flags, shared by check and baseline
+	schemaPath  string
+	suppression advisor.SuppressionOptions
+	ruleTimeout time.Duration
+	workers     int
+
+	// Check flags
+	baselinePath string
+	strict       bool
+
+	// Baseline flags
+	baselineOutput string
 )
 
+// defaultBaselinePath is the baseline file written by the baseline command.
+const defaultBaselinePath = ".sql-review-baseline.json"
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -43,10 +69,36 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().StringVar(&format, "format", "text", "output format (text, json)")
+	rootCmd.PersistentFlags().StringVar(&configDir, "config", "config", "directory of rules.yaml; built-in defaults are used without it")
+	rootCmd.PersistentFlags().StringVar(&policyName, "policy", "", "review policy of rules.yaml to apply (default: the policy bound to APP_ENV, if any)")
+
+	// Check flags
+	addReviewFlags(checkCmd)
+	checkCmd.Flags().StringVar(&baselinePath, "baseline", "", "only report issues that are not in this baseline file")
+	checkCmd.Flags().BoolVar(&strict, "strict", false, "exit with an error when a rule failed to run")
+
+	// Baseline flags
+	addReviewFlags(baselineCmd)
+	baselineCmd.Flags().StringVarP(&baselineOutput, "output", "o", defaultBaselinePath, "baseline file to write")
 
 	// Add subcommands
 	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(baselineCmd)
 	rootCmd.AddCommand(rulesCmd)
+	rootCmd.AddCommand(schemaCmd)
+	policyCmd.AddCommand(policyListCmd)
+	policyCmd.AddCommand(policyExportCmd)
+	rootCmd.AddCommand(policyCmd)
+}
+
+// addReviewFlags adds the flags that change what a review reports.
+func addReviewFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&schemaPath, "schema", "", "baseline schema: a directory of CREATE statements, a .sql file or a JSON schema dump")
+	cmd.Flags().BoolVar(&suppression.ReportSuppressed, "show-suppressed", false, "report suppressed issues as INFO instead of hiding them")
+	cmd.Flags().BoolVar(&suppression.RequireJustification, "require-justification", false, "ignore suppression comments without a justification")
+	cmd.Flags().BoolVar(&suppression.ReportUnused, "report-unused-suppressions", false, "report suppression comments that match no issue")
+	cmd.Flags().DurationVar(&ruleTimeout, "rule-timeout", 0, "time limit for each rule, e.g. 5s (0 means no limit)")
+	cmd.Flags().IntVar(&workers, "workers", 0, "number of goroutines rules run on, listener rules are split across that many walks of each statement (0 means one per CPU)")
 }
 
 // checkCmd represents the check command
@@ -60,30 +112,287 @@ reporting any violations found.
 Examples:
   sql-review-demo check examples/good_examples.sql
   sql-review-demo check examples/bad_examples.sql
-  sql-review-demo check --format json examples/mixed_examples.sql`,
+  sql-review-demo check --format json examples/mixed_examples.sql
+  sql-review-demo check --schema schema/ migrations/0042.sql
+  sql-review-demo check --baseline .sql-review-baseline.json migrations/*.sql
+
+With --schema, schema-aware checks run against the given baseline schema
+instead of a live database. With --baseline, issues recorded by the
+baseline command are not reported again.
+
+A rule that fails to run is reported as an internal error issue; with
+--strict the command then exits with an error.
+
+Issues can be suppressed with comments in the SQL file:
+  -- sql-review:disable-next-line mysql.table.require-pk -- legacy table
+  -- sql-review:disable mysql.naming.convention
+  -- sql-review:enable mysql.naming.convention
+  -- sql-review:disable-file mysql.select.performance`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runCheck,
 }
 
+// baselineCmd represents the baseline command
+var baselineCmd = &cobra.Command{
+	Use:   "baseline [file]",
+	Short: "Record the current issues as accepted",
+	Long: `Review one or more SQL files and write the issues found to a baseline
+file. Later check runs with --baseline only report issues that are not in
+the baseline, which helps adopting the review on an existing schema.
+
+Issues are recorded by rule, file, statement and message rather than by
+line, so moving or reformatting statements does not bring them back.
+
+Examples:
+  sql-review-demo baseline migrations/*.sql
+  sql-review-demo baseline -o legacy-baseline.json schema/*.sql`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runBaseline,
+}
+
 func runCheck(cmd *cobra.Command, args []string) error {
-	// Create advisor and register rules
-	sqlAdvisor := advisor.NewDefaultAdvisor()
-	sqlAdvisor.RegisterRule(mysql.NewTableRequirePKRule())
+	rules, err := loadRules()
+	if err != nil {
+		return err
+	}
 
+	metadata, err := loadSchema()
+	if err != nil {
+		return err
+	}
+
+	var known *baseline.Baseline
+	if baselinePath != "" {
+		known, err = baseline.Load(baselinePath)
+		if err != nil {
+			return fmt.Errorf("failed to load baseline %s: %w", baselinePath, err)
+		}
+	}
+
+	failed := 0
 	for _, filePath := range args {
-		if err := checkFile(sqlAdvisor, filePath); err != nil {
+		advices, err := checkFile(rules, metadata, known, filePath)
+		if err != nil {
 			return fmt.Errorf("failed to check file %s: %w", filePath, err)
 		}
+		failed += len(advisor.InternalErrors(advices))
 	}
 
+	if strict && failed > 0 {
+		return fmt.Errorf("%d rule run(s) failed, see the internal errors above", failed)
+	}
 	return nil
 }
 
-func checkFile(sqlAdvisor *advisor.DefaultAdvisor, filePath string) error {
+func runBaseline(cmd *cobra.Command, args []string) error {
+	rules, err := loadRules()
+	if err != nil {
+		return err
+	}
+
+	metadata, err := loadSchema()
+	if err != nil {
+		return err
+	}
+
+	accepted := baseline.New(baselineOutput)
+	for _, filePath := range args {
+		sql, advices, err := reviewFile(rules, metadata, filePath)
+		if err != nil {
+			return fmt.Errorf("failed to review file %s: %w", filePath, err)
+		}
+		if err := accepted.Add(filePath, sql, advices); err != nil {
+			return fmt.Errorf("failed to fingerprint file %s: %w", filePath, err)
+		}
+	}
+
+	if err := accepted.Save(baselineOutput); err != nil {
+		return fmt.Errorf("failed to write baseline %s: %w", baselineOutput, err)
+	}
+	fmt.Printf("Recorded %d issue(s) from %d file(s) in %s\n", len(accepted.Fingerprints), len(args), baselineOutput)
+	return nil
+}
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "List and export review policies",
+	Long: `Review policies adjust the rules of rules.yaml for an environment, a
+connection or a database. A policy may extend one of the built-in templates:
+
+  policies:
+    prod:
+      extends: mysql-prod
+      rules:
+        mysql:
+          mysql.table.comment:
+            level: WARNING`,
+}
+
+var policyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the built-in policy templates and the policies of rules.yaml",
+	Args:  cobra.NoArgs,
+	RunE:  runPolicyList,
+}
+
+var policyExportCmd = &cobra.Command{
+	Use:   "export NAME",
+	Short: "Print the fully resolved rules of a policy or a built-in template",
+	Long: `Print every rule of a policy of rules.yaml or of a built-in template, with
+its level and all its payload options, as resolved over the rules of rules.yaml.
+Disabled rules are listed with enabled: false. The output can be pasted under
+policies in rules.yaml.
+
+Examples:
+  sql-review-demo policy export mysql-prod
+  sql-review-demo policy export prod > prod-policy.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPolicyExport,
+}
+
+func runPolicyList(cmd *cobra.Command, args []string) error {
+	review, err := config.NewLoader(configDir).LoadReview()
+	if err != nil {
+		return err
+	}
+
+	fmt.Print("\n=== Built-in Policy Templates ===\n\n")
+	for _, template := range config.Templates() {
+		fmt.Printf("%s - %s\n", template.Name, template.Title)
+		fmt.Printf("   %s\n\n", template.Description)
+	}
+
+	fmt.Print("=== Policies in rules.yaml ===\n\n")
+	if len(review.Policies) == 0 {
+		fmt.Println("No policies configured.")
+		return nil
+	}
+	var names []string
+	for name := range review.Policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if extends := review.Policies[name].Extends; extends != "" {
+			fmt.Printf("%s (extends %s)\n", name, extends)
+		} else {
+			fmt.Println(name)
+		}
+	}
+	return nil
+}
+
+func runPolicyExport(cmd *cobra.Command, args []string) error {
+	review, err := config.NewLoader(configDir).LoadReview()
+	if err != nil {
+		return err
+	}
+
+	name := args[0]
+	policy, ok := review.Policies[name]
+	if !ok {
+		if _, err := config.LookupTemplate(name); err != nil {
+			return fmt.Errorf("%q is neither a policy of rules.yaml nor a built-in template: %w", name, err)
+		}
+		policy = config.PolicyConfig{Extends: name}
+	}
+
+	rules, err := review.PolicyRules(policy, mysql.DefaultRules())
+	if err != nil {
+		return fmt.Errorf("policy %s: %w", name, err)
+	}
+	exported, err := config.ExportRules(rules)
+	if err != nil {
+		return fmt.Errorf("policy %s: %w", name, err)
+	}
+
+	fmt.Printf("# Resolved rules of %s\n", name)
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config.PolicyConfig{Rules: exported}); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// loadRules returns the rules enabled in rules.yaml of --config, with their
+// configured levels and payloads, as adjusted by the review policy given by
+// --policy or else bound to the APP_ENV environment.
+func loadRules() ([]*advisor.Rule, error) {
+	loader := config.NewLoader(configDir)
+	review, err := loader.LoadReview()
+	if err != nil {
+		return nil, err
+	}
+	policies, err := review.BuildPolicies(loader.GetEnv(), mysql.DefaultRules())
+	if err != nil {
+		return nil, err
+	}
+
+	name := policyName
+	if name == "" {
+		name = policies.Resolve("", "")
+	}
+	rules, ok := policies.Rules(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown policy %q, available policies: %s", name, strings.Join(policies.Names(), ", "))
+	}
+	if verbose && name != "" {
+		fmt.Fprintf(os.Stderr, "Using review policy %s\n", name)
+	}
+	return rules, nil
+}
+
+// loadSchema loads the baseline schema given by --schema, nil without one.
+func loadSchema() (*advisor.DatabaseMetadata, error) {
+	if schemaPath == "" {
+		return nil, nil
+	}
+	metadata, err := schemaload.Load(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema %s: %w", schemaPath, err)
+	}
+	return metadata, nil
+}
+
+// checkFile reviews one SQL file, prints the advice that is not in the
+// baseline and returns it.
+func checkFile(rules []*advisor.Rule, metadata *advisor.DatabaseMetadata, known *baseline.Baseline, filePath string) ([]*advisor.Advice, error) {
+	sql, advices, err := reviewFile(rules, metadata, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if sql == "" {
+		return nil, nil
+	}
+
+	// Drop the issues accepted by the baseline
+	if known != nil {
+		total := len(advices)
+		advices, err = known.Filter(filePath, sql, advices)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply baseline: %w", err)
+		}
+		if verbose && total > len(advices) {
+			fmt.Printf("%d known issue(s) in %s are in the baseline\n", total-len(advices), filePath)
+		}
+	}
+
+	// Output results
+	if err := outputResults(filePath, advices); err != nil {
+		return nil, fmt.Errorf("failed to output results: %w", err)
+	}
+
+	return advices, nil
+}
+
+// reviewFile reviews one SQL file and returns its content with the advice.
+// Empty files are skipped and returned as "".
+func reviewFile(rules []*advisor.Rule, metadata *advisor.DatabaseMetadata, filePath string) (string, []*advisor.Advice, error) {
 	// Read SQL file
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return "", nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	sql := string(content)
@@ -91,29 +400,28 @@ func checkFile(sqlAdvisor *advisor.DefaultAdvisor, filePath string) error {
 		if verbose {
 			fmt.Printf("Skipping empty file: %s\n", filePath)
 		}
-		return nil
+		return "", nil, nil
 	}
 
 	// Create check context
-	checkCtx := &advisor.Context{
+	checkCtx := advisor.Context{
 		SQL:          sql,
-		Engine:       "mysql",
+		Engine:       advisor.MySQL,
 		DatabaseName: "demo",
 		Rules:        []string{}, // Empty means use all rules
+		Metadata:     metadata,   // Baseline schema, nil reviews without one
+		Suppression:  suppression,
 	}
 
 	// Execute review
-	advices, err := sqlAdvisor.Check(context.Background(), checkCtx)
+	ctx := advisor.WithRuleTimeout(context.Background(), ruleTimeout)
+	ctx = advisor.WithMaxWorkers(ctx, workers)
+	advices, err := advisor.SQLReviewCheck(ctx, rules, checkCtx)
 	if err != nil {
-		return fmt.Errorf("failed to execute review: %w", err)
-	}
-
-	// Output results
-	if err := outputResults(filePath, advices); err != nil {
-		return fmt.Errorf("failed to output results: %w", err)
+		return "", nil, fmt.Errorf("failed to execute review: %w", err)
 	}
 
-	return nil
+	return sql, advices, nil
 }
 
 func outputResults(filePath string, advices []*advisor.Advice) error {
@@ -121,7 +429,7 @@ func outputResults(filePath string, advices []*advisor.Advice) error {
 
 	switch format {
 	case "json":
-		return outputJSON(fileName, advices)
+		return outputJSON(os.Stdout, fileName, advices)
 	case "text":
 		return outputText(fileName, advices)
 	default:
@@ -157,27 +465,47 @@ func outputText(fileName string, advices []*advisor.Advice) error {
 	return nil
 }
 
-func outputJSON(fileName string, advices []*advisor.Advice) error {
-	// Simple JSON output (we could use encoding/json for more complex formatting)
-	fmt.Printf(`{"file":"%s","issues":%d,"results":[`, fileName, len(advices))
+// checkResultJSON is the JSON output of the check command for one file.
+type checkResultJSON struct {
+	File    string        `json:"file"`
+	Issues  int           `json:"issues"`
+	Results []*adviceJSON `json:"results"`
+}
 
-	for i, advice := range advices {
-		if i > 0 {
-			fmt.Print(",")
-		}
-		fmt.Printf(`{"level":"%s","title":"%s","message":"%s","rule_id":"%s"`,
-			advice.Level, advice.Title, advice.Message, advice.RuleID)
-		if advice.Line > 0 {
-			fmt.Printf(`,"line":%d`, advice.Line)
-		}
-		if advice.Column > 0 {
-			fmt.Printf(`,"column":%d`, advice.Column)
-		}
-		fmt.Print("}")
+// adviceJSON is one issue of the JSON output of the check command.
+type adviceJSON struct {
+	Level   advisor.Level `json:"level"`
+	Title   string        `json:"title"`
+	Message string        `json:"message"`
+	RuleID  string        `json:"rule_id"`
+	Code    int32         `json:"code"`
+	Line    int           `json:"line,omitempty"`
+	Column  int           `json:"column,omitempty"`
+}
+
+func outputJSON(w io.Writer, fileName string, advices []*advisor.Advice) error {
+	result := checkResultJSON{File: fileName, Issues: len(advices), Results: []*adviceJSON{}}
+	for _, advice := range advices {
+		result.Results = append(result.Results, &adviceJSON{
+			Level:   advice.Level,
+			Title:   advice.Title,
+			Message: advice.Message,
+			RuleID:  advice.RuleID,
+			Code:    advice.Code,
+			Line:    advice.Line,
+			Column:  advice.Column,
+		})
 	}
+	return writeJSON(w, result)
+}
 
-	fmt.Println("]}")
-	return nil
+// writeJSON writes v as indented JSON without escaping HTML characters,
+// which appear in SQL messages such as "a <> b".
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
 }
 
 func getIcon(level advisor.Level) string {
@@ -203,11 +531,11 @@ This helps you understand what rules are available and how they work.`,
 }
 
 func runRules(cmd *cobra.Command, args []string) error {
-	// Create advisor and register rules
-	sqlAdvisor := advisor.NewDefaultAdvisor()
-	sqlAdvisor.RegisterRule(mysql.NewTableRequirePKRule())
-
-	rules := sqlAdvisor.ListRules()
+	configured, err := loadRules()
+	if err != nil {
+		return err
+	}
+	rules := advisor.DescribeRules(configured)
 
 	if format == "json" {
 		return outputRulesJSON(rules)
@@ -216,8 +544,8 @@ func runRules(cmd *cobra.Command, args []string) error {
 	return outputRulesText(rules)
 }
 
-func outputRulesText(rules []advisor.Rule) error {
-	fmt.Println("\n=== Available SQL Review Rules ===\n")
+func outputRulesText(rules []*advisor.RuleInfo) error {
+	fmt.Print("\n=== Available SQL Review Rules ===\n\n")
 
 	if len(rules) == 0 {
 		fmt.Println("No rules registered.")
@@ -225,10 +553,10 @@ func outputRulesText(rules []advisor.Rule) error {
 	}
 
 	for i, rule := range rules {
-		icon := getIcon(rule.Level())
-		fmt.Printf("%d. %s [%s] %s\n", i+1, icon, rule.Level(), rule.Name())
-		fmt.Printf("   ID: %s\n", rule.ID())
-		fmt.Printf("   Description: %s\n", rule.Description())
+		icon := getIcon(rule.Level)
+		fmt.Printf("%d. %s [%s] %s\n", i+1, icon, rule.Level, rule.Title)
+		fmt.Printf("   ID: %s\n", rule.Type)
+		fmt.Printf("   Description: %s\n", rule.Description)
 		fmt.Println()
 	}
 
@@ -236,18 +564,47 @@ func outputRulesText(rules []advisor.Rule) error {
 	return nil
 }
 
-func outputRulesJSON(rules []advisor.Rule) error {
-	fmt.Print(`{"rules":[`)
+// ruleJSON is one rule of the JSON output of the rules command.
+type ruleJSON struct {
+	ID          advisor.Type  `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Level       advisor.Level `json:"level"`
+}
 
-	for i, rule := range rules {
-		if i > 0 {
-			fmt.Print(",")
-		}
-		fmt.Printf(`{"id":"%s","name":"%s","description":"%s","level":"%s"}`,
-			rule.ID(), rule.Name(), rule.Description(), rule.Level())
+func outputRulesJSON(rules []*advisor.RuleInfo) error {
+	result := struct {
+		Rules []*ruleJSON `json:"rules"`
+		Total int         `json:"total"`
+	}{Rules: []*ruleJSON{}, Total: len(rules)}
+	for _, rule := range rules {
+		result.Rules = append(result.Rules, &ruleJSON{
+			ID:          rule.Type,
+			Name:        rule.Title,
+			Description: rule.Description,
+			Level:       rule.Level,
+		})
 	}
+	return writeJSON(os.Stdout, result)
+}
 
-	fmt.Printf(`],"total":%d}`, len(rules))
-	fmt.Println()
-	return nil
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of rules.yaml",
+	Long: `Print the JSON Schema of rules.yaml, with the rules of every engine and the
+options, defaults and constraints of their payloads. Point your editor at it
+to validate and autocomplete rules.yaml:
+
+  sql-review-demo schema > config/rules.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: runSchema,
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	schema, err := config.RulesSchema()
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(os.Stdout, schema)
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/rules/mysql"
+)
+
+// TestOutputJSON 测试 check --format json 的输出是合法的 JSON，消息中的引号被转义
+func TestOutputJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.sql")
+	content := "CREATE TABLE \"Users\" (id INT PRIMARY KEY) COMMENT 'users';\nCREATE TABLEE orders (id INT);\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{path, filepath.Join("..", "..", "examples", "mixed_examples.sql")} {
+		_, advices, err := reviewFile(mysql.DefaultRules(), nil, file)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := outputJSON(&buf, filepath.Base(file), advices); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var result checkResultJSON
+		if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+			t.Fatalf("Invalid JSON output of %s: %v\n%s", file, err, buf.String())
+		}
+		if result.File != filepath.Base(file) || result.Issues != len(advices) || len(result.Results) != len(advices) {
+			t.Errorf("Unexpected JSON output of %s: %s", file, buf.String())
+		}
+		for i, advice := range advices {
+			if result.Results[i].Message != advice.Message || result.Results[i].Line != advice.Line {
+				t.Errorf("Expected %+v, got %+v", advice, result.Results[i])
+			}
+		}
+	}
+
+	_, advices, err := reviewFile(mysql.DefaultRules(), nil, path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var syntaxError *advisor.Advice
+	for _, advice := range advices {
+		if advice.Code == advisor.CodeStatementSyntaxError {
+			syntaxError = advice
+		}
+	}
+	if syntaxError == nil || !strings.Contains(syntaxError.Message, `"TABLEE"`) {
+		t.Fatalf("Expected a syntax error near \"TABLEE\", got %v", advices)
+	}
+}
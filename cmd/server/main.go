@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
 	"github.com/shenbo/sql-review-learning-demo/pkg/api"
 	"github.com/shenbo/sql-review-learning-demo/pkg/config"
 	"github.com/shenbo/sql-review-learning-demo/pkg/database"
@@ -28,12 +27,15 @@ func main() {
 	dbManager := database.NewDatabaseManagerWithConfig(cfg.Database)
 	defer dbManager.Close()
 
-	// 创建审查器并注册规则
-	sqlAdvisor := advisor.NewDefaultAdvisor()
-	sqlAdvisor.RegisterRule(mysql.NewTableRequirePKRule())
+	// 按规则配置决定启用的规则、级别和参数，并构建各审查策略的规则
+	policies, err := cfg.BuildPolicies(loader.GetEnv(), mysql.DefaultRules())
+	if err != nil {
+		log.Fatal("Failed to load rules:", err)
+	}
+	rules, _ := policies.Rules("")
 
 	// 创建HTTP服务器
-	server := api.NewServer(dbManager, sqlAdvisor)
+	server := api.NewServer(dbManager, policies)
 
 	r := gin.Default()
 
@@ -97,13 +99,17 @@ func main() {
 	log.Printf("Starting SQL Review Learning Demo API Server on %s", addr)
 	log.Printf("Environment: %s", loader.GetEnv())
 	log.Printf("Gin Mode: %s", cfg.Server.Mode)
+	log.Printf("Enabled rules: %d", len(rules))
+	if names := policies.Names(); len(names) > 0 {
+		log.Printf("Review policies: %s", strings.Join(names, ", "))
+	}
 	log.Println("API Documentation:")
 	log.Println("  POST /api/connections/test  - 测试数据库连接")
 	log.Println("  POST /api/connections       - 保存数据库连接")
 	log.Println("  GET  /api/connections       - 列出所有连接")
 	log.Println("  GET  /api/schema/:id        - 获取数据库schema")
 	log.Println("  POST /api/sql/review        - 审查SQL语句")
-	log.Println("  GET  /api/rules             - 列出所有规则")
+	log.Println("  GET  /api/rules             - 列出所有规则（?policy= 查看策略的规则）")
 
 	if err := r.Run(addr); err != nil {
 		log.Fatal("Failed to start server:", err)
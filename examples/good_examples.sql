@@ -3,9 +3,9 @@
 
 -- Table with proper primary key and naming
 CREATE TABLE user_profiles (
-    id BIGINT PRIMARY KEY AUTO_INCREMENT,
-    user_name VARCHAR(50) NOT NULL COMMENT 'User login name',
-    email VARCHAR(100) NOT NULL COMMENT 'User email address',
+    id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT COMMENT 'Profile ID',
+    user_name VARCHAR(50) NOT NULL DEFAULT '' COMMENT 'User login name',
+    email VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'User email address',
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Record creation time',
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Last update time'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='User profile information';
@@ -35,12 +35,12 @@ WHERE created_at < DATE_SUB(NOW(), INTERVAL 365 DAY);
 
 -- Another well-designed table
 CREATE TABLE order_items (
-    id BIGINT PRIMARY KEY AUTO_INCREMENT,
-    order_id BIGINT NOT NULL COMMENT 'Reference to orders table',
-    product_id BIGINT NOT NULL COMMENT 'Reference to products table',
+    id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT COMMENT 'Line item ID',
+    order_id BIGINT NOT NULL DEFAULT 0 COMMENT 'Reference to orders table',
+    product_id BIGINT NOT NULL DEFAULT 0 COMMENT 'Reference to products table',
     quantity INT NOT NULL DEFAULT 1 COMMENT 'Item quantity',
-    unit_price DECIMAL(10,2) NOT NULL COMMENT 'Price per unit',
-    total_price DECIMAL(10,2) NOT NULL COMMENT 'Total price for this line item',
+    unit_price DECIMAL(10,2) NOT NULL DEFAULT 0.00 COMMENT 'Price per unit',
+    total_price DECIMAL(10,2) NOT NULL DEFAULT 0.00 COMMENT 'Total price for this line item',
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Record creation time'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Order line items';
 
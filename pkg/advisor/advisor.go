@@ -5,10 +5,12 @@ package advisor
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"sync"
+	"encoding/json"
+	"strings"
 
 	"github.com/pkg/errors"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/catalog"
 )
 
 // Engine represents the database engine type.
@@ -30,6 +32,16 @@ const (
 	LevelInfo    Level = "INFO"
 )
 
+// ParseLevel converts a level name such as "warning" to a Level.
+func ParseLevel(name string) (Level, error) {
+	switch level := Level(strings.ToUpper(name)); level {
+	case LevelError, LevelWarning, LevelInfo:
+		return level, nil
+	default:
+		return "", errors.Errorf("invalid level %q, must be one of ERROR, WARNING, INFO", name)
+	}
+}
+
 // Status represents the status of advice (maps to Level for compatibility).
 type Status = Level
 
@@ -52,21 +64,30 @@ type Advice struct {
 	Line    int    `json:"line,omitempty"`
 	Column  int    `json:"column,omitempty"`
 	RuleID  string `json:"rule_id"`
+	// Suppressed is set on advice silenced by a suppression comment and
+	// kept at INFO level because SuppressionOptions.ReportSuppressed is set.
+	Suppressed bool `json:"suppressed,omitempty"`
 }
 
 // Context encapsulates the checking context and configuration.
 type Context struct {
 	// Core fields
-	SQL    string  `json:"sql"`
-	Engine Engine  `json:"engine"`
-	Rule   *Rule   `json:"rule"`
-	AST    any     `json:"-"` // Parsed abstract syntax tree
+	SQL    string `json:"sql"`
+	Engine Engine `json:"engine"`
+	Rule   *Rule  `json:"rule"`
+	AST    any    `json:"-"` // Parsed abstract syntax tree
 
 	// Legacy compatibility fields
 	DatabaseName string            `json:"database_name"`
-	Rules        []string          `json:"rules"`        // 启用的规则ID列表
-	Connection   *sql.DB           `json:"-"`            // 数据库连接
-	Metadata     *DatabaseMetadata `json:"metadata"`     // 数据库元数据
+	Rules        []string          `json:"rules"`    // 只执行列出的规则类型，为空时执行全部
+	Connection   *sql.DB           `json:"-"`        // 数据库连接
+	Metadata     *DatabaseMetadata `json:"metadata"` // 数据库元数据
+	// Catalog 随脚本逐条语句演进的 schema，规则看到的是当前语句执行前的状态。
+	// 为空时由 Metadata 构建，没有 Metadata 时从空 catalog 开始（离线审查）。
+	// 监听规则通过 BaseRule.Catalog 读取，规则被放弃后审查可能改用它的副本
+	Catalog *catalog.Catalog `json:"-"`
+	// Suppression 内联抑制注释（-- sql-review:disable-next-line 等）的处理方式
+	Suppression SuppressionOptions `json:"suppression"`
 }
 
 // DatabaseMetadata 数据库元数据
@@ -74,11 +95,29 @@ type DatabaseMetadata struct {
 	Tables map[string]*TableMetadata `json:"tables"`
 }
 
+// GetTable 按表名查找表元数据，表名不区分大小写，找不到时返回 nil
+func (m *DatabaseMetadata) GetTable(name string) *TableMetadata {
+	if m == nil {
+		return nil
+	}
+	if table, ok := m.Tables[name]; ok {
+		return table
+	}
+	for _, table := range m.Tables {
+		if strings.EqualFold(table.Name, name) {
+			return table
+		}
+	}
+	return nil
+}
+
 // TableMetadata 表元数据
 type TableMetadata struct {
-	Name    string                    `json:"name"`
+	Name    string                     `json:"name"`
 	Columns map[string]*ColumnMetadata `json:"columns"`
 	Indexes map[string]*IndexMetadata  `json:"indexes"`
+	// RowCount 估算行数，0 表示未知
+	RowCount int64 `json:"row_count,omitempty"`
 }
 
 // ColumnMetadata 列元数据
@@ -103,100 +142,11 @@ type Advisor interface {
 	Check(ctx context.Context, checkCtx Context) ([]*Advice, error)
 }
 
-// Rule 规则接口
-type Rule interface {
-	ID() string
-	Name() string
+// Describer is implemented by advisors that provide a title and a
+// description for rule listings.
+type Describer interface {
+	Title() string
 	Description() string
-	Level() Level
-	Check(ctx context.Context, checkCtx *Context) ([]*Advice, error)
-}
-
-// BaseRule 基础规则实现
-type BaseRule struct {
-	RuleID      string
-	RuleName    string
-	RuleDesc    string
-	RuleLevel   Level
-}
-
-func (r *BaseRule) ID() string {
-	return r.RuleID
-}
-
-func (r *BaseRule) Name() string {
-	return r.RuleName
-}
-
-func (r *BaseRule) Description() string {
-	return r.RuleDesc
-}
-
-func (r *BaseRule) Level() Level {
-	return r.RuleLevel
-}
-
-// DefaultAdvisor 默认审查器实现
-type DefaultAdvisor struct {
-	rules map[string]Rule
-}
-
-// NewDefaultAdvisor 创建默认审查器
-func NewDefaultAdvisor() *DefaultAdvisor {
-	return &DefaultAdvisor{
-		rules: make(map[string]Rule),
-	}
-}
-
-// RegisterRule 注册规则
-func (a *DefaultAdvisor) RegisterRule(rule Rule) {
-	a.rules[rule.ID()] = rule
-}
-
-// GetRule 获取规则
-func (a *DefaultAdvisor) GetRule(id string) (Rule, bool) {
-	rule, exists := a.rules[id]
-	return rule, exists
-}
-
-// ListRules 列出所有规则
-func (a *DefaultAdvisor) ListRules() []Rule {
-	rules := make([]Rule, 0, len(a.rules))
-	for _, rule := range a.rules {
-		rules = append(rules, rule)
-	}
-	return rules
-}
-
-// Check 执行SQL审查
-func (a *DefaultAdvisor) Check(ctx context.Context, checkCtx *Context) ([]*Advice, error) {
-	var allAdvices []*Advice
-
-	// 如果没有指定规则，使用所有规则
-	rulesToCheck := checkCtx.Rules
-	if len(rulesToCheck) == 0 {
-		for ruleID := range a.rules {
-			rulesToCheck = append(rulesToCheck, ruleID)
-		}
-	}
-
-	// 执行每个规则
-	for _, ruleID := range rulesToCheck {
-		rule, exists := a.rules[ruleID]
-		if !exists {
-			continue
-		}
-
-		advices, err := rule.Check(ctx, checkCtx)
-		if err != nil {
-			// 记录错误但继续执行其他规则
-			continue
-		}
-
-		allAdvices = append(allAdvices, advices...)
-	}
-
-	return allAdvices, nil
 }
 
 // Type represents the type identifier for an advisor.
@@ -210,83 +160,30 @@ type Rule struct {
 	Payload any    `yaml:"payload,omitempty"`
 }
 
-// Global registry for advisors (Bytebase-style).
-var (
-	advisorMu sync.RWMutex
-	advisors  = make(map[Engine]map[Type]Advisor)
-)
-
-// Register makes an advisor available by the provided engine and type.
-// If Register is called twice with the same combination or if advisor is nil,
-// it panics.
-func Register(engine Engine, advType Type, advisor Advisor) {
-	advisorMu.Lock()
-	defer advisorMu.Unlock()
-
-	if advisor == nil {
-		panic("advisor: Register advisor is nil")
-	}
-
-	engineAdvisors, ok := advisors[engine]
-	if !ok {
-		advisors[engine] = map[Type]Advisor{
-			advType: advisor,
-		}
-	} else {
-		if _, dup := engineAdvisors[advType]; dup {
-			panic(fmt.Sprintf("advisor: Register called twice for advisor %v for %v", advType, engine))
-		}
-		engineAdvisors[advType] = advisor
+// UnmarshalPayload decodes the rule payload into target. The payload may be
+// a map from the rules configuration or a payload struct; fields missing
+// from the payload keep the values already in target, so callers fill
+// target with defaults first.
+func (r *Rule) UnmarshalPayload(target any) error {
+	if r.Payload == nil {
+		return nil
 	}
-}
-
-// CheckByType runs the specified advisor and returns the advice list.
-func CheckByType(ctx context.Context, engine Engine, advType Type, checkCtx Context) (adviceList []*Advice, err error) {
-	// Panic recovery for safer execution
-	defer func() {
-		if panicErr := recover(); panicErr != nil {
-			if panicError, ok := panicErr.(error); ok {
-				err = errors.Errorf("advisor check PANIC RECOVER, type: %v, err: %v", advType, panicError)
-			} else {
-				err = errors.Errorf("advisor check PANIC RECOVER, type: %v, err: %v", advType, panicErr)
-			}
-		}
-	}()
-
-	advisorMu.RLock()
-	engineAdvisors, ok := advisors[engine]
-	defer advisorMu.RUnlock()
-
-	if !ok {
-		return nil, errors.Errorf("advisor: unknown engine type %v", engine)
+	data, err := json.Marshal(r.Payload)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal payload of rule %s", r.Type)
 	}
-
-	advisor, ok := engineAdvisors[advType]
-	if !ok {
-		return nil, errors.Errorf("advisor: unknown advisor %v for %v", advType, engine)
+	if err := json.Unmarshal(data, target); err != nil {
+		return errors.Wrapf(err, "invalid payload of rule %s", r.Type)
 	}
-
-	return advisor.Check(ctx, checkCtx)
+	return nil
 }
 
-// GetRegisteredAdvisors returns all registered advisor types for a given engine.
-func GetRegisteredAdvisors(engine Engine) []Type {
-	advisorMu.RLock()
-	defer advisorMu.RUnlock()
-
-	engineAdvisors, ok := advisors[engine]
-	if !ok {
-		return nil
-	}
-
-	var types []Type
-	for advType := range engineAdvisors {
-		types = append(types, advType)
-	}
-	return types
+// EngineFromName converts an engine name such as "mysql" to an Engine.
+func EngineFromName(name string) Engine {
+	return Engine(strings.ToUpper(name))
 }
 
 // NewStatusByRuleLevel converts rule level to advice status.
 func NewStatusByRuleLevel(level Level) Status {
 	return Status(level)
-}
\ No newline at end of file
+}
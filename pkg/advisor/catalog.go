@@ -0,0 +1,111 @@
+package advisor
+
+import (
+	"context"
+	"sort"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/catalog"
+)
+
+// walkThroughCodes maps walk-through error types to advice codes.
+var walkThroughCodes = map[catalog.ErrorType]int32{
+	catalog.ErrorTableNotExists:       CodeTableNotExists,
+	catalog.ErrorTableExists:          CodeTableExists,
+	catalog.ErrorColumnExists:         CodeColumnExists,
+	catalog.ErrorColumnNotExists:      CodeColumnNotExists,
+	catalog.ErrorIndexExists:          CodeIndexExists,
+	catalog.ErrorIndexNotExists:       CodeIndexNotExists,
+	catalog.ErrorIndexColumnNotExists: CodeIndexColumnNotExists,
+	catalog.ErrorPrimaryKeyExists:     CodePrimaryKeyExists,
+}
+
+// NewCatalog returns the catalog a review starts from: the schema in
+// metadata, or an empty catalog when metadata is nil. The catalog does not
+// share state with metadata, so walking a script through it leaves
+// metadata unchanged.
+func NewCatalog(databaseName string, metadata *DatabaseMetadata) *catalog.Catalog {
+	if metadata == nil {
+		return catalog.New(databaseName)
+	}
+
+	tables := make([]*catalog.Table, 0, len(metadata.Tables))
+	for _, tableMetadata := range metadata.Tables {
+		table := &catalog.Table{Name: tableMetadata.Name, RowCount: tableMetadata.RowCount}
+		for _, column := range tableMetadata.Columns {
+			table.Columns = append(table.Columns, &catalog.Column{
+				Name:          column.Name,
+				Type:          column.Type,
+				Nullable:      column.IsNullable,
+				AutoIncrement: column.IsAutoIncr,
+			})
+		}
+		for _, index := range tableMetadata.Indexes {
+			primary := index.Type == "PRIMARY"
+			table.Indexes = append(table.Indexes, &catalog.Index{
+				Name:     index.Name,
+				Columns:  append([]string(nil), index.Columns...),
+				Primary:  primary,
+				Unique:   primary || index.Type == "UNIQUE",
+				Fulltext: index.Type == "FULLTEXT",
+			})
+		}
+		// Metadata columns and indexes come from maps, sort them for stable
+		// advice.
+		sort.Slice(table.Columns, func(i, j int) bool { return table.Columns[i].Name < table.Columns[j].Name })
+		sort.Slice(table.Indexes, func(i, j int) bool { return table.Indexes[i].Name < table.Indexes[j].Name })
+		tables = append(tables, table)
+	}
+	return catalog.NewWithTables(databaseName, tables)
+}
+
+// WalkThroughAdvisor is implemented by the advisor that reports the
+// statements that fail against the catalog. The script is applied to the
+// catalog whether or not the rule is configured, since the other rules
+// read it; the configured rule only decides which walk-through errors are
+// reported and at what level.
+type WalkThroughAdvisor interface {
+	Advisor
+	// WalkThroughAdvices returns the advice for the walk-through errors of
+	// a review. rule holds the rule configuration.
+	WalkThroughAdvices(rule *Rule, walkThroughErrors []*catalog.WalkThroughError) ([]*Advice, error)
+}
+
+// CheckWalkThrough applies checkCtx.AST to checkCtx.Catalog and reports the
+// walk-through errors with advisor. It lets walk-through advisors implement
+// Advisor.Check for CheckByType.
+func CheckWalkThrough(ctx context.Context, advisor WalkThroughAdvisor, checkCtx Context) ([]*Advice, error) {
+	if checkCtx.Catalog == nil {
+		checkCtx.Catalog = NewCatalog(checkCtx.DatabaseName, checkCtx.Metadata)
+	}
+	result, err := walkListeners(ctx, checkCtx.AST, nil, checkCtx.Catalog)
+	if err != nil {
+		return nil, err
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+	return advisor.WalkThroughAdvices(checkCtx.Rule, result.walkThroughErrors)
+}
+
+// NewWalkThroughAdvices converts walk-through errors to advice with the
+// level and type of rule.
+func NewWalkThroughAdvices(rule *Rule, walkThroughErrors []*catalog.WalkThroughError) []*Advice {
+	status := NewStatusByRuleLevel(rule.Level)
+	adviceList := make([]*Advice, 0, len(walkThroughErrors))
+	for _, walkThroughErr := range walkThroughErrors {
+		adviceList = append(adviceList, &Advice{
+			Status:        status,
+			Code:          walkThroughCodes[walkThroughErr.Type],
+			Title:         "Schema walk-through error",
+			Content:       walkThroughErr.Content,
+			StartPosition: &Position{Line: walkThroughErr.Line, Column: walkThroughErr.Column},
+			// Legacy fields for compatibility
+			Level:   Level(status),
+			Message: walkThroughErr.Content,
+			Line:    walkThroughErr.Line,
+			Column:  walkThroughErr.Column,
+			RuleID:  string(rule.Type),
+		})
+	}
+	return adviceList
+}
@@ -0,0 +1,141 @@
+package advisor
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type ruleTimeoutKey struct{}
+
+type maxWorkersKey struct{}
+
+// WithRuleTimeout returns a context that limits the time each rule of a
+// review may take. A timeout of 0 or less removes the limit, which is the
+// default.
+func WithRuleTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, ruleTimeoutKey{}, timeout)
+}
+
+// RuleTimeout returns the time each rule may take, 0 when unlimited.
+func RuleTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(ruleTimeoutKey{}).(time.Duration)
+	if timeout < 0 {
+		return 0
+	}
+	return timeout
+}
+
+// WithMaxWorkers returns a context that limits the number of goroutines a
+// review runs rules on: the listener rules are split across that many
+// walks of each statement, and the other rules run that many at a time.
+// The default is runtime.GOMAXPROCS.
+func WithMaxWorkers(ctx context.Context, workers int) context.Context {
+	return context.WithValue(ctx, maxWorkersKey{}, workers)
+}
+
+// MaxWorkers returns the number of goroutines a review may run rules on.
+func MaxWorkers(ctx context.Context) int {
+	if workers, ok := ctx.Value(maxWorkersKey{}).(int); ok && workers > 0 {
+		return workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// ruleCheck is a rule that runs on its own rather than in the shared walk.
+type ruleCheck struct {
+	rule     *Rule
+	checkCtx Context
+	advices  []*Advice
+	err      error
+	// abandoned is set when the check did not return in time and may
+	// still be running.
+	abandoned bool
+}
+
+// runChecks runs the checks on at most MaxWorkers(ctx) goroutines, each
+// with the RuleTimeout(ctx) of its rule. A check that does not return in
+// time is abandoned and fails with a timeout error; advisors should stop
+// when the context they get is done.
+func runChecks(ctx context.Context, checks []*ruleCheck) {
+	sem := make(chan struct{}, MaxWorkers(ctx))
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			check.advices, check.err = runCheck(ctx, check)
+		}()
+	}
+	wg.Wait()
+}
+
+// runCheck runs a check and sets check.abandoned when it does not return
+// in time.
+func runCheck(ctx context.Context, check *ruleCheck) ([]*Advice, error) {
+	parent := ctx
+	if timeout := RuleTimeout(ctx); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		advices []*Advice
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		advices, err := CheckByType(ctx, check.rule.Engine, check.rule.Type, check.checkCtx)
+		done <- result{advices: advices, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.advices, r.err
+	case <-ctx.Done():
+		check.abandoned = true
+		if parent.Err() != nil {
+			return nil, parent.Err()
+		}
+		return nil, errors.Errorf("advisor check TIMEOUT, type: %v, timeout: %v", check.rule.Type, RuleTimeout(ctx))
+	}
+}
+
+// SortAdvices sorts advice by position, then rule ID, then code, so that a
+// review reports the same advice in the same order every time. Advice that
+// is equal on all three keeps its order.
+func SortAdvices(adviceList []*Advice) {
+	sort.SliceStable(adviceList, func(i, j int) bool {
+		x, y := adviceList[i], adviceList[j]
+		xLine, xColumn := x.position()
+		yLine, yColumn := y.position()
+		if xLine != yLine {
+			return xLine < yLine
+		}
+		if xColumn != yColumn {
+			return xColumn < yColumn
+		}
+		if x.RuleID != y.RuleID {
+			return x.RuleID < y.RuleID
+		}
+		return x.Code < y.Code
+	})
+}
+
+// position returns the line and column of an advice, from the legacy
+// fields when StartPosition is not set.
+func (a *Advice) position() (int, int) {
+	if a.StartPosition != nil {
+		return a.StartPosition.Line, a.StartPosition.Column
+	}
+	return a.Line, a.Column
+}
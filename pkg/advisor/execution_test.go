@@ -0,0 +1,214 @@
+package advisor_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/rules/mysql"
+)
+
+const (
+	testSlowOnCreateTable  advisor.Type = "test.listener.slow-on-create-table"
+	testBlockOnCreateTable advisor.Type = "test.listener.block-on-create-table"
+	testWaitOnCreateTable  advisor.Type = "test.listener.wait-on-create-table"
+	testMeetOnCreateTable  advisor.Type = "test.listener.meet-on-create-table"
+	testMeetOnCreateTable2 advisor.Type = "test.listener.meet-on-create-table-2"
+)
+
+func init() {
+	advisor.Register(advisor.MySQL, testSlowOnCreateTable, &nodeTestAdvisor{nodeType: "CreateTable", delay: 20 * time.Millisecond})
+	advisor.Register(advisor.MySQL, testBlockOnCreateTable, &nodeTestAdvisor{nodeType: "CreateTable", blocks: true})
+	advisor.Register(advisor.MySQL, testWaitOnCreateTable, &nodeTestAdvisor{nodeType: "CreateTable", waits: true})
+	meet := make(chan struct{})
+	advisor.Register(advisor.MySQL, testMeetOnCreateTable, &nodeTestAdvisor{nodeType: "CreateTable", meet: meet})
+	advisor.Register(advisor.MySQL, testMeetOnCreateTable2, &nodeTestAdvisor{nodeType: "CreateTable", meet: meet})
+}
+
+// TestSQLReviewCheckOrder 测试并发执行时审查结果的顺序固定
+func TestSQLReviewCheckOrder(t *testing.T) {
+	checkCtx := advisor.Context{
+		SQL:    "CREATE TABLE T1 (id INT, Name TEXT);\nSELECT * FROM t1;\nUPDATE t1 SET id = 1;\nCREATE TABLE b (id INT);",
+		Engine: advisor.MySQL,
+	}
+
+	review := func(workers int) []string {
+		ctx := advisor.WithMaxWorkers(context.Background(), workers)
+		advices, err := advisor.SQLReviewCheck(ctx, mysql.DefaultRules(), checkCtx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var results []string
+		for _, advice := range advices {
+			results = append(results, fmt.Sprintf("%d:%d %s %d", advice.StartPosition.Line, advice.StartPosition.Column, advice.RuleID, advice.Code))
+		}
+		return results
+	}
+
+	expected := review(1)
+	if len(expected) < 5 {
+		t.Fatalf("Expected several advices, got %v", expected)
+	}
+	for i := 0; i < 10; i++ {
+		if results := review(4); !reflect.DeepEqual(results, expected) {
+			t.Fatalf("Expected %v, got %v", expected, results)
+		}
+	}
+}
+
+// TestSQLReviewCheckRuleTimeout 测试超时的规则被跳过，其他规则不受影响
+func TestSQLReviewCheckRuleTimeout(t *testing.T) {
+	rules := []*advisor.Rule{
+		{Type: testSlowOnCreateTable, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLTableRequirePK, Level: advisor.LevelError, Engine: advisor.MySQL},
+	}
+	checkCtx := advisor.Context{
+		SQL:    "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\nCREATE TABLE c (id INT);",
+		Engine: advisor.MySQL,
+	}
+
+	ctx := advisor.WithRuleTimeout(context.Background(), 5*time.Millisecond)
+	advices, err := advisor.SQLReviewCheck(ctx, rules, checkCtx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, advice := range advices {
+		counts[advice.RuleID]++
+	}
+	internalErrors := advisor.InternalErrors(advices)
+	if counts[string(testSlowOnCreateTable)] != 1 || len(internalErrors) != 1 || !strings.Contains(internalErrors[0].Content, "TIMEOUT") {
+		t.Errorf("Expected one timeout error from the slow rule, got %v", internalErrors)
+	}
+	if counts[string(advisor.MySQLTableRequirePK)] != 3 {
+		t.Errorf("Expected 3 primary key advices, got %d", counts[string(advisor.MySQLTableRequirePK)])
+	}
+}
+
+// TestSQLReviewCheckBlockingRule 测试阻塞的规则在超时后被放弃，其他规则和后续语句照常检查
+func TestSQLReviewCheckBlockingRule(t *testing.T) {
+	rules := []*advisor.Rule{
+		{Type: testBlockOnCreateTable, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: testWaitOnCreateTable, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLTableRequirePK, Level: advisor.LevelError, Engine: advisor.MySQL},
+		{Type: advisor.MySQLIndexDesign, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLSchemaWalkThrough, Level: advisor.LevelError, Engine: advisor.MySQL},
+	}
+	checkCtx := advisor.Context{
+		SQL:    "CREATE TABLE a (id INT, KEY idx_id (id));\nCREATE TABLE b (id INT);\nCREATE TABLE a (id INT);\nCREATE INDEX idx_id_2 ON a (id);",
+		Engine: advisor.MySQL,
+	}
+
+	// 一个 worker 时其他规则在放弃阻塞的回调后从下一个节点继续
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			ctx := advisor.WithRuleTimeout(context.Background(), 20*time.Millisecond)
+			ctx = advisor.WithMaxWorkers(ctx, workers)
+			advices, err := advisor.SQLReviewCheck(ctx, rules, checkCtx)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			counts := make(map[string]int)
+			for _, advice := range advices {
+				counts[advice.RuleID]++
+			}
+			internalErrors := make(map[string]string)
+			for _, advice := range advisor.InternalErrors(advices) {
+				internalErrors[advice.RuleID] = advice.Content
+			}
+			if !strings.Contains(internalErrors[string(testBlockOnCreateTable)], "TIMEOUT") || counts[string(testBlockOnCreateTable)] != 1 {
+				t.Errorf("Expected one timeout error from the blocking rule, got %v", internalErrors)
+			}
+			// 规则通过 Context 得到超时
+			if _, ok := internalErrors[string(testWaitOnCreateTable)]; !ok || counts[string(testWaitOnCreateTable)] != 1 {
+				t.Errorf("Expected one error from the rule waiting on its context, got %v", internalErrors)
+			}
+			// 放弃回调后规则仍然看到应用了之前语句的 catalog
+			if counts[string(advisor.MySQLTableRequirePK)] != 3 || counts[string(advisor.MySQLSchemaWalkThrough)] != 1 || counts[string(advisor.MySQLIndexDesign)] != 1 {
+				t.Errorf("Expected the other rules to check every statement, got %v", counts)
+			}
+		})
+	}
+}
+
+// TestSQLReviewCheckWorkers 测试监听规则分到多个 worker 时同时遍历语句
+func TestSQLReviewCheckWorkers(t *testing.T) {
+	rules := []*advisor.Rule{
+		{Type: testMeetOnCreateTable, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: testMeetOnCreateTable2, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+	}
+	checkCtx := advisor.Context{
+		SQL:    "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);",
+		Engine: advisor.MySQL,
+	}
+
+	review := func(workers int) []*advisor.Advice {
+		ctx := advisor.WithRuleTimeout(context.Background(), 200*time.Millisecond)
+		ctx = advisor.WithMaxWorkers(ctx, workers)
+		advices, err := advisor.SQLReviewCheck(ctx, rules, checkCtx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return advices
+	}
+
+	// 两个规则在每个 CreateTable 节点等待对方，只有同时遍历时才能完成
+	if advices := review(2); len(advices) != 4 || len(advisor.InternalErrors(advices)) != 0 {
+		t.Errorf("Expected both rules to report both statements, got %v", advices)
+	}
+	if advices := review(1); len(advisor.InternalErrors(advices)) == 0 {
+		t.Errorf("Expected the rules to time out on one worker, got %v", advices)
+	}
+}
+
+// TestSQLReviewCheckCanceled 测试审查被取消时未完成的规则报告为内部错误
+func TestSQLReviewCheckCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	checkCtx := advisor.Context{
+		SQL:    "CREATE TABLE a (id INT);",
+		Engine: advisor.MySQL,
+	}
+	rules := mysql.DefaultRules()
+	advices, err := advisor.SQLReviewCheck(ctx, rules, checkCtx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	internalErrors := advisor.InternalErrors(advices)
+	if len(advices) != len(rules) || len(internalErrors) != len(rules) {
+		t.Fatalf("Expected an internal error for each rule, got %v", advices)
+	}
+	for _, advice := range internalErrors {
+		if !strings.Contains(advice.Content, context.Canceled.Error()) {
+			t.Errorf("Expected the cancellation in the internal error, got %q", advice.Content)
+		}
+	}
+}
+
+// TestSortAdvices 测试按位置、规则 ID、错误码排序
+func TestSortAdvices(t *testing.T) {
+	advices := []*advisor.Advice{
+		{RuleID: "b", Code: 1, StartPosition: &advisor.Position{Line: 2, Column: 1}},
+		{RuleID: "b", Code: 2, StartPosition: &advisor.Position{Line: 1, Column: 5}},
+		{RuleID: "a", Code: 3, Line: 1, Column: 5},
+		{RuleID: "b", Code: 1, StartPosition: &advisor.Position{Line: 1, Column: 5}},
+		{RuleID: "c", Code: 1, StartPosition: &advisor.Position{Line: 1, Column: 1}},
+	}
+	advisor.SortAdvices(advices)
+
+	var results []string
+	for _, advice := range advices {
+		results = append(results, fmt.Sprintf("%s %d", advice.RuleID, advice.Code))
+	}
+	expected := []string{"c 1", "a 3", "b 1", "b 2", "b 1"}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+}
@@ -0,0 +1,578 @@
+package advisor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antlr4-go/antlr/v4"
+	"github.com/pkg/errors"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/catalog"
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+// NodeListener is a rule that inspects parse tree nodes during the shared
+// tree walk. The engine walks every statement once and hands each node to
+// the listeners that declared its node type, so adding a rule does not add
+// another walk over the tree.
+type NodeListener interface {
+	// NodeTypes returns the node types the listener handles. A node type is
+	// the grammar rule name of the node, e.g. "CreateTable" for
+	// *mysql.CreateTableContext.
+	NodeTypes() []string
+	// SetBaseLine is called before each statement is walked.
+	SetBaseLine(baseLine int)
+	// SetContext is called before the walk with the context of the
+	// callbacks. It is done when the rule runs out of time or the review is
+	// canceled; callbacks should return once it is done, and pass it on to
+	// I/O. A callback still running then is abandoned and the listener gets
+	// no more callbacks.
+	SetContext(ctx context.Context)
+	// SetCatalog is called before the walk with the catalog the listener
+	// reads the schema from, nil when there is none. The walk leaves the
+	// catalog of an abandoned callback as it is and calls SetCatalog again
+	// with a copy on the listeners that go on.
+	SetCatalog(cat *catalog.Catalog)
+	// OnEnter is called when the walker enters a node of a declared type.
+	OnEnter(ctx antlr.ParserRuleContext, nodeType string) error
+	// OnExit is called when the walker exits a node of a declared type.
+	OnExit(ctx antlr.ParserRuleContext, nodeType string) error
+	// Advices returns the advice collected during the walk.
+	Advices() []*Advice
+}
+
+// ListenerAdvisor is implemented by advisors that take part in the shared
+// tree walk instead of walking the tree on their own.
+type ListenerAdvisor interface {
+	Advisor
+	// NewListener returns a listener for one review. checkCtx.Rule holds
+	// the rule configuration.
+	NewListener(checkCtx Context) (NodeListener, error)
+}
+
+// CheckWithListener runs a single ListenerAdvisor over checkCtx.AST. It lets
+// listener advisors implement Advisor.Check for CheckByType. The script is
+// walked through checkCtx.Catalog if set; walk-through errors are not part
+// of the rule's advice.
+func CheckWithListener(ctx context.Context, advisor ListenerAdvisor, checkCtx Context) ([]*Advice, error) {
+	listener, err := advisor.NewListener(checkCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := walkListeners(ctx, checkCtx.AST, []NodeListener{listener}, checkCtx.Catalog)
+	if err != nil {
+		return nil, err
+	}
+	if result.errs[0] != nil {
+		return nil, result.errs[0]
+	}
+	return listener.Advices(), nil
+}
+
+// walkResult is the outcome of a walk over the statements of a script.
+type walkResult struct {
+	// errs holds the error of each listener that failed, at the same
+	// index as the listener.
+	errs []error
+	// walkThroughErrors are the errors found applying the statements to
+	// the catalog.
+	walkThroughErrors []*catalog.WalkThroughError
+	// err is the error of the context when the walk stopped before the
+	// last statement; walkThroughErrors is incomplete then.
+	err error
+}
+
+// walkListeners walks the statements of the AST and dispatches the nodes
+// to the listeners, which see the statements one by one and in order.
+//
+// The listeners are split across MaxWorkers(ctx) workers. The workers walk
+// each statement at the same time, one walk per worker, and the next
+// statement starts when all of them are done. A listener that returns an
+// error, panics or uses up RuleTimeout(ctx) is dropped from the rest of the
+// walk, see walker.wait. When ctx is done the walk stops, and the listeners
+// that have not failed yet fail with the error of ctx.
+//
+// When cat is not nil each statement is applied to it after the listeners
+// have seen the statement, so listeners see the schema as it is before the
+// statement.
+func walkListeners(ctx context.Context, ast any, listeners []NodeListener, cat *catalog.Catalog) (*walkResult, error) {
+	stmtList, ok := ast.([]*mysqlparser.ParseResult)
+	if !ok {
+		return nil, errors.Errorf("advisor: unsupported AST type %T", ast)
+	}
+
+	w := &walker{
+		ctx:       ctx,
+		listeners: listeners,
+		cancels:   make([]context.CancelFunc, len(listeners)),
+		errs:      make([]error, len(listeners)),
+		spent:     make([]time.Duration, len(listeners)),
+		timeout:   RuleTimeout(ctx),
+	}
+	w.workers = make([]*worker, min(MaxWorkers(ctx), len(listeners)))
+	for i := range w.workers {
+		w.workers[i] = &worker{walker: w, byType: make(map[string][]int)}
+	}
+	for i, listener := range listeners {
+		var listenerCtx context.Context
+		listenerCtx, w.cancels[i] = context.WithCancel(ctx)
+		defer w.cancels[i]()
+		listener.SetContext(listenerCtx)
+		listener.SetCatalog(cat)
+
+		worker := w.workers[i%len(w.workers)]
+		worker.listeners = append(worker.listeners, i)
+		for _, nodeType := range listener.NodeTypes() {
+			worker.byType[nodeType] = append(worker.byType[nodeType], i)
+		}
+	}
+
+	result := &walkResult{errs: w.errs}
+	for _, stmt := range stmtList {
+		if err := ctx.Err(); err != nil {
+			w.cancel(err)
+			result.err = err
+			break
+		}
+		for i, listener := range listeners {
+			if w.errs[i] == nil {
+				listener.SetBaseLine(stmt.BaseLine)
+			}
+		}
+		abandoned, err := w.walk(stmt)
+		if err != nil {
+			result.err = err
+			break
+		}
+		if cat != nil {
+			if abandoned {
+				// An abandoned callback may still read the catalog, keep
+				// it as it is and go on with a copy.
+				cat = cat.Clone()
+				for i, listener := range listeners {
+					if w.errs[i] == nil {
+						listener.SetCatalog(cat)
+					}
+				}
+			}
+			result.walkThroughErrors = append(result.walkThroughErrors, cat.WalkThrough(stmt)...)
+		}
+	}
+	return result, nil
+}
+
+// walker holds the state of a walk shared by its workers.
+type walker struct {
+	ctx       context.Context
+	listeners []NodeListener
+	workers   []*worker
+	// cancels, errs and spent are indexed like listeners. cancels end the
+	// contexts the listeners got with SetContext. errs and spent of a
+	// listener are changed by the run of its worker, under the lock of the
+	// run, or by the walk goroutine when no run of the worker is live.
+	cancels []context.CancelFunc
+	errs    []error
+	spent   []time.Duration
+	// timeout is the time a listener may spend in its callbacks, 0 when
+	// unlimited.
+	timeout time.Duration
+}
+
+// worker is a share of the listeners, walked by one goroutine.
+type worker struct {
+	walker    *walker
+	listeners []int
+	// byType maps a node type to the indexes of the listeners handling it.
+	byType map[string][]int
+}
+
+// walk has the workers walk a statement and waits for them. abandoned
+// reports that a run was abandoned, see wait. err is the error of the walk
+// context when it is done before the workers.
+func (w *walker) walk(stmt *mysqlparser.ParseResult) (abandoned bool, err error) {
+	finished := make(chan *run, len(w.workers))
+	running := make(map[*worker]*run, len(w.workers))
+	for _, worker := range w.workers {
+		if worker.live() {
+			running[worker] = worker.start(stmt, finished, 0, 0)
+		}
+	}
+	return w.wait(running, finished)
+}
+
+// wait waits for the runs of a statement to finish.
+//
+// With a rule timeout it also watches the callbacks: a callback still
+// running when its listener has used up the timeout is abandoned. The
+// listener fails with a TIMEOUT error, its context is canceled and the
+// worker goes on with its other listeners in a new run from the node after
+// the callback, without waiting for it. The abandoned run does not touch
+// the walk again once the callback returns.
+//
+// When the walk context is done every run is abandoned the same way and
+// the listeners that have not failed yet fail with the error of the
+// context.
+func (w *walker) wait(running map[*worker]*run, finished chan *run) (abandoned bool, err error) {
+	var timer *time.Timer
+	var expired <-chan time.Time
+	if w.timeout > 0 {
+		timer = time.NewTimer(w.timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for len(running) > 0 {
+		if timer != nil {
+			timer.Reset(w.nextExpiry(running))
+		}
+		select {
+		case r := <-finished:
+			delete(running, r.worker)
+		case <-expired:
+			for worker, r := range running {
+				from, position, ok := r.expire()
+				if !ok {
+					continue
+				}
+				abandoned = true
+				delete(running, worker)
+				if worker.live() {
+					running[worker] = worker.start(r.stmt, finished, from, position+1)
+				}
+			}
+		case <-w.ctx.Done():
+			for _, r := range running {
+				r.abandon()
+			}
+			w.cancel(w.ctx.Err())
+			return true, w.ctx.Err()
+		}
+	}
+	return abandoned, nil
+}
+
+// nextExpiry returns the time until a listener of the runs can use up
+// its timeout.
+func (w *walker) nextExpiry(running map[*worker]*run) time.Duration {
+	next := w.timeout
+	for _, r := range running {
+		if remaining := r.remaining(); remaining < next {
+			next = remaining
+		}
+	}
+	return max(next, 0)
+}
+
+// cancel fails the listeners that have not failed yet with err, the error
+// of the walk context.
+func (w *walker) cancel(err error) {
+	for i := range w.listeners {
+		if w.errs[i] == nil {
+			w.fail(i, err)
+		}
+	}
+}
+
+// fail records the error of listener i and cancels its context. An error
+// while the walk context is done is reported as the cancellation.
+func (w *walker) fail(i int, err error) {
+	if ctxErr := w.ctx.Err(); ctxErr != nil {
+		err = errors.Wrapf(ctxErr, "advisor listener CANCELED, listener: %T", w.listeners[i])
+	}
+	w.errs[i] = err
+	w.cancels[i]()
+}
+
+// live reports whether some listener of the worker has not failed.
+func (w *worker) live() bool {
+	for _, i := range w.listeners {
+		if w.walker.errs[i] == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// start walks a statement on a new goroutine, dispatching from event from
+// and, at that event, from the listener at position.
+func (w *worker) start(stmt *mysqlparser.ParseResult, finished chan *run, from, position int) *run {
+	r := &run{worker: w, stmt: stmt, finished: finished, from: from, fromPosition: position, listener: -1}
+	go func() {
+		antlr.ParseTreeWalkerDefault.Walk(r, stmt.Tree)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if !r.abandoned {
+			r.finished <- r
+		}
+	}()
+	return r
+}
+
+// run is the walk of a statement by a worker. It is the
+// antlr.ParseTreeListener of the walk and hands each node to the listeners
+// of the worker that handle its type.
+type run struct {
+	worker   *worker
+	stmt     *mysqlparser.ParseResult
+	finished chan *run
+	// events counts the nodes entered and exited. Events before from, and
+	// the listeners before fromPosition at event from, were dispatched by
+	// an earlier run.
+	events       int
+	from         int
+	fromPosition int
+
+	mu sync.Mutex
+	// abandoned is set when the walk gives up on the run.
+	abandoned bool
+	// listener is the listener whose callback runs, -1 between callbacks.
+	// event and position are where the callback is: the event and the
+	// position of the listener among the listeners of the node. started is
+	// when the callback started.
+	listener int
+	event    int
+	position int
+	started  time.Time
+}
+
+// VisitTerminal implements antlr.ParseTreeListener.
+func (*run) VisitTerminal(antlr.TerminalNode) {}
+
+// VisitErrorNode implements antlr.ParseTreeListener.
+func (*run) VisitErrorNode(antlr.ErrorNode) {}
+
+// EnterEveryRule implements antlr.ParseTreeListener.
+func (r *run) EnterEveryRule(ctx antlr.ParserRuleContext) {
+	r.dispatch(ctx, func(listener NodeListener, nodeType string) error { return listener.OnEnter(ctx, nodeType) })
+}
+
+// ExitEveryRule implements antlr.ParseTreeListener.
+func (r *run) ExitEveryRule(ctx antlr.ParserRuleContext) {
+	r.dispatch(ctx, func(listener NodeListener, nodeType string) error { return listener.OnExit(ctx, nodeType) })
+}
+
+func (r *run) dispatch(ctx antlr.ParserRuleContext, callback func(NodeListener, string) error) {
+	event := r.events
+	r.events++
+	if event < r.from {
+		return
+	}
+	nodeType := NodeType(ctx)
+	for position, i := range r.worker.byType[nodeType] {
+		if event == r.from && position < r.fromPosition {
+			continue
+		}
+		if !r.call(i, event, position, func() error { return callback(r.worker.walker.listeners[i], nodeType) }) {
+			return
+		}
+	}
+}
+
+// call runs one callback of listener i unless the listener failed. It
+// returns false when the run is abandoned.
+func (r *run) call(i, event, position int, fn func() error) bool {
+	w := r.worker.walker
+	r.mu.Lock()
+	if r.abandoned {
+		r.mu.Unlock()
+		return false
+	}
+	if w.errs[i] != nil {
+		r.mu.Unlock()
+		return true
+	}
+	if err := w.ctx.Err(); err != nil {
+		w.fail(i, err)
+		r.mu.Unlock()
+		return true
+	}
+	r.listener, r.event, r.position = i, event, position
+	if w.timeout > 0 {
+		r.started = time.Now()
+	}
+	r.mu.Unlock()
+
+	err := r.run(i, fn)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.abandoned {
+		return false
+	}
+	r.listener = -1
+	if w.timeout > 0 {
+		w.spent[i] += time.Since(r.started)
+		if err == nil && w.spent[i] > w.timeout {
+			err = w.timedOut(i)
+		}
+	}
+	if err != nil {
+		w.fail(i, err)
+	}
+	return true
+}
+
+// run runs one listener callback and turns a panic into an error, so one
+// broken rule cannot abort the walk for the others.
+func (r *run) run(i int, fn func() error) (err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = errors.Errorf("advisor listener PANIC RECOVER, listener: %T, err: %v", r.worker.walker.listeners[i], panicErr)
+		}
+	}()
+	return fn()
+}
+
+// remaining returns the time until a listener of the run can use up its
+// timeout: the listener of the running callback, or any listener of the
+// worker between callbacks.
+func (r *run) remaining() time.Duration {
+	w := r.worker.walker
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.listener >= 0 {
+		return w.timeout - w.spent[r.listener] - time.Since(r.started)
+	}
+	remaining := w.timeout
+	for _, i := range r.worker.listeners {
+		if w.errs[i] == nil {
+			remaining = min(remaining, w.timeout-w.spent[i])
+		}
+	}
+	// Between callbacks nothing can expire yet, check again a little later
+	// rather than spin on a listener that has almost no time left.
+	return max(remaining, time.Millisecond)
+}
+
+// expire abandons the run when the running callback has used up the
+// timeout of its listener, and fails the listener. It returns where the
+// callback is, see run.event and run.position.
+func (r *run) expire() (event, position int, ok bool) {
+	w := r.worker.walker
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.abandoned || r.listener < 0 || w.spent[r.listener]+time.Since(r.started) < w.timeout {
+		return 0, 0, false
+	}
+	r.abandoned = true
+	w.fail(r.listener, w.timedOut(r.listener))
+	return r.event, r.position, true
+}
+
+// abandon gives up on the run.
+func (r *run) abandon() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.abandoned = true
+}
+
+// timedOut returns the error of listener i when it used up its timeout.
+func (w *walker) timedOut(i int) error {
+	return errors.Errorf("advisor listener TIMEOUT, listener: %T, timeout: %v", w.listeners[i], w.timeout)
+}
+
+// NodeType returns the node type of a parse tree node: the name of its
+// context type without the "Context" suffix.
+func NodeType(ctx antlr.ParserRuleContext) string {
+	t := reflect.TypeOf(ctx)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return strings.TrimSuffix(t.Name(), "Context")
+}
+
+// BaseRule holds the state every node listener needs: the configured rule,
+// the base line of the statement being walked and the collected advice.
+// Listeners embed it and implement NodeTypes and OnEnter.
+type BaseRule struct {
+	rule       *Rule
+	baseLine   int
+	ctx        context.Context
+	catalog    *catalog.Catalog
+	adviceList []*Advice
+}
+
+// NewBaseRule returns a BaseRule reporting with the level and type of rule.
+func NewBaseRule(rule *Rule) BaseRule {
+	return BaseRule{rule: rule}
+}
+
+// SetBaseLine implements NodeListener.
+func (r *BaseRule) SetBaseLine(baseLine int) {
+	r.baseLine = baseLine
+}
+
+// SetContext implements NodeListener.
+func (r *BaseRule) SetContext(ctx context.Context) {
+	r.ctx = ctx
+}
+
+// Context returns the context of the callbacks, see
+// NodeListener.SetContext.
+func (r *BaseRule) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// SetCatalog implements NodeListener.
+func (r *BaseRule) SetCatalog(cat *catalog.Catalog) {
+	r.catalog = cat
+}
+
+// Catalog returns the schema as of the statement being walked, or nil
+// when the review has no catalog.
+func (r *BaseRule) Catalog() *catalog.Catalog {
+	return r.catalog
+}
+
+// BaseLine returns the base line of the statement being walked.
+func (r *BaseRule) BaseLine() int {
+	return r.baseLine
+}
+
+// OnExit implements NodeListener. Most rules only act on enter.
+func (*BaseRule) OnExit(antlr.ParserRuleContext, string) error {
+	return nil
+}
+
+// Advices implements NodeListener.
+func (r *BaseRule) Advices() []*Advice {
+	return r.adviceList
+}
+
+// Rule returns the configured rule.
+func (r *BaseRule) Rule() *Rule {
+	return r.rule
+}
+
+// AddAdvice records an advice positioned at token.
+func (r *BaseRule) AddAdvice(code int32, title, content string, token antlr.Token) {
+	r.AddAdviceAt(code, title, content, &Position{
+		Line:   r.baseLine + token.GetLine(),
+		Column: token.GetColumn() + 1,
+	})
+}
+
+// AddAdviceAt records an advice at an explicit position in the script.
+func (r *BaseRule) AddAdviceAt(code int32, title, content string, position *Position) {
+	status := NewStatusByRuleLevel(r.rule.Level)
+	r.adviceList = append(r.adviceList, &Advice{
+		Status:        status,
+		Code:          code,
+		Title:         title,
+		Content:       content,
+		StartPosition: position,
+		// Legacy fields for compatibility
+		Level:   Level(status),
+		Message: content,
+		Line:    position.Line,
+		Column:  position.Column,
+		RuleID:  string(r.rule.Type),
+	})
+}
@@ -0,0 +1,119 @@
+package advisor_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antlr4-go/antlr/v4"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+const (
+	testCountCreateTable advisor.Type = "test.listener.count-create-table"
+	testPanicOnSelect    advisor.Type = "test.listener.panic-on-select"
+)
+
+func init() {
+	advisor.Register(advisor.MySQL, testCountCreateTable, &nodeTestAdvisor{nodeType: "CreateTable"})
+	advisor.Register(advisor.MySQL, testPanicOnSelect, &nodeTestAdvisor{nodeType: "SelectStatement", panics: true})
+}
+
+// nodeTestAdvisor reports one advice for every node of nodeType. A
+// blocking listener never returns, a waiting one returns when its context
+// is done. Listeners sharing a meet channel wait at every node for one
+// another, or for their context to be done.
+type nodeTestAdvisor struct {
+	nodeType string
+	panics   bool
+	blocks   bool
+	waits    bool
+	meet     chan struct{}
+	delay    time.Duration
+}
+
+func (a *nodeTestAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+func (a *nodeTestAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	return &nodeTestRule{BaseRule: advisor.NewBaseRule(checkCtx.Rule), advisor: a}, nil
+}
+
+type nodeTestRule struct {
+	advisor.BaseRule
+	advisor *nodeTestAdvisor
+}
+
+func (r *nodeTestRule) NodeTypes() []string {
+	return []string{r.advisor.nodeType}
+}
+
+func (r *nodeTestRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if r.advisor.panics {
+		panic("boom")
+	}
+	if r.advisor.blocks {
+		select {}
+	}
+	if r.advisor.waits {
+		<-r.Context().Done()
+		return r.Context().Err()
+	}
+	if r.advisor.meet != nil {
+		select {
+		case r.advisor.meet <- struct{}{}:
+		case <-r.advisor.meet:
+		case <-r.Context().Done():
+			return r.Context().Err()
+		}
+	}
+	time.Sleep(r.advisor.delay)
+	r.AddAdvice(100, nodeType, ctx.GetText(), ctx.GetStart())
+	return nil
+}
+
+// TestSQLReviewCheckSharedWalk 测试多个规则共享一次语法树遍历
+func TestSQLReviewCheckSharedWalk(t *testing.T) {
+	rules := []*advisor.Rule{
+		{Type: testCountCreateTable, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: testPanicOnSelect, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLTableRequirePK, Level: advisor.LevelError, Engine: advisor.MySQL},
+	}
+	checkCtx := advisor.Context{
+		SQL:    "SELECT 1;\nCREATE TABLE a (id INT PRIMARY KEY);\n  CREATE TABLE b (id INT);",
+		Engine: advisor.MySQL,
+	}
+
+	advices, err := advisor.SQLReviewCheck(context.Background(), rules, checkCtx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, advice := range advices {
+		counts[advice.RuleID]++
+	}
+	// 发生 panic 的规则不影响其他规则
+	if counts[string(testCountCreateTable)] != 2 {
+		t.Errorf("Expected 2 CreateTable nodes, got %d", counts[string(testCountCreateTable)])
+	}
+	if counts[string(advisor.MySQLTableRequirePK)] != 1 {
+		t.Errorf("Expected 1 primary key advice, got %d", counts[string(advisor.MySQLTableRequirePK)])
+	}
+	// 发生 panic 的规则只报告一条内部错误
+	internalErrors := advisor.InternalErrors(advices)
+	if counts[string(testPanicOnSelect)] != 1 || len(internalErrors) != 1 || internalErrors[0].RuleID != string(testPanicOnSelect) {
+		t.Errorf("Expected one internal error from the panicking rule, got %v", internalErrors)
+	} else if !strings.Contains(internalErrors[0].Content, "boom") {
+		t.Errorf("Expected the panic in the internal error, got %q", internalErrors[0].Content)
+	}
+
+	for _, advice := range advices {
+		if advice.RuleID == string(advisor.MySQLTableRequirePK) && (advice.Line != 3 || advice.Column != 3) {
+			t.Errorf("Expected primary key advice at 3:3, got %d:%d", advice.Line, advice.Column)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package advisor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PayloadAdvisor is implemented by advisors whose rules take a payload.
+// DefaultPayload returns a pointer to a new payload struct holding the
+// defaults; the JSON fields of the struct are the options the rule accepts.
+//
+// Fields may declare constraints in a validate tag, a comma-separated list
+// of:
+//
+//	min=N      integer options must be at least N
+//	max=N      integer options must be at most N
+//	enum=a|b   string options, or each element of string list options,
+//	           must be one of the values, ignoring case
+//	regexp     string options, or each element of string list options,
+//	           must compile as regular expressions
+//
+// Constraints that tags cannot express go in a Validate method of the
+// payload, see PayloadValidator.
+type PayloadAdvisor interface {
+	DefaultPayload() any
+}
+
+// PayloadValidator is implemented by payload structs with constraints
+// beyond their validate tags.
+type PayloadValidator interface {
+	Validate() error
+}
+
+// DecodePayload decodes the payload of a configured rule into the payload
+// struct declared by the advisor of the rule, over its defaults, validates
+// it and returns a pointer to the struct. Options the struct does not
+// declare, values of the wrong type and values that break the constraints
+// of the struct are errors. An empty payload decodes to the defaults, or to
+// nil for advisors that take no payload.
+func DecodePayload(engine Engine, advType Type, payload map[string]any) (any, error) {
+	advisor, err := getAdvisor(engine, advType)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadAdvisor, ok := advisor.(PayloadAdvisor)
+	if !ok {
+		if len(payload) > 0 {
+			return nil, errors.Errorf("rule %s takes no payload", advType)
+		}
+		return nil, nil
+	}
+
+	target := payloadAdvisor.DefaultPayload()
+	if len(payload) == 0 {
+		if err := ValidatePayload(advType, target); err != nil {
+			return nil, err
+		}
+		return target, nil
+	}
+
+	options := payloadOptions(target)
+	var names []string
+	for name := range payload {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !containsString(options, name) {
+			return nil, errors.Errorf("unknown option %q of rule %s%s", name, advType, suggestName(name, options))
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal payload of rule %s", advType)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, errors.Errorf("option %q of rule %s must be %s, got %s", typeErr.Field, advType, typeErr.Type, typeErr.Value)
+		}
+		return nil, errors.Wrapf(err, "invalid payload of rule %s", advType)
+	}
+	if err := ValidatePayload(advType, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// ValidatePayload checks a payload struct against the validate tags of its
+// fields and its Validate method.
+func ValidatePayload(advType Type, payload any) error {
+	v := reflect.ValueOf(payload)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, option := range payloadFields(v.Type()) {
+		if err := option.validate(v.FieldByIndex(option.index)); err != nil {
+			return errors.Wrapf(err, "option %q of rule %s", option.name, advType)
+		}
+	}
+	if validator, ok := payload.(PayloadValidator); ok {
+		if err := validator.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid payload of rule %s", advType)
+		}
+	}
+	return nil
+}
+
+// payloadField is an option of a payload struct with its constraints.
+type payloadField struct {
+	name   string
+	index  []int
+	typ    reflect.Type
+	min    *int64
+	max    *int64
+	enum   []string
+	regexp bool
+}
+
+// payloadFields returns the options of a payload struct type. It panics on
+// malformed validate tags, which are programming errors.
+func payloadFields(t reflect.Type) []*payloadField {
+	var fields []*payloadField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		option := &payloadField{name: name, index: field.Index, typ: field.Type}
+		if tag := field.Tag.Get("validate"); tag != "" {
+			for _, constraint := range strings.Split(tag, ",") {
+				key, value, _ := strings.Cut(constraint, "=")
+				switch key {
+				case "min", "max":
+					n, err := strconv.ParseInt(value, 10, 64)
+					if err != nil {
+						panic(fmt.Sprintf("advisor: invalid %s constraint of payload field %s.%s", key, t.Name(), field.Name))
+					}
+					if key == "min" {
+						option.min = &n
+					} else {
+						option.max = &n
+					}
+				case "enum":
+					option.enum = strings.Split(value, "|")
+				case "regexp":
+					option.regexp = true
+				default:
+					panic(fmt.Sprintf("advisor: unknown constraint %q of payload field %s.%s", key, t.Name(), field.Name))
+				}
+			}
+		}
+		fields = append(fields, option)
+	}
+	return fields
+}
+
+// validate checks the value of the option against its constraints.
+func (f *payloadField) validate(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f.min != nil && v.Int() < *f.min {
+			return errors.Errorf("must be at least %d, got %d", *f.min, v.Int())
+		}
+		if f.max != nil && v.Int() > *f.max {
+			return errors.Errorf("must be at most %d, got %d", *f.max, v.Int())
+		}
+	case reflect.String:
+		return f.validateString(v.String())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := f.validateString(v.Index(i).String()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f *payloadField) validateString(value string) error {
+	if len(f.enum) > 0 {
+		found := false
+		for _, allowed := range f.enum {
+			if strings.EqualFold(value, allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.Errorf("has invalid value %q, must be one of %s%s", value, strings.Join(f.enum, ", "), suggestName(strings.ToLower(value), f.enum))
+		}
+	}
+	if f.regexp {
+		if _, err := regexp.Compile(value); err != nil {
+			return errors.Wrapf(err, "is not a valid regular expression")
+		}
+	}
+	return nil
+}
+
+// payloadOptions returns the JSON field names of a payload struct.
+func payloadOptions(payload any) []string {
+	t := reflect.TypeOf(payload)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var options []string
+	for _, field := range payloadFields(t) {
+		options = append(options, field.name)
+	}
+	return options
+}
+
+// suggestName returns a ", did you mean ...?" hint naming the candidate
+// closest to a misspelled name, or "" when no candidate is close.
+func suggestName(name string, candidates []string) string {
+	best, bestDistance := "", len(name)/3+1
+	for _, candidate := range candidates {
+		if distance := editDistance(name, candidate); distance <= bestDistance && (best == "" || distance < bestDistance) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %q?", best)
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,393 @@
+package advisor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+// Global registry for advisors (Bytebase-style).
+var (
+	advisorMu sync.RWMutex
+	advisors  = make(map[Engine]map[Type]Advisor)
+)
+
+// Register makes an advisor available by the provided engine and type.
+// If Register is called twice with the same combination or if advisor is nil,
+// it panics.
+func Register(engine Engine, advType Type, advisor Advisor) {
+	advisorMu.Lock()
+	defer advisorMu.Unlock()
+
+	if advisor == nil {
+		panic("advisor: Register advisor is nil")
+	}
+
+	engineAdvisors, ok := advisors[engine]
+	if !ok {
+		advisors[engine] = map[Type]Advisor{
+			advType: advisor,
+		}
+	} else {
+		if _, dup := engineAdvisors[advType]; dup {
+			panic(fmt.Sprintf("advisor: Register called twice for advisor %v for %v", advType, engine))
+		}
+		engineAdvisors[advType] = advisor
+	}
+}
+
+// CheckByType runs the specified advisor and returns the advice list.
+// The statement is parsed first if checkCtx.AST is not set, and the catalog
+// is built from checkCtx.Metadata if checkCtx.Catalog is not set.
+func CheckByType(ctx context.Context, engine Engine, advType Type, checkCtx Context) (adviceList []*Advice, err error) {
+	// Panic recovery for safer execution
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			if panicError, ok := panicErr.(error); ok {
+				err = errors.Errorf("advisor check PANIC RECOVER, type: %v, err: %v", advType, panicError)
+			} else {
+				err = errors.Errorf("advisor check PANIC RECOVER, type: %v, err: %v", advType, panicErr)
+			}
+		}
+	}()
+
+	advisor, err := getAdvisor(engine, advType)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkCtx.AST == nil {
+		ast, err := ParseStatement(engine, checkCtx.SQL)
+		if err != nil {
+			return nil, err
+		}
+		checkCtx.AST = ast
+	}
+	if checkCtx.Catalog == nil {
+		checkCtx.Catalog = NewCatalog(checkCtx.DatabaseName, checkCtx.Metadata)
+	}
+
+	return advisor.Check(ctx, checkCtx)
+}
+
+// SQLReviewCheck parses checkCtx.SQL once and runs the configured rules of
+// checkCtx.Engine against it. When checkCtx.Rules is not empty only the
+// listed rule types run.
+//
+// Statements that fail to parse are reported as syntax error advice, and
+// the rules still run on the statements that parsed.
+//
+// The statements are applied in order to checkCtx.Catalog, built from
+// checkCtx.Metadata when not set. The statements that fail against it are
+// reported by the configured WalkThroughAdvisor rule, if any.
+//
+// Listener rules are split across MaxWorkers(ctx) workers that walk each
+// statement at the same time, see walkListeners. The other rules run
+// concurrently on at most MaxWorkers(ctx) goroutines. Each rule is limited
+// to RuleTimeout(ctx). A rule that fails, panics or times out does not stop
+// the others; it is reported as internal error advice instead of its
+// advice. When ctx is done before the review ends, the rules that did not
+// finish are reported the same way.
+//
+// Finally the suppression comments of the script apply to the advice as
+// set by checkCtx.Suppression, and the advice is sorted with SortAdvices.
+// Internal error advice cannot be suppressed.
+func SQLReviewCheck(ctx context.Context, rules []*Rule, checkCtx Context) ([]*Advice, error) {
+	var adviceList []*Advice
+
+	ast, err := ParseStatement(checkCtx.Engine, checkCtx.SQL)
+	if err != nil {
+		var syntaxErrors mysqlparser.SyntaxErrors
+		if !errors.As(err, &syntaxErrors) {
+			return nil, err
+		}
+		adviceList = append(adviceList, NewSyntaxErrorAdvices(syntaxErrors)...)
+	}
+	checkCtx.AST = ast
+	if checkCtx.Catalog == nil {
+		checkCtx.Catalog = NewCatalog(checkCtx.DatabaseName, checkCtx.Metadata)
+	}
+
+	selected := make(map[Type]bool)
+	for _, ruleType := range checkCtx.Rules {
+		selected[Type(ruleType)] = true
+	}
+
+	// Listener rules share the walk over the tree; the others run on
+	// their own.
+	var listeners []NodeListener
+	var listenerRules []*Rule
+	var checks []*ruleCheck
+	var walkThroughRule *Rule
+	var walkThroughAdvisor WalkThroughAdvisor
+	var internalErrors []*Advice
+	for _, rule := range rules {
+		if rule.Engine != checkCtx.Engine {
+			continue
+		}
+		if len(selected) > 0 && !selected[rule.Type] {
+			continue
+		}
+
+		ruleCtx := checkCtx
+		ruleCtx.Rule = rule
+		if listenerAdvisor, ok := lookupListenerAdvisor(rule); ok {
+			listener, err := newListener(listenerAdvisor, ruleCtx)
+			if err != nil {
+				// 记录错误但继续执行其他规则
+				internalErrors = append(internalErrors, NewInternalErrorAdvice(rule.Type, err))
+				continue
+			}
+			listeners = append(listeners, listener)
+			listenerRules = append(listenerRules, rule)
+			continue
+		}
+		if advisor, ok := lookupWalkThroughAdvisor(rule); ok {
+			walkThroughRule, walkThroughAdvisor = rule, advisor
+			continue
+		}
+
+		checks = append(checks, &ruleCheck{rule: rule, checkCtx: ruleCtx})
+	}
+
+	runChecks(ctx, checks)
+	cat := checkCtx.Catalog
+	for _, check := range checks {
+		if check.abandoned && cat == checkCtx.Catalog {
+			// The abandoned check may still read the catalog, walk a copy.
+			cat = cat.Clone()
+		}
+		if check.err != nil {
+			// 记录错误但继续执行其他规则
+			internalErrors = append(internalErrors, NewInternalErrorAdvice(check.rule.Type, check.err))
+			continue
+		}
+		adviceList = append(adviceList, check.advices...)
+	}
+
+	walk, err := walkListeners(ctx, checkCtx.AST, listeners, cat)
+	if err != nil {
+		return nil, err
+	}
+	if walkThroughAdvisor != nil {
+		// The walk-through errors are incomplete when the walk stopped early
+		err := walk.err
+		var advices []*Advice
+		if err == nil {
+			advices, err = walkThroughAdvisor.WalkThroughAdvices(walkThroughRule, walk.walkThroughErrors)
+		}
+		if err != nil {
+			internalErrors = append(internalErrors, NewInternalErrorAdvice(walkThroughRule.Type, err))
+		}
+		adviceList = append(adviceList, advices...)
+	}
+	for i, listener := range listeners {
+		if walk.errs[i] != nil {
+			internalErrors = append(internalErrors, NewInternalErrorAdvice(listenerRules[i].Type, walk.errs[i]))
+			continue
+		}
+		adviceList = append(adviceList, listener.Advices()...)
+	}
+
+	adviceList, err = applySuppressions(checkCtx.SQL, adviceList, checkCtx.Suppression)
+	if err != nil {
+		return nil, err
+	}
+	adviceList = append(adviceList, internalErrors...)
+	SortAdvices(adviceList)
+	return adviceList, nil
+}
+
+// newListener calls NewListener and turns a panic into an error.
+func newListener(advisor ListenerAdvisor, checkCtx Context) (listener NodeListener, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			err = errors.Errorf("advisor listener PANIC RECOVER, type: %v, err: %v", checkCtx.Rule.Type, panicErr)
+		}
+	}()
+	return advisor.NewListener(checkCtx)
+}
+
+// NewInternalErrorAdvice reports a rule that failed to run. The advice has
+// the rule ID of the rule and no position.
+func NewInternalErrorAdvice(ruleType Type, err error) *Advice {
+	content := fmt.Sprintf("Rule %s failed to run: %v", ruleType, err)
+	return &Advice{
+		Status:  LevelError,
+		Code:    CodeInternalError,
+		Title:   "Internal error",
+		Content: content,
+		// Legacy fields for compatibility
+		Level:   LevelError,
+		Message: content,
+		RuleID:  string(ruleType),
+	}
+}
+
+// InternalErrors returns the internal error advice of an advice list, the
+// rules that failed to run.
+func InternalErrors(adviceList []*Advice) []*Advice {
+	var internalErrors []*Advice
+	for _, advice := range adviceList {
+		if advice.Code == CodeInternalError {
+			internalErrors = append(internalErrors, advice)
+		}
+	}
+	return internalErrors
+}
+
+// NewSyntaxErrorAdvices converts syntax errors to ERROR advice.
+func NewSyntaxErrorAdvices(syntaxErrors []*mysqlparser.SyntaxError) []*Advice {
+	adviceList := make([]*Advice, 0, len(syntaxErrors))
+	for _, syntaxErr := range syntaxErrors {
+		near := "end of statement"
+		if syntaxErr.Token != "" {
+			near = fmt.Sprintf("%q", syntaxErr.Token)
+		}
+		content := fmt.Sprintf("Syntax error near %s: %s", near, syntaxErr.Message)
+
+		adviceList = append(adviceList, &Advice{
+			Status:        LevelError,
+			Code:          CodeStatementSyntaxError,
+			Title:         "Syntax error",
+			Content:       content,
+			StartPosition: &Position{Line: syntaxErr.Line, Column: syntaxErr.Column},
+			// Legacy fields for compatibility
+			Level:   LevelError,
+			Message: content,
+			Line:    syntaxErr.Line,
+			Column:  syntaxErr.Column,
+			RuleID:  string(SyntaxCheck),
+		})
+	}
+	return adviceList
+}
+
+// ErrEngineNotSupported is returned by ParseStatement, and so by the checks,
+// for engines without a parser.
+var ErrEngineNotSupported = errors.New("engine not supported")
+
+// ParseStatement parses the SQL text with the parser of the given engine.
+// The returned value is what rules find in Context.AST.
+func ParseStatement(engine Engine, statement string) (any, error) {
+	switch engine {
+	case MySQL:
+		return mysqlparser.ParseMySQL(statement)
+	default:
+		return nil, errors.Wrapf(ErrEngineNotSupported, "advisor: no parser for engine %v", engine)
+	}
+}
+
+// GetRegisteredEngines returns the engines with registered advisors.
+func GetRegisteredEngines() []Engine {
+	advisorMu.RLock()
+	defer advisorMu.RUnlock()
+
+	var engines []Engine
+	for engine := range advisors {
+		engines = append(engines, engine)
+	}
+	sort.Slice(engines, func(i, j int) bool { return engines[i] < engines[j] })
+	return engines
+}
+
+// GetRegisteredAdvisors returns all registered advisor types for a given engine.
+func GetRegisteredAdvisors(engine Engine) []Type {
+	advisorMu.RLock()
+	defer advisorMu.RUnlock()
+
+	engineAdvisors, ok := advisors[engine]
+	if !ok {
+		return nil
+	}
+
+	var types []Type
+	for advType := range engineAdvisors {
+		types = append(types, advType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// RuleInfo describes a configured rule for listings.
+type RuleInfo struct {
+	Type        Type   `json:"type"`
+	Engine      Engine `json:"engine"`
+	Level       Level  `json:"level"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// DescribeRules returns the listing information of the configured rules.
+// Rules without a registered advisor are skipped.
+func DescribeRules(rules []*Rule) []*RuleInfo {
+	var infos []*RuleInfo
+	for _, rule := range rules {
+		advisor, err := getAdvisor(rule.Engine, rule.Type)
+		if err != nil {
+			continue
+		}
+
+		info := &RuleInfo{
+			Type:   rule.Type,
+			Engine: rule.Engine,
+			Level:  rule.Level,
+			Title:  string(rule.Type),
+		}
+		if describer, ok := advisor.(Describer); ok {
+			info.Title = describer.Title()
+			info.Description = describer.Description()
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// lookupListenerAdvisor returns the advisor of rule if it takes part in the
+// shared tree walk.
+func lookupListenerAdvisor(rule *Rule) (ListenerAdvisor, bool) {
+	advisor, err := getAdvisor(rule.Engine, rule.Type)
+	if err != nil {
+		return nil, false
+	}
+	listenerAdvisor, ok := advisor.(ListenerAdvisor)
+	return listenerAdvisor, ok
+}
+
+// lookupWalkThroughAdvisor returns the advisor of rule if it reports the
+// walk-through errors of the catalog.
+func lookupWalkThroughAdvisor(rule *Rule) (WalkThroughAdvisor, bool) {
+	advisor, err := getAdvisor(rule.Engine, rule.Type)
+	if err != nil {
+		return nil, false
+	}
+	walkThroughAdvisor, ok := advisor.(WalkThroughAdvisor)
+	return walkThroughAdvisor, ok
+}
+
+// getAdvisor looks up a registered advisor.
+func getAdvisor(engine Engine, advType Type) (Advisor, error) {
+	advisorMu.RLock()
+	defer advisorMu.RUnlock()
+
+	engineAdvisors, ok := advisors[engine]
+	if !ok {
+		return nil, errors.Errorf("advisor: unknown engine type %v", engine)
+	}
+
+	advisor, ok := engineAdvisors[advType]
+	if !ok {
+		var types []string
+		for registered := range engineAdvisors {
+			types = append(types, string(registered))
+		}
+		sort.Strings(types)
+		return nil, errors.Errorf("advisor: unknown advisor %v for %v%s", advType, engine, suggestName(string(advType), types))
+	}
+	return advisor, nil
+}
@@ -0,0 +1,131 @@
+package advisor_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/rules/mysql"
+)
+
+// TestSQLReviewCheckSyntaxError 测试语法错误作为 ERROR 级别的审查结果返回
+func TestSQLReviewCheckSyntaxError(t *testing.T) {
+	checkCtx := advisor.Context{
+		SQL:    "CREATE TABLEE broken (id INT);\nCREATE TABLE no_pk (id INT);",
+		Engine: advisor.MySQL,
+	}
+
+	advices, err := advisor.SQLReviewCheck(context.Background(), mysql.DefaultRules(), checkCtx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var syntaxAdvice, pkAdvice *advisor.Advice
+	for _, advice := range advices {
+		switch advice.Code {
+		case advisor.CodeStatementSyntaxError:
+			syntaxAdvice = advice
+		case advisor.CodeTableNoPrimaryKey:
+			pkAdvice = advice
+		}
+	}
+
+	if syntaxAdvice == nil {
+		t.Fatalf("Expected syntax error advice, got %v", advices)
+	}
+	if syntaxAdvice.Status != advisor.LevelError {
+		t.Errorf("Expected ERROR status, got %s", syntaxAdvice.Status)
+	}
+	if syntaxAdvice.StartPosition.Line != 1 || syntaxAdvice.StartPosition.Column != 8 {
+		t.Errorf("Expected syntax error at 1:8, got %d:%d", syntaxAdvice.StartPosition.Line, syntaxAdvice.StartPosition.Column)
+	}
+
+	// 其他语句仍然会被规则检查
+	if pkAdvice == nil || pkAdvice.StartPosition.Line != 2 {
+		t.Errorf("Expected primary key advice on line 2, got %v", pkAdvice)
+	}
+}
+
+// TestSQLReviewCheckEngineNotSupported 测试没有解析器的引擎返回 ErrEngineNotSupported，而不是审查结果
+func TestSQLReviewCheckEngineNotSupported(t *testing.T) {
+	checkCtx := advisor.Context{
+		SQL:    "SELECT 1;",
+		Engine: advisor.PostgreSQL,
+	}
+
+	advices, err := advisor.SQLReviewCheck(context.Background(), mysql.DefaultRules(), checkCtx)
+	if !errors.Is(err, advisor.ErrEngineNotSupported) {
+		t.Fatalf("Expected ErrEngineNotSupported, got advices %v, error %v", advices, err)
+	}
+}
+
+// TestSQLReviewCheckWalkThrough 测试离线审查时脚本逐条应用到 catalog 发现的错误，按推演规则的级别报告
+func TestSQLReviewCheckWalkThrough(t *testing.T) {
+	checkCtx := advisor.Context{
+		SQL:    "CREATE TABLE t (id INT PRIMARY KEY);\nALTER TABLE t ADD COLUMN id INT;\nALTER TABLE users ADD COLUMN id INT;",
+		Engine: advisor.MySQL,
+	}
+	rules := []*advisor.Rule{{Type: advisor.MySQLSchemaWalkThrough, Level: advisor.LevelWarning, Engine: advisor.MySQL}}
+
+	advices, err := advisor.SQLReviewCheck(context.Background(), rules, checkCtx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(advices) != 1 {
+		t.Fatalf("Expected one walk-through advice, got %v", advices)
+	}
+	advice := advices[0]
+	if advice.Code != advisor.CodeColumnExists || advice.Status != advisor.LevelWarning || advice.RuleID != string(advisor.MySQLSchemaWalkThrough) {
+		t.Errorf("Unexpected advice %+v", advice)
+	}
+	if advice.StartPosition.Line != 2 || advice.StartPosition.Column != 15 {
+		t.Errorf("Expected advice at 2:15, got %d:%d", advice.StartPosition.Line, advice.StartPosition.Column)
+	}
+
+	// 未配置推演规则时不报告推演错误
+	advices, err = advisor.SQLReviewCheck(context.Background(), nil, checkCtx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(advices) != 0 {
+		t.Errorf("Expected no advice without the walk-through rule, got %v", advices)
+	}
+}
+
+// TestSQLReviewCheckRuleFailure 测试无法运行的规则报告为带规则 ID 和原因的内部错误
+func TestSQLReviewCheckRuleFailure(t *testing.T) {
+	rules := []*advisor.Rule{
+		{Type: advisor.MySQLNamingConvention, Level: advisor.LevelWarning, Engine: advisor.MySQL, Payload: map[string]any{"max_length": "long"}},
+		{Type: "mysql.no-such-rule", Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLTableRequirePK, Level: advisor.LevelError, Engine: advisor.MySQL},
+	}
+	checkCtx := advisor.Context{
+		// 抑制注释不影响内部错误
+		SQL:    "-- sql-review:disable-file mysql.naming.convention\nCREATE TABLE t (id INT);",
+		Engine: advisor.MySQL,
+	}
+
+	advices, err := advisor.SQLReviewCheck(context.Background(), rules, checkCtx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	internalErrors := advisor.InternalErrors(advices)
+	if len(internalErrors) != 2 {
+		t.Fatalf("Expected 2 internal errors, got %v", advices)
+	}
+	for i, ruleID := range []string{"mysql.naming.convention", "mysql.no-such-rule"} {
+		advice := internalErrors[i]
+		if advice.RuleID != ruleID || advice.Status != advisor.LevelError || !strings.Contains(advice.Content, ruleID) {
+			t.Errorf("Unexpected internal error %+v", advice)
+		}
+	}
+	if !strings.Contains(internalErrors[0].Content, "invalid payload") {
+		t.Errorf("Expected the cause in %q", internalErrors[0].Content)
+	}
+	if len(advices) != 3 {
+		t.Errorf("Expected the primary key advice besides the internal errors, got %v", advices)
+	}
+}
@@ -0,0 +1,124 @@
+package advisor
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// PayloadSchema returns the JSON Schema of the payload of a rule, built
+// from the payload struct declared by its advisor: the option types, their
+// defaults and the constraints of their validate tags. It returns nil for
+// rules that take no payload.
+func PayloadSchema(engine Engine, advType Type) (map[string]any, error) {
+	advisor, err := getAdvisor(engine, advType)
+	if err != nil {
+		return nil, err
+	}
+	payloadAdvisor, ok := advisor.(PayloadAdvisor)
+	if !ok {
+		return nil, nil
+	}
+
+	payload := reflect.ValueOf(payloadAdvisor.DefaultPayload())
+	for payload.Kind() == reflect.Pointer {
+		payload = payload.Elem()
+	}
+
+	properties := make(map[string]any)
+	for _, field := range payloadFields(payload.Type()) {
+		schema := field.schema(field.typ)
+		if value := defaultValue(payload.FieldByIndex(field.index)); value != nil {
+			schema["default"] = value
+		}
+		properties[field.name] = schema
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}, nil
+}
+
+// schema returns the JSON Schema of a value of type t of the option. The
+// constraints of the option apply to integers, strings and the strings of
+// string lists.
+func (f *payloadField) schema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		schema := map[string]any{"type": "integer"}
+		if f.min != nil {
+			schema["minimum"] = *f.min
+		}
+		if f.max != nil {
+			schema["maximum"] = *f.max
+		}
+		return schema
+	case reflect.String:
+		schema := map[string]any{"type": "string"}
+		if len(f.enum) > 0 {
+			// The values are accepted in any case, which "enum" cannot
+			// express.
+			schema["pattern"] = CaseInsensitivePattern(f.enum)
+			schema["examples"] = f.enum
+		}
+		if f.regexp {
+			// Not "format": "regex", which means ECMA 262 syntax; RE2
+			// patterns such as (?i) would be rejected.
+			schema["description"] = "正则表达式（Go RE2 语法）"
+		}
+		return schema
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": f.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": (&payloadField{}).schema(t.Elem())}
+	default:
+		return map[string]any{}
+	}
+}
+
+// defaultValue returns the JSON value of a default option value. Nil lists
+// and maps are empty.
+func defaultValue(v reflect.Value) any {
+	switch {
+	case v.Kind() == reflect.Slice && v.IsNil():
+		return []any{}
+	case v.Kind() == reflect.Map && v.IsNil():
+		return map[string]any{}
+	}
+
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	return value
+}
+
+// CaseInsensitivePattern returns a JSON Schema pattern that matches any of
+// the values, ignoring case. JSON Schema patterns are ECMA 262 regular
+// expressions, which have no case-insensitive flag, so letters are spelled
+// as classes such as [Ee].
+func CaseInsensitivePattern(values []string) string {
+	alternatives := make([]string, len(values))
+	for i, value := range values {
+		var b strings.Builder
+		for _, r := range value {
+			lower, upper := unicode.ToLower(r), unicode.ToUpper(r)
+			if lower == upper {
+				b.WriteString(regexp.QuoteMeta(string(r)))
+				continue
+			}
+			b.WriteString("[" + string(lower) + string(upper) + "]")
+		}
+		alternatives[i] = b.String()
+	}
+	return "^(" + strings.Join(alternatives, "|") + ")$"
+}
@@ -0,0 +1,353 @@
+package advisor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+// suppressionPrefix starts the comments that suppress advice, e.g.
+//
+//	-- sql-review:disable-next-line mysql.table.require-pk -- legacy table
+//
+// The directive is followed by the rule types it applies to, separated by
+// commas or spaces, all rules when none is given, and optionally by "--"
+// and a justification.
+const suppressionPrefix = "sql-review:"
+
+// Suppression directives.
+const (
+	// directiveDisableNextLine suppresses advice on the line after the
+	// comment.
+	directiveDisableNextLine = "disable-next-line"
+	// directiveDisable suppresses advice from the comment to the next
+	// enable directive of the same rules, or to the end of the script.
+	directiveDisable = "disable"
+	// directiveEnable ends disable ranges.
+	directiveEnable = "enable"
+	// directiveDisableFile suppresses advice in the whole script.
+	directiveDisableFile = "disable-file"
+)
+
+// SuppressionOptions controls how suppression comments apply.
+type SuppressionOptions struct {
+	// ReportSuppressed keeps suppressed advice at INFO level, with the
+	// justification of the suppression, instead of dropping it.
+	ReportSuppressed bool `json:"report_suppressed" yaml:"report_suppressed"`
+	// RequireJustification makes suppressions without a justification
+	// ineffective. They are reported instead.
+	RequireJustification bool `json:"require_justification" yaml:"require_justification"`
+	// ReportUnused reports suppressions that no longer match any advice.
+	ReportUnused bool `json:"report_unused" yaml:"report_unused"`
+}
+
+// suppression is one disable directive of a script.
+type suppression struct {
+	directive string
+	// rules holds the lowercase rule types, empty for all rules.
+	rules         []string
+	justification string
+	position      *Position
+	// used holds the rules that matched advice, "" when rules is empty.
+	used map[string]bool
+}
+
+// suppressionRange is a range of lines where a suppression applies.
+type suppressionRange struct {
+	suppression *suppression
+	// from and to are the first line and the line after the last line.
+	from, to int
+	// except holds the rules an enable directive took out of a disable
+	// directive of all rules or several rules.
+	except map[string]bool
+}
+
+// applySuppressions applies the suppression comments of statement to the
+// advice list. Suppressed advice is dropped, or kept at INFO level when
+// options.ReportSuppressed is set. Malformed suppressions, suppressions
+// missing a required justification and, with options.ReportUnused, unused
+// suppressions are reported as WARNING advice.
+func applySuppressions(statement string, adviceList []*Advice, options SuppressionOptions) ([]*Advice, error) {
+	comments, err := mysqlparser.SplitComments(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges, suppressions, problems := parseSuppressions(comments, options)
+
+	var result []*Advice
+	for _, advice := range adviceList {
+		matched := matchSuppression(ranges, advice)
+		if matched == nil {
+			result = append(result, advice)
+			continue
+		}
+		if options.ReportSuppressed {
+			result = append(result, suppressedAdvice(advice, matched))
+		}
+	}
+	result = append(result, problems...)
+
+	if options.ReportUnused {
+		for _, s := range suppressions {
+			result = append(result, unusedSuppressionAdvices(s)...)
+		}
+	}
+	return result, nil
+}
+
+// parseSuppressions reads the directives of the comments in order and
+// returns the ranges they suppress, the disable directives and the advice
+// about malformed directives.
+func parseSuppressions(comments []*mysqlparser.Comment, options SuppressionOptions) ([]*suppressionRange, []*suppression, []*Advice) {
+	var ranges, open []*suppressionRange
+	var suppressions []*suppression
+	var problems []*Advice
+
+	for _, comment := range comments {
+		body, ok := directiveBody(comment.Text)
+		if !ok {
+			continue
+		}
+		position := &Position{Line: comment.Line, Column: comment.Column}
+
+		directive, rest, _ := strings.Cut(body, " ")
+		ruleList, justification, _ := strings.Cut(rest, "--")
+		justification = strings.TrimSpace(justification)
+		rules, unknown := parseSuppressionRules(ruleList)
+		for _, rule := range unknown {
+			problems = append(problems, newSuppressionAdvice(CodeSuppressionInvalid, "Invalid suppression",
+				fmt.Sprintf("Suppression comment names unknown rule %q", rule), position))
+		}
+
+		switch directive {
+		case directiveDisableNextLine, directiveDisable, directiveDisableFile, directiveEnable:
+		default:
+			problems = append(problems, newSuppressionAdvice(CodeSuppressionInvalid, "Invalid suppression",
+				fmt.Sprintf("Unknown suppression directive %q", suppressionPrefix+directive), position))
+			continue
+		}
+		if len(unknown) > 0 && len(rules) == 0 {
+			// Only unknown rules, do not turn this into a directive of all
+			// rules.
+			continue
+		}
+		if directive == directiveEnable {
+			open = enableSuppressions(open, rules, comment.Line)
+			continue
+		}
+		if justification == "" && options.RequireJustification {
+			problems = append(problems, newSuppressionAdvice(CodeSuppressionJustificationRequired, "Suppression requires justification",
+				fmt.Sprintf("Suppression comment %q has no justification, add one after \"--\"", suppressionPrefix+directive), position))
+			continue
+		}
+
+		s := &suppression{
+			directive:     directive,
+			rules:         rules,
+			justification: justification,
+			position:      position,
+			used:          make(map[string]bool),
+		}
+		suppressions = append(suppressions, s)
+
+		r := &suppressionRange{suppression: s, from: comment.Line, to: math.MaxInt}
+		switch directive {
+		case directiveDisableNextLine:
+			r.from, r.to = comment.EndLine+1, comment.EndLine+2
+		case directiveDisable:
+			open = append(open, r)
+		case directiveDisableFile:
+			r.from = 0
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, suppressions, problems
+}
+
+// directiveBody returns the text of a suppression comment after the
+// prefix, or false when the comment is not a suppression comment.
+func directiveBody(text string) (string, bool) {
+	switch {
+	case strings.HasPrefix(text, "--"):
+		text = text[2:]
+	case strings.HasPrefix(text, "#"):
+		text = text[1:]
+	case strings.HasPrefix(text, "/*"):
+		text = strings.TrimSuffix(text[2:], "*/")
+	}
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, suppressionPrefix) {
+		return "", false
+	}
+	return strings.Join(strings.Fields(text[len(suppressionPrefix):]), " "), true
+}
+
+// parseSuppressionRules splits the rule list of a directive and returns the
+// lowercase rule types and the names that are not rule types.
+func parseSuppressionRules(ruleList string) ([]string, []string) {
+	var rules, unknown []string
+	for _, rule := range strings.FieldsFunc(ruleList, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		rule = strings.ToLower(rule)
+		if !isSuppressibleType(Type(rule)) {
+			unknown = append(unknown, rule)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, unknown
+}
+
+// isSuppressibleType reports whether advice of a type can be suppressed:
+// the registered advisor types and the syntax check.
+func isSuppressibleType(advType Type) bool {
+	switch advType {
+	case SyntaxCheck:
+		return true
+	}
+	advisorMu.RLock()
+	defer advisorMu.RUnlock()
+	for _, engineAdvisors := range advisors {
+		if _, ok := engineAdvisors[advType]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// enableSuppressions ends the open disable ranges of rules, all ranges when
+// rules is empty, at line and returns the ranges that stay open.
+func enableSuppressions(open []*suppressionRange, rules []string, line int) []*suppressionRange {
+	var stillOpen []*suppressionRange
+	for _, r := range open {
+		r.to = line
+		if len(rules) == 0 {
+			continue
+		}
+
+		except := map[string]bool{}
+		for rule := range r.except {
+			except[rule] = true
+		}
+		for _, rule := range rules {
+			except[rule] = true
+		}
+		reopened := &suppressionRange{suppression: r.suppression, from: line, to: math.MaxInt, except: except}
+		if reopened.matchesAny() {
+			stillOpen = append(stillOpen, reopened)
+		}
+	}
+	return stillOpen
+}
+
+// matchesAny reports whether some rule is still suppressed by the range.
+func (r *suppressionRange) matchesAny() bool {
+	if len(r.suppression.rules) == 0 {
+		return true
+	}
+	for _, rule := range r.suppression.rules {
+		if !r.except[rule] {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the range suppresses advice of rule on line, and
+// marks the suppression used.
+func (r *suppressionRange) matches(rule string, line int) bool {
+	if line < r.from || line >= r.to || r.except[rule] {
+		return false
+	}
+	s := r.suppression
+	if len(s.rules) == 0 {
+		// A directive without rules leaves syntax errors reported, they
+		// are only suppressed by naming the syntax check.
+		if rule == string(SyntaxCheck) {
+			return false
+		}
+		s.used[""] = true
+		return true
+	}
+	for _, suppressed := range s.rules {
+		if suppressed == rule {
+			s.used[rule] = true
+			return true
+		}
+	}
+	return false
+}
+
+// matchSuppression returns the first suppression that applies to advice,
+// or nil.
+func matchSuppression(ranges []*suppressionRange, advice *Advice) *suppression {
+	if advice.RuleID == string(SuppressionCheck) {
+		return nil
+	}
+	line, _ := advice.position()
+	rule := strings.ToLower(advice.RuleID)
+	for _, r := range ranges {
+		if r.matches(rule, line) {
+			return r.suppression
+		}
+	}
+	return nil
+}
+
+// suppressedAdvice returns a copy of advice at INFO level that carries the
+// justification of the suppression.
+func suppressedAdvice(advice *Advice, s *suppression) *Advice {
+	suppressed := *advice
+	note := "suppressed"
+	if s.justification != "" {
+		note += ": " + s.justification
+	}
+	suppressed.Status = LevelInfo
+	suppressed.Level = LevelInfo
+	suppressed.Content = fmt.Sprintf("%s (%s)", advice.Content, note)
+	suppressed.Message = fmt.Sprintf("%s (%s)", advice.Message, note)
+	suppressed.Suppressed = true
+	return &suppressed
+}
+
+// unusedSuppressionAdvices reports the rules of a suppression that matched
+// no advice.
+func unusedSuppressionAdvices(s *suppression) []*Advice {
+	directive := suppressionPrefix + s.directive
+	if len(s.rules) == 0 {
+		if s.used[""] {
+			return nil
+		}
+		return []*Advice{newSuppressionAdvice(CodeSuppressionUnused, "Unused suppression",
+			fmt.Sprintf("Suppression comment %q does not match any advice", directive), s.position)}
+	}
+
+	var adviceList []*Advice
+	for _, rule := range s.rules {
+		if s.used[rule] {
+			continue
+		}
+		adviceList = append(adviceList, newSuppressionAdvice(CodeSuppressionUnused, "Unused suppression",
+			fmt.Sprintf("Suppression comment %q of rule %s does not match any advice", directive, rule), s.position))
+	}
+	return adviceList
+}
+
+func newSuppressionAdvice(code int32, title, content string, position *Position) *Advice {
+	return &Advice{
+		Status:        LevelWarning,
+		Code:          code,
+		Title:         title,
+		Content:       content,
+		StartPosition: position,
+		// Legacy fields for compatibility
+		Level:   LevelWarning,
+		Message: content,
+		Line:    position.Line,
+		Column:  position.Column,
+		RuleID:  string(SuppressionCheck),
+	}
+}
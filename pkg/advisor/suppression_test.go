@@ -0,0 +1,106 @@
+package advisor_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/rules/mysql"
+)
+
+// TestSuppression 测试内联抑制注释对审查结果的影响
+func TestSuppression(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		options   advisor.SuppressionOptions
+		expected  []string
+	}{
+		{
+			name:      "Without suppression",
+			statement: "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);",
+			expected:  []string{"1:1 801 ERROR", "2:1 801 ERROR"},
+		},
+		{
+			name:      "Disable next line",
+			statement: "-- sql-review:disable-next-line mysql.table.require-pk -- legacy table\nCREATE TABLE a (id INT);\nCREATE TABLE b (id INT);",
+			expected:  []string{"3:1 801 ERROR"},
+		},
+		{
+			name:      "Disable next line of another rule",
+			statement: "-- sql-review:disable-next-line mysql.select.performance\nCREATE TABLE a (id INT);",
+			expected:  []string{"2:1 801 ERROR"},
+		},
+		{
+			name:      "Disable and enable",
+			statement: "/* sql-review:disable */\nCREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n/* sql-review:enable */\nCREATE TABLE c (id INT);",
+			expected:  []string{"5:1 801 ERROR"},
+		},
+		{
+			name:      "Enable one rule of a disable of all rules",
+			statement: "# sql-review:disable\nCREATE TABLE a (id INT);\n# sql-review:enable mysql.table.require-pk\nCREATE TABLE b (id INT);\nSELECT * FROM b;",
+			expected:  []string{"4:1 801 ERROR"},
+		},
+		{
+			name:      "Disable file",
+			statement: "CREATE TABLE a (id INT);\n-- sql-review:disable-file mysql.table.require-pk, mysql.select.performance\nCREATE TABLE b (id INT);",
+		},
+		{
+			name:      "Disable file of all rules keeps syntax errors",
+			statement: "-- sql-review:disable-file\nSELEC 1;\n/* sql-review:disable */\nCREATE TABLE a (id INT);",
+			expected:  []string{"2:1 201 ERROR"},
+		},
+		{
+			name:      "Disable syntax errors by name",
+			statement: "-- sql-review:disable-next-line syntax -- generated by a template\nSELEC 1;",
+		},
+		{
+			name:      "Report suppressed advice",
+			statement: "-- sql-review:disable-next-line mysql.table.require-pk -- legacy table\nCREATE TABLE a (id INT);",
+			options:   advisor.SuppressionOptions{ReportSuppressed: true},
+			expected:  []string{"2:1 801 INFO"},
+		},
+		{
+			name:      "Justification required",
+			statement: "-- sql-review:disable-next-line mysql.table.require-pk\nCREATE TABLE a (id INT);\n-- sql-review:disable-next-line mysql.table.require-pk -- legacy table\nCREATE TABLE b (id INT);",
+			options:   advisor.SuppressionOptions{RequireJustification: true},
+			expected:  []string{"1:1 1402 WARNING", "2:1 801 ERROR"},
+		},
+		{
+			name:      "Unused suppressions",
+			statement: "-- sql-review:disable-next-line mysql.table.require-pk, mysql.select.performance\nCREATE TABLE a (id INT);\n-- sql-review:disable\nCREATE TABLE b (id INT PRIMARY KEY);",
+			options:   advisor.SuppressionOptions{ReportUnused: true},
+			expected:  []string{"1:1 1403 WARNING", "3:1 1403 WARNING"},
+		},
+		{
+			name:      "Invalid suppressions",
+			statement: "-- sql-review:disable-next-line mysql.no-such-rule\nCREATE TABLE a (id INT);\n-- sql-review:ignore mysql.table.require-pk\nCREATE TABLE b (id INT);",
+			expected:  []string{"1:1 1401 WARNING", "2:1 801 ERROR", "3:1 1401 WARNING", "4:1 801 ERROR"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkCtx := advisor.Context{
+				SQL:         tt.statement,
+				Engine:      advisor.MySQL,
+				Rules:       []string{string(advisor.MySQLTableRequirePK)},
+				Suppression: tt.options,
+			}
+			advices, err := advisor.SQLReviewCheck(context.Background(), mysql.DefaultRules(), checkCtx)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var results []string
+			for _, advice := range advices {
+				results = append(results, fmt.Sprintf("%d:%d %d %s", advice.StartPosition.Line, advice.StartPosition.Column, advice.Code, advice.Status))
+			}
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
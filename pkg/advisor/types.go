@@ -1,5 +1,14 @@
 package advisor
 
+// SyntaxCheck is the pseudo advisor type of syntax error advice. It is not
+// registered and always reports at ERROR level.
+const SyntaxCheck Type = "syntax"
+
+// SuppressionCheck is the pseudo advisor type of the advice about the
+// suppression comments of a script. It is not registered, reports at
+// WARNING level and cannot be suppressed.
+const SuppressionCheck Type = "suppression"
+
 // MySQL advisor types inspired by Bytebase.
 const (
 	// MySQLTableRequirePK is an advisor type for MySQL table require primary key.
@@ -16,6 +25,42 @@ const (
 
 	// MySQLSelectPerformance is an advisor type for MySQL SELECT performance.
 	MySQLSelectPerformance Type = "mysql.select.performance"
+
+	// MySQLStatementInsert is an advisor type for MySQL INSERT and REPLACE statements.
+	MySQLStatementInsert Type = "mysql.statement.insert"
+
+	// MySQLColumnConstraint is an advisor type for MySQL column constraints.
+	MySQLColumnConstraint Type = "mysql.column.constraint"
+
+	// MySQLIndexDesign is an advisor type for MySQL index and key design.
+	MySQLIndexDesign Type = "mysql.index.design"
+
+	// MySQLTableEngine is an advisor type for MySQL table storage engines.
+	MySQLTableEngine Type = "mysql.table.engine"
+
+	// MySQLTableCharset is an advisor type for MySQL table charsets and collations.
+	MySQLTableCharset Type = "mysql.table.charset"
+
+	// MySQLTableComment is an advisor type for MySQL table comments.
+	MySQLTableComment Type = "mysql.table.comment"
+
+	// MySQLTablePartition is an advisor type for MySQL table partitioning.
+	MySQLTablePartition Type = "mysql.table.partition"
+
+	// MySQLTableNamePrefix is an advisor type for MySQL table name prefixes.
+	MySQLTableNamePrefix Type = "mysql.table.name-prefix"
+
+	// MySQLTableCreateAsSelect is an advisor type for MySQL CREATE TABLE ... AS SELECT.
+	MySQLTableCreateAsSelect Type = "mysql.table.create-as-select"
+
+	// MySQLTableCreateLike is an advisor type for MySQL CREATE TABLE ... LIKE.
+	MySQLTableCreateLike Type = "mysql.table.create-like"
+
+	// MySQLSchemaConsistency is an advisor type for MySQL DDL checked against the database schema.
+	MySQLSchemaConsistency Type = "mysql.schema.consistency"
+
+	// MySQLSchemaWalkThrough is an advisor type for MySQL statements that fail against the catalog.
+	MySQLSchemaWalkThrough Type = "mysql.schema.walk-through"
 )
 
 // Error codes for advisor checks.
@@ -23,19 +68,80 @@ const (
 	// Success codes
 	CodeOK int32 = 0
 
+	// Internal error codes, for rules that failed to run
+	CodeInternalError int32 = 1
+
+	// Syntax related error codes (200 range)
+	CodeStatementSyntaxError int32 = 201
+
 	// Table related error codes (800 range)
-	CodeTableNoPrimaryKey   int32 = 801
-	CodeTableNamingInvalid  int32 = 802
-	CodeTableCreateRequired int32 = 803
+	CodeTableNoPrimaryKey             int32 = 801
+	CodeTableNamingInvalid            int32 = 802
+	CodeTableCreateRequired           int32 = 803
+	CodeTableRequireTimeColumns       int32 = 804
+	CodeTableEngineDisallowed         int32 = 805
+	CodeTableRequireCharset           int32 = 806
+	CodeTableCharsetDisallowed        int32 = 807
+	CodeTableCollationDisallowed      int32 = 808
+	CodeTableRequireComment           int32 = 809
+	CodeTableCommentTooLong           int32 = 810
+	CodeTablePartitionDisallowed      int32 = 811
+	CodeTableNamePrefixInvalid        int32 = 812
+	CodeTableCreateAsSelectDisallowed int32 = 813
+	CodeTableCreateLikeDisallowed     int32 = 814
 
 	// Column related error codes (900 range)
-	CodeColumnTypeDisallowed int32 = 901
-	CodeColumnNamingInvalid  int32 = 902
-	CodeColumnRequireDefault int32 = 903
+	CodeColumnTypeDisallowed          int32 = 901
+	CodeColumnNamingInvalid           int32 = 902
+	CodeColumnRequireDefault          int32 = 903
+	CodeColumnTypeMismatch            int32 = 904
+	CodeColumnCannotNull              int32 = 905
+	CodeColumnRequireComment          int32 = 906
+	CodeColumnCommentTooLong          int32 = 907
+	CodeAutoIncrementColumnInvalid    int32 = 908
+	CodeColumnRequireCurrentTimestamp int32 = 909
+	CodeColumnCharsetDisallowed       int32 = 910
 
 	// Statement related error codes (1000 range)
 	CodeStatementSelectAll        int32 = 1001
 	CodeStatementNoWhere          int32 = 1002
 	CodeStatementUnsafeOperation  int32 = 1003
 	CodeStatementPerformanceIssue int32 = 1004
-)
\ No newline at end of file
+
+	// Index related error codes (1100 range)
+	CodeIndexNamingInvalid       int32 = 1101
+	CodeUniqueKeyNamingInvalid   int32 = 1102
+	CodeForeignKeyNamingInvalid  int32 = 1103
+	CodeIndexCountExceeded       int32 = 1104
+	CodeIndexColumnCountExceeded int32 = 1105
+	CodeIndexDuplicate           int32 = 1106
+	CodeIndexRedundant           int32 = 1107
+	CodeIndexLowSelectivity      int32 = 1108
+	CodePrimaryKeyTypeInvalid    int32 = 1109
+	CodeForeignKeyDisallowed     int32 = 1110
+
+	// Insert related error codes (1200 range)
+	CodeInsertColumnListRequired       int32 = 1201
+	CodeInsertTooManyRows              int32 = 1202
+	CodeInsertSelectAll                int32 = 1203
+	CodeInsertSelectUnbounded          int32 = 1204
+	CodeInsertReplaceDisallowed        int32 = 1205
+	CodeInsertOnDuplicateKeyDisallowed int32 = 1206
+
+	// Schema related error codes (1300 range)
+	CodeTableNotExists                  int32 = 1301
+	CodeTableExists                     int32 = 1302
+	CodeDropIndexedColumn               int32 = 1303
+	CodeAddNotNullColumnToNonEmptyTable int32 = 1304
+	CodeColumnExists                    int32 = 1305
+	CodeColumnNotExists                 int32 = 1306
+	CodeIndexExists                     int32 = 1307
+	CodeIndexNotExists                  int32 = 1308
+	CodeIndexColumnNotExists            int32 = 1309
+	CodePrimaryKeyExists                int32 = 1310
+
+	// Suppression related error codes (1400 range)
+	CodeSuppressionInvalid               int32 = 1401
+	CodeSuppressionJustificationRequired int32 = 1402
+	CodeSuppressionUnused                int32 = 1403
+)
@@ -3,24 +3,31 @@ package api
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/config"
 	"github.com/shenbo/sql-review-learning-demo/pkg/database"
+	"github.com/shenbo/sql-review-learning-demo/pkg/schemaload"
 )
 
 // Server HTTP服务器
 type Server struct {
 	dbManager *database.DatabaseManager
-	advisor   advisor.Advisor
+	policies  *config.PolicySet
+	metadata  *metadataCache
 }
 
-// NewServer 创建HTTP服务器
-func NewServer(dbManager *database.DatabaseManager, advisor advisor.Advisor) *Server {
+// NewServer 创建HTTP服务器，policies 提供基础规则和各审查策略的规则
+func NewServer(dbManager *database.DatabaseManager, policies *config.PolicySet) *Server {
 	return &Server{
 		dbManager: dbManager,
-		advisor:   advisor,
+		policies:  policies,
+		metadata:  newMetadataCache(defaultMetadataCacheTTL),
 	}
 }
 
@@ -43,19 +50,35 @@ type ConnectionSaveRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 	Engine   string `json:"engine" binding:"required"`
+	// Policy 审查该连接时使用的策略，为空时按数据库名和环境选择
+	Policy string `json:"policy"`
 }
 
 // SQLRequest SQL请求
 type SQLRequest struct {
 	SQL          string   `json:"sql" binding:"required"`
-	ConnectionID string   `json:"connection_id" binding:"required"`
+	ConnectionID string   `json:"connection_id"`
 	DryRun       bool     `json:"dry_run"`
 	Rules        []string `json:"rules"`
+	// Schema 基线 schema（GET /api/schema 返回的格式），不连接数据库时供 schema 相关规则使用
+	Schema *database.SchemaInfo `json:"schema,omitempty"`
+	// SchemaSQL 以 CREATE 语句给出的基线 schema
+	SchemaSQL string `json:"schema_sql,omitempty"`
+	// Suppression 内联抑制注释的处理方式
+	Suppression advisor.SuppressionOptions `json:"suppression"`
+	// Strict 为 true 时，有规则执行失败则返回失败
+	Strict bool `json:"strict"`
+	// RefreshSchema 为 true 时丢弃缓存的数据库元数据重新加载，在数据库 schema 变更后使用
+	RefreshSchema bool `json:"refresh_schema"`
 }
 
 // SQLResponse SQL响应
 type SQLResponse struct {
+	// Policy 审查使用的策略，使用基础规则时为空
+	Policy        string                 `json:"policy,omitempty"`
 	ReviewResults []*advisor.Advice      `json:"review_results"`
+	// Warnings 不影响审查结果返回的问题，如数据库元数据加载失败
+	Warnings      []string               `json:"warnings,omitempty"`
 	ExecuteResult *ExecuteResult         `json:"execute_result,omitempty"`
 	Schema        *database.SchemaInfo   `json:"schema,omitempty"`
 }
@@ -108,6 +131,14 @@ func (s *Server) SaveConnection(c *gin.Context) {
 		return
 	}
 
+	if _, ok := s.policies.Rules(req.Policy); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("unknown policy %q", req.Policy),
+		})
+		return
+	}
+
 	// 生成连接ID
 	id, err := generateID()
 	if err != nil {
@@ -124,6 +155,7 @@ func (s *Server) SaveConnection(c *gin.Context) {
 		Username: req.Username,
 		Password: req.Password,
 		Engine:   req.Engine,
+		Policy:   req.Policy,
 	}
 
 	if err := s.dbManager.AddConnection(config); err != nil {
@@ -179,6 +211,9 @@ func (s *Server) GetSchema(c *gin.Context) {
 		return
 	}
 
+	// 刚读取的 schema 同时刷新审查使用的元数据缓存
+	s.metadata.Put(connectionID, config, schemaload.FromSchemaInfo(schema))
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"schema":  schema,
@@ -193,36 +228,103 @@ func (s *Server) ReviewSQL(c *gin.Context) {
 		return
 	}
 
-	config, err := s.dbManager.GetConfig(req.ConnectionID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	if req.ConnectionID == "" && req.Schema == nil && req.SchemaSQL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "connection_id, schema or schema_sql is required"})
 		return
 	}
 
-	db, err := s.dbManager.GetConnection(req.ConnectionID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		return
+	// 构建审查上下文，没有连接时按 MySQL 离线审查
+	checkCtx := advisor.Context{
+		SQL:         req.SQL,
+		Engine:      advisor.MySQL,
+		Rules:       req.Rules,
+		Suppression: req.Suppression,
 	}
+	connectionPolicy := ""
+	var warnings []string
+	if req.ConnectionID != "" {
+		config, err := s.dbManager.GetConfig(req.ConnectionID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		db, err := s.dbManager.GetConnection(req.ConnectionID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		checkCtx.Engine = advisor.EngineFromName(config.Engine)
+		checkCtx.DatabaseName = config.Database
+		checkCtx.Connection = db
+		connectionPolicy = config.Policy
 
-	// 构建审查上下文
-	checkCtx := &advisor.Context{
-		SQL:          req.SQL,
-		Engine:       config.Engine,
-		DatabaseName: config.Database,
-		Rules:        req.Rules,
-		Connection:   db,
+		// 没有给出基线 schema 时加载数据库元数据，供规则对照真实 schema 检查。
+		// 加载失败（如没有 information_schema 权限）时不使用元数据继续审查
+		if req.Schema == nil && req.SchemaSQL == "" {
+			if req.RefreshSchema {
+				s.metadata.Invalidate(req.ConnectionID)
+			}
+			metadata, err := s.metadata.Get(req.ConnectionID, db, config)
+			if err != nil {
+				log.Printf("Reviewing without schema metadata of connection %s: %v", req.ConnectionID, err)
+				warnings = append(warnings, fmt.Sprintf("reviewed without schema metadata: %v", err))
+			}
+			checkCtx.Metadata = metadata
+		}
+	}
+
+	// 基线 schema 优先于数据库元数据
+	switch {
+	case req.Schema != nil:
+		checkCtx.Metadata = schemaload.FromSchemaInfo(req.Schema)
+		if checkCtx.DatabaseName == "" {
+			checkCtx.DatabaseName = req.Schema.DatabaseName
+		}
+	case req.SchemaSQL != "":
+		metadata, err := schemaload.FromSQL(req.SchemaSQL)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid schema_sql: %v", err)})
+			return
+		}
+		checkCtx.Metadata = metadata
+	}
+
+	// 按连接、数据库名和环境选择审查策略
+	policy := s.policies.Resolve(connectionPolicy, checkCtx.DatabaseName)
+	rules, ok := s.policies.Rules(policy)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("unknown policy %q", policy)})
+		return
 	}
 
 	// 执行SQL审查
-	advices, err := s.advisor.Check(c.Request.Context(), checkCtx)
+	advices, err := advisor.SQLReviewCheck(c.Request.Context(), rules, checkCtx)
+	if errors.Is(err, advisor.ErrEngineNotSupported) {
+		// 连接的数据库引擎没有 SQL 解析器，无法审查
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("engine %s is not supported for SQL review", checkCtx.Engine)})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	response := &SQLResponse{
+		Policy:        policy,
 		ReviewResults: advices,
+		Warnings:      warnings,
+	}
+
+	// 严格模式下规则执行失败视为审查失败，仍返回审查结果以便查看原因
+	if failed := advisor.InternalErrors(advices); req.Strict && len(failed) > 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("%d rule(s) failed to run", len(failed)),
+			"result":  response,
+		})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -231,28 +333,33 @@ func (s *Server) ReviewSQL(c *gin.Context) {
 	})
 }
 
-// ListRules 列出所有规则
+// ListRules 列出所有规则，policy 参数指定策略时列出该策略的规则
 func (s *Server) ListRules(c *gin.Context) {
-	if defaultAdvisor, ok := s.advisor.(*advisor.DefaultAdvisor); ok {
-		rules := defaultAdvisor.ListRules()
-		rulesInfo := make([]map[string]interface{}, len(rules))
-
-		for i, rule := range rules {
-			rulesInfo[i] = map[string]interface{}{
-				"id":          rule.ID(),
-				"name":        rule.Name(),
-				"description": rule.Description(),
-				"level":       rule.Level(),
-			}
+	policy := c.Query("policy")
+	configured, ok := s.policies.Rules(policy)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("unknown policy %q", policy)})
+		return
+	}
+	rules := advisor.DescribeRules(configured)
+	rulesInfo := make([]map[string]interface{}, len(rules))
+
+	for i, rule := range rules {
+		rulesInfo[i] = map[string]interface{}{
+			"id":          rule.Type,
+			"name":        rule.Title,
+			"description": rule.Description,
+			"level":       rule.Level,
+			"engine":      rule.Engine,
 		}
-
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"rules":   rulesInfo,
-		})
-	} else {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to list rules"})
 	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"rules":    rulesInfo,
+		"policy":   policy,
+		"policies": s.policies.Names(),
+	})
 }
 
 // generateID 生成随机ID
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/database"
+	"github.com/shenbo/sql-review-learning-demo/pkg/schemaload"
+)
+
+// defaultMetadataCacheTTL 元数据缓存的默认有效期
+const defaultMetadataCacheTTL = 5 * time.Minute
+
+// metadataLoader 从数据库加载元数据
+type metadataLoader func(db *sql.DB, config *database.ConnectionConfig) (*advisor.DatabaseMetadata, error)
+
+// metadataCache 按连接缓存数据库元数据，避免每次审查都查询 information_schema。
+// 同一连接同时未命中缓存的请求只查询一次数据库，加载失败的结果不缓存
+type metadataCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	load    metadataLoader
+	entries map[string]*metadataCacheEntry
+}
+
+type metadataCacheEntry struct {
+	// ready 在加载完成后关闭，之后 metadata、err 和 loadedAt 不再修改
+	ready    chan struct{}
+	metadata *advisor.DatabaseMetadata
+	err      error
+	loadedAt time.Time
+}
+
+// newMetadataCache 创建元数据缓存
+func newMetadataCache(ttl time.Duration) *metadataCache {
+	return &metadataCache{
+		ttl:     ttl,
+		load:    loadMetadata,
+		entries: make(map[string]*metadataCacheEntry),
+	}
+}
+
+// loadMetadata 通过 SchemaManager 加载数据库元数据
+func loadMetadata(db *sql.DB, config *database.ConnectionConfig) (*advisor.DatabaseMetadata, error) {
+	schemaManager := database.NewSchemaManager(db, config.Engine)
+	schema, err := schemaManager.GetSchemaInfo(config.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema metadata: %w", err)
+	}
+	return schemaload.FromSchemaInfo(schema), nil
+}
+
+// metadataCacheKey 返回连接对应数据库的缓存键
+func metadataCacheKey(connectionID string, config *database.ConnectionConfig) string {
+	return connectionID + "/" + config.Database
+}
+
+// Get 获取连接对应数据库的元数据，缓存过期或不存在时重新加载。
+// 其他请求正在加载同一数据库时等待其结果
+func (c *metadataCache) Get(connectionID string, db *sql.DB, config *database.ConnectionConfig) (*advisor.DatabaseMetadata, error) {
+	key := metadataCacheKey(connectionID, config)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok {
+		select {
+		case <-entry.ready:
+			// 已加载的元数据过期后重新加载
+			ok = time.Since(entry.loadedAt) < c.ttl
+		default:
+			// 正在加载
+		}
+	}
+	if ok {
+		c.mu.Unlock()
+		<-entry.ready
+		return entry.metadata, entry.err
+	}
+
+	entry = &metadataCacheEntry{ready: make(chan struct{})}
+	c.entries[key] = entry
+	c.mu.Unlock()
+
+	entry.metadata, entry.err = c.load(db, config)
+	entry.loadedAt = time.Now()
+	close(entry.ready)
+
+	if entry.err != nil {
+		c.mu.Lock()
+		if c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+	}
+	return entry.metadata, entry.err
+}
+
+// Put 用刚从数据库读取的 schema 更新缓存
+func (c *metadataCache) Put(connectionID string, config *database.ConnectionConfig, metadata *advisor.DatabaseMetadata) {
+	entry := &metadataCacheEntry{ready: make(chan struct{}), metadata: metadata, loadedAt: time.Now()}
+	close(entry.ready)
+
+	c.mu.Lock()
+	c.entries[metadataCacheKey(connectionID, config)] = entry
+	c.mu.Unlock()
+}
+
+// Invalidate 丢弃连接的缓存元数据，下次审查时重新加载。
+// 正在进行的加载不受影响，但其结果不会再被后续请求使用
+func (c *metadataCache) Invalidate(connectionID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, connectionID+"/") {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/database"
+)
+
+// TestMetadataCache 测试元数据缓存的并发加载、失效和加载失败
+func TestMetadataCache(t *testing.T) {
+	var loads atomic.Int32
+	release := make(chan struct{})
+	fail := false
+	cache := newMetadataCache(time.Minute)
+	cache.load = func(db *sql.DB, config *database.ConnectionConfig) (*advisor.DatabaseMetadata, error) {
+		loads.Add(1)
+		<-release
+		if fail {
+			return nil, errors.New("access denied")
+		}
+		return metadataWith(config.Database), nil
+	}
+	config := &database.ConnectionConfig{Database: "shop"}
+
+	// 并发未命中只加载一次
+	var wg sync.WaitGroup
+	results := make([]*advisor.DatabaseMetadata, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = cache.Get("c1", nil, config)
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := loads.Load(); n != 1 {
+		t.Errorf("Expected 1 load, got %d", n)
+	}
+	for _, metadata := range results {
+		if metadata == nil || metadata.Tables["shop"] == nil {
+			t.Fatalf("Expected the metadata of shop, got %v", metadata)
+		}
+	}
+
+	// 失效后重新加载，加载失败的结果不缓存
+	cache.Invalidate("c1")
+	fail = true
+	if _, err := cache.Get("c1", nil, config); err == nil {
+		t.Error("Expected the load error")
+	}
+	fail = false
+	if metadata, err := cache.Get("c1", nil, config); err != nil || metadata.Tables["shop"] == nil {
+		t.Errorf("Expected the metadata to be reloaded, got %v, %v", metadata, err)
+	}
+	if n := loads.Load(); n != 3 {
+		t.Errorf("Expected 3 loads, got %d", n)
+	}
+
+	// Put 更新缓存，不再查询数据库
+	cache.Put("c1", config, metadataWith("fresh"))
+	if metadata, _ := cache.Get("c1", nil, config); metadata.Tables["fresh"] == nil || loads.Load() != 3 {
+		t.Errorf("Expected the cached metadata, got %v", metadata)
+	}
+}
+
+// metadataWith 返回只有一个表的元数据，用表名区分不同次加载的结果
+func metadataWith(table string) *advisor.DatabaseMetadata {
+	return &advisor.DatabaseMetadata{Tables: map[string]*advisor.TableMetadata{table: {}}}
+}
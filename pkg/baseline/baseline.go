@@ -0,0 +1,220 @@
+// Package baseline records the advice a project has accepted, so that
+// later reviews only report new advice. Advice is identified by a
+// fingerprint that does not depend on its position, so that moving or
+// reformatting statements keeps it accepted.
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+// Version is the format version of baseline files.
+const Version = 1
+
+// Fingerprint identifies one piece of advice.
+type Fingerprint struct {
+	// Rule is the rule ID of the advice.
+	Rule string `json:"rule"`
+	// File is the slash-separated path of the reviewed file, relative to
+	// the directory of the baseline file.
+	File string `json:"file"`
+	// StatementHash is the hash of the normalized statement the advice is
+	// on, see mysqlparser.NormalizeStatement.
+	StatementHash string `json:"statement_hash"`
+	// Message is the content of the advice.
+	Message string `json:"message"`
+}
+
+// Baseline is the accepted advice. The same fingerprint is listed once for
+// each accepted advice.
+type Baseline struct {
+	Version      int           `json:"version"`
+	Fingerprints []Fingerprint `json:"fingerprints"`
+
+	// dir is the directory of the baseline file, which the file paths of
+	// the fingerprints are relative to.
+	dir string
+}
+
+// New returns an empty baseline to be saved at path.
+func New(path string) *Baseline {
+	return &Baseline{Version: Version, dir: filepath.Dir(path)}
+}
+
+// Load reads a baseline file written by Save.
+func Load(path string) (*Baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b := New(path)
+	if err := json.Unmarshal(content, b); err != nil {
+		return nil, fmt.Errorf("%s: invalid baseline: %w", path, err)
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("%s: unsupported baseline version %d", path, b.Version)
+	}
+	return b, nil
+}
+
+// Save writes the baseline with the fingerprints sorted, so that the file
+// diffs well under version control.
+func (b *Baseline) Save(path string) error {
+	sort.Slice(b.Fingerprints, func(i, j int) bool {
+		x, y := b.Fingerprints[i], b.Fingerprints[j]
+		if x.File != y.File {
+			return x.File < y.File
+		}
+		if x.Rule != y.Rule {
+			return x.Rule < y.Rule
+		}
+		if x.StatementHash != y.StatementHash {
+			return x.StatementHash < y.StatementHash
+		}
+		return x.Message < y.Message
+	})
+	if b.Fingerprints == nil {
+		b.Fingerprints = []Fingerprint{}
+	}
+
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
+
+// Add accepts the advice of a file. file is relative to the current
+// directory and statement is the content of the file. Rules that failed to
+// run are not accepted.
+func (b *Baseline) Add(file, statement string, adviceList []*advisor.Advice) error {
+	file, err := b.relativePath(file)
+	if err != nil {
+		return err
+	}
+
+	var accepted []*advisor.Advice
+	for _, advice := range adviceList {
+		if advice.Code != advisor.CodeInternalError {
+			accepted = append(accepted, advice)
+		}
+	}
+	fingerprints, err := Fingerprints(file, statement, accepted)
+	if err != nil {
+		return err
+	}
+	b.Fingerprints = append(b.Fingerprints, fingerprints...)
+	return nil
+}
+
+// Filter returns the advice of a file that the baseline does not accept,
+// in order. file is relative to the current directory, like in Add. Each
+// fingerprint of the baseline accepts one advice. Rules that failed to run
+// are always reported.
+func (b *Baseline) Filter(file, statement string, adviceList []*advisor.Advice) ([]*advisor.Advice, error) {
+	file, err := b.relativePath(file)
+	if err != nil {
+		return nil, err
+	}
+	fingerprints, err := Fingerprints(file, statement, adviceList)
+	if err != nil {
+		return nil, err
+	}
+
+	accepted := make(map[Fingerprint]int)
+	for _, fingerprint := range b.Fingerprints {
+		accepted[fingerprint]++
+	}
+
+	var result []*advisor.Advice
+	for i, advice := range adviceList {
+		if advice.Code != advisor.CodeInternalError && accepted[fingerprints[i]] > 0 {
+			accepted[fingerprints[i]]--
+			continue
+		}
+		result = append(result, advice)
+	}
+	return result, nil
+}
+
+// relativePath returns the path of file relative to the directory of the
+// baseline file, so that the baseline matches whichever directory the
+// review runs from.
+func (b *Baseline) relativePath(file string) (string, error) {
+	dir, err := filepath.Abs(b.dir)
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		// On another volume than the baseline file
+		return abs, nil
+	}
+	return rel, nil
+}
+
+// Fingerprints returns the fingerprint of each advice of a file, in order.
+// statement is the content of the file and file its path as stored in the
+// baseline.
+func Fingerprints(file, statement string, adviceList []*advisor.Advice) ([]Fingerprint, error) {
+	stmts, err := mysqlparser.SplitSQL(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	file = filepath.ToSlash(filepath.Clean(file))
+	hashes := make(map[*mysqlparser.SingleSQL]string)
+	fingerprints := make([]Fingerprint, 0, len(adviceList))
+	for _, advice := range adviceList {
+		var hash string
+		if stmt := statementOf(stmts, advice); stmt != nil {
+			if _, ok := hashes[stmt]; !ok {
+				sum := sha256.Sum256([]byte(mysqlparser.NormalizeStatement(stmt.Text)))
+				hashes[stmt] = hex.EncodeToString(sum[:8])
+			}
+			hash = hashes[stmt]
+		}
+		fingerprints = append(fingerprints, Fingerprint{
+			Rule:          advice.RuleID,
+			File:          file,
+			StatementHash: hash,
+			Message:       advice.Content,
+		})
+	}
+	return fingerprints, nil
+}
+
+// statementOf returns the statement an advice is on: the last statement
+// that starts at or before the advice, or the first statement for advice
+// before any statement, such as on a leading comment.
+func statementOf(stmts []*mysqlparser.SingleSQL, advice *advisor.Advice) *mysqlparser.SingleSQL {
+	if len(stmts) == 0 {
+		return nil
+	}
+	position := advice.StartPosition
+	if position == nil {
+		position = &advisor.Position{Line: advice.Line, Column: advice.Column}
+	}
+
+	found := stmts[0]
+	for _, stmt := range stmts {
+		if stmt.StartLine > position.Line || (stmt.StartLine == position.Line && stmt.StartColumn > position.Column) {
+			break
+		}
+		found = stmt
+	}
+	return found
+}
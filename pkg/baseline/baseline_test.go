@@ -0,0 +1,144 @@
+package baseline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/rules/mysql"
+)
+
+func review(t *testing.T, statement string) []*advisor.Advice {
+	t.Helper()
+
+	checkCtx := advisor.Context{
+		SQL:    statement,
+		Engine: advisor.MySQL,
+		Rules:  []string{string(advisor.MySQLTableRequirePK)},
+	}
+	advices, err := advisor.SQLReviewCheck(context.Background(), mysql.DefaultRules(), checkCtx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return advices
+}
+
+// TestBaselineFilter 测试基线只放过已接受的问题，且不受行号变化和重新格式化影响
+func TestBaselineFilter(t *testing.T) {
+	t.Chdir(t.TempDir())
+	original := "CREATE TABLE IF NOT EXISTS a (id INT);\nCREATE TABLE b (id INT);"
+	path := "baseline.json"
+	b := New(path)
+	if err := b.Add("migrations/001.sql", original, review(t, original)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := b.Save(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(loaded.Fingerprints) != 2 {
+		t.Fatalf("Expected 2 fingerprints, got %+v", loaded.Fingerprints)
+	}
+
+	tests := []struct {
+		name      string
+		file      string
+		statement string
+		expected  []int
+	}{
+		{
+			name:      "Unchanged",
+			file:      "migrations/001.sql",
+			statement: original,
+		},
+		{
+			name:      "Shifted and reformatted",
+			file:      "./migrations/001.sql",
+			statement: "-- header\n\ncreate table b (\n  id INT\n);\nCREATE TABLE c (id INT);\nCREATE   TABLE if not exists a (id INT); -- note",
+			expected:  []int{6},
+		},
+		{
+			name:      "Repeated statement",
+			file:      "migrations/001.sql",
+			statement: original + "\nCREATE TABLE IF NOT EXISTS a (id INT);",
+			expected:  []int{3},
+		},
+		{
+			name:      "Other file",
+			file:      "migrations/002.sql",
+			statement: original,
+			expected:  []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			advices, err := loaded.Filter(tt.file, tt.statement, review(t, tt.statement))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var lines []int
+			for _, advice := range advices {
+				lines = append(lines, advice.StartPosition.Line)
+			}
+			if len(lines) != len(tt.expected) {
+				t.Fatalf("Expected advice on lines %v, got %v", tt.expected, lines)
+			}
+			for i := range lines {
+				if lines[i] != tt.expected[i] {
+					t.Errorf("Expected advice on lines %v, got %v", tt.expected, lines)
+				}
+			}
+		})
+	}
+}
+
+// TestBaselinePaths 测试基线中的文件路径相对于基线文件所在目录，从其他目录检查时仍然匹配
+func TestBaselinePaths(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+	statement := "CREATE TABLE a (id INT);"
+	b := New(filepath.Join("ci", "baseline.json"))
+	if err := b.Add(filepath.Join("migrations", "001.sql"), statement, review(t, statement)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := os.Mkdir("ci", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Save(filepath.Join("ci", "baseline.json")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if file := b.Fingerprints[0].File; file != "../migrations/001.sql" {
+		t.Errorf("Expected the path relative to the baseline file, got %s", file)
+	}
+
+	// 在 migrations 目录中检查同一文件
+	if err := os.Mkdir("migrations", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(filepath.Join(root, "migrations"))
+	loaded, err := Load(filepath.Join("..", "ci", "baseline.json"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	advices, err := loaded.Filter("001.sql", statement, review(t, statement))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(advices) != 0 {
+		t.Errorf("Expected the advice to be accepted, got %v", advices)
+	}
+	advices, err = loaded.Filter(filepath.Join("..", "002.sql"), statement, review(t, statement))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(advices) != 1 {
+		t.Errorf("Expected the advice of another file, got %v", advices)
+	}
+}
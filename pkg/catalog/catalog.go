@@ -0,0 +1,240 @@
+// Package catalog keeps the schema of a database while a migration script is
+// walked through statement by statement, so that rules see the schema as it
+// is at each statement rather than as it was before the script.
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Catalog is the schema of one database. It is seeded with the schema of
+// the database, or starts empty for offline review, and WalkThrough applies
+// the DDL statements of the script to it in order.
+type Catalog struct {
+	databaseName string
+	// complete is set when the catalog was seeded with the full schema of
+	// the database: a table of the database that the catalog does not hold
+	// does not exist. An empty catalog only knows the tables the script
+	// creates or touches.
+	complete bool
+	// tables maps table keys to the tables that exist.
+	tables map[string]*Table
+	// dropped holds the keys of the tables the script dropped or renamed.
+	dropped map[string]bool
+}
+
+// Table is a table of the catalog.
+type Table struct {
+	Name    string
+	Columns []*Column
+	Indexes []*Index
+	// RowCount is the estimated number of rows, 0 when the table is empty
+	// or the count is unknown.
+	RowCount int64
+	// Partial is set when the columns of the table are not all known, for
+	// tables the script creates with CREATE TABLE ... AS SELECT and tables
+	// of an empty catalog that the script alters. Missing columns and
+	// indexes are not reported on partial tables.
+	Partial bool
+}
+
+// Column is a column of a table.
+type Column struct {
+	Name string
+	// Type is the lowercase column type, e.g. "varchar(50)".
+	Type          string
+	Nullable      bool
+	HasDefault    bool
+	AutoIncrement bool
+}
+
+// Index is an index or key of a table.
+type Index struct {
+	// Name is "PRIMARY" for the primary key. Unnamed indexes are named
+	// after their first column like MySQL does.
+	Name    string
+	Columns []string
+	Primary bool
+	Unique  bool
+	// Fulltext is set for FULLTEXT and SPATIAL indexes.
+	Fulltext bool
+}
+
+// New returns an empty catalog of a database. Tables that the script does
+// not create are unknown to it, and no error is reported for them.
+func New(databaseName string) *Catalog {
+	return &Catalog{
+		databaseName: databaseName,
+		tables:       make(map[string]*Table),
+		dropped:      make(map[string]bool),
+	}
+}
+
+// NewWithTables returns a catalog holding the full schema of a database.
+// The catalog takes ownership of tables.
+func NewWithTables(databaseName string, tables []*Table) *Catalog {
+	c := New(databaseName)
+	c.complete = true
+	for _, table := range tables {
+		c.tables[c.key("", table.Name)] = table
+	}
+	return c
+}
+
+// Clone returns a copy of the catalog that shares no state with it, so
+// that walking statements through one leaves the other unchanged.
+func (c *Catalog) Clone() *Catalog {
+	cloned := New(c.databaseName)
+	cloned.complete = c.complete
+	for key, table := range c.tables {
+		copied := table.clone(table.Name)
+		copied.RowCount = table.RowCount
+		cloned.tables[key] = copied
+	}
+	for key := range c.dropped {
+		cloned.dropped[key] = true
+	}
+	return cloned
+}
+
+// Table returns a table as of the statement being walked, or nil when the
+// table does not exist or is unknown. schema is empty for unqualified
+// names.
+func (c *Catalog) Table(schema, name string) *Table {
+	return c.tables[c.key(schema, name)]
+}
+
+// Tables returns the tables of the catalog database as of the statement
+// being walked, sorted by name.
+func (c *Catalog) Tables() []*Table {
+	var tables []*Table
+	for key, table := range c.tables {
+		if !strings.Contains(key, ".") {
+			tables = append(tables, table)
+		}
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+	return tables
+}
+
+// TableExists reports whether a table exists as of the statement being
+// walked. known is false when the catalog cannot tell, for tables of an
+// empty catalog that the script has not touched and tables of other
+// databases.
+func (c *Catalog) TableExists(schema, name string) (exists bool, known bool) {
+	key := c.key(schema, name)
+	if _, ok := c.tables[key]; ok {
+		return true, true
+	}
+	if c.dropped[key] {
+		return false, true
+	}
+	if c.complete && !strings.Contains(key, ".") {
+		return false, true
+	}
+	return false, false
+}
+
+// key returns the map key of a table. Tables of the catalog database are
+// keyed by their lowercase name, tables of other databases by their
+// lowercase qualified name.
+func (c *Catalog) key(schema, name string) string {
+	if schema == "" || strings.EqualFold(schema, c.databaseName) {
+		return strings.ToLower(name)
+	}
+	return strings.ToLower(schema) + "." + strings.ToLower(name)
+}
+
+func (c *Catalog) putTable(key string, table *Table) {
+	c.tables[key] = table
+	delete(c.dropped, key)
+}
+
+func (c *Catalog) dropTable(key string) {
+	delete(c.tables, key)
+	c.dropped[key] = true
+}
+
+// Column returns a column by name, case-insensitively, or nil.
+func (t *Table) Column(name string) *Column {
+	for _, column := range t.Columns {
+		if strings.EqualFold(column.Name, name) {
+			return column
+		}
+	}
+	return nil
+}
+
+// Index returns an index by name, case-insensitively, or nil.
+func (t *Table) Index(name string) *Index {
+	for _, index := range t.Indexes {
+		if strings.EqualFold(index.Name, name) {
+			return index
+		}
+	}
+	return nil
+}
+
+// PrimaryKey returns the primary key of the table, or nil.
+func (t *Table) PrimaryKey() *Index {
+	for _, index := range t.Indexes {
+		if index.Primary {
+			return index
+		}
+	}
+	return nil
+}
+
+// IndexesOf returns the indexes that contain a column.
+func (t *Table) IndexesOf(column string) []*Index {
+	var indexes []*Index
+	for _, index := range t.Indexes {
+		if index.HasColumn(column) {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
+// HasColumn reports whether the index contains a column.
+func (i *Index) HasColumn(column string) bool {
+	for _, name := range i.Columns {
+		if strings.EqualFold(name, column) {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *Index) String() string {
+	if i.Primary {
+		return "PRIMARY KEY"
+	}
+	return fmt.Sprintf("`%s`", i.Name)
+}
+
+func (t *Table) clone(name string) *Table {
+	cloned := &Table{Name: name, Partial: t.Partial}
+	for _, column := range t.Columns {
+		copied := *column
+		cloned.Columns = append(cloned.Columns, &copied)
+	}
+	for _, index := range t.Indexes {
+		copied := *index
+		copied.Columns = append([]string(nil), index.Columns...)
+		cloned.Indexes = append(cloned.Indexes, &copied)
+	}
+	return cloned
+}
+
+// uniqueIndexName returns the name MySQL gives an unnamed index: its first
+// column, with a numeric suffix when the name is taken.
+func (t *Table) uniqueIndexName(column string) string {
+	name := column
+	for i := 2; t.Index(name) != nil; i++ {
+		name = fmt.Sprintf("%s_%d", column, i)
+	}
+	return name
+}
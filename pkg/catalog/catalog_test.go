@@ -0,0 +1,172 @@
+package catalog
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+// walkThrough 依次将脚本中的语句应用到 catalog，返回 "行:列 错误类型" 形式的错误列表
+func walkThrough(t *testing.T, c *Catalog, statement string) []string {
+	t.Helper()
+
+	stmts, err := mysqlparser.ParseMySQL(statement)
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", statement, err)
+	}
+	var results []string
+	for _, stmt := range stmts {
+		for _, walkThroughErr := range c.WalkThrough(stmt) {
+			results = append(results, fmt.Sprintf("%d:%d %d", walkThroughErr.Line, walkThroughErr.Column, walkThroughErr.Type))
+		}
+	}
+	return results
+}
+
+func seededCatalog() *Catalog {
+	return NewWithTables("app", []*Table{
+		{
+			Name: "users",
+			Columns: []*Column{
+				{Name: "id", Type: "bigint"},
+				{Name: "email", Type: "varchar(255)", Nullable: true},
+			},
+			Indexes: []*Index{
+				{Name: "PRIMARY", Columns: []string{"id"}, Primary: true, Unique: true},
+				{Name: "uk_email", Columns: []string{"email"}, Unique: true},
+			},
+			RowCount: 10,
+		},
+	})
+}
+
+// TestWalkThrough 测试逐条语句应用 DDL 时发现的错误
+func TestWalkThrough(t *testing.T) {
+	tests := []struct {
+		name      string
+		catalog   *Catalog
+		statement string
+		expected  []string
+	}{
+		{
+			name:      "Create then alter",
+			catalog:   New(""),
+			statement: "CREATE TABLE t (id INT PRIMARY KEY, a INT);\nALTER TABLE t ADD COLUMN b INT, ADD INDEX idx_b (b);\nCREATE INDEX idx_ab ON t (a, b);\nALTER TABLE t DROP INDEX idx_b, RENAME COLUMN a TO c, MODIFY c BIGINT;",
+		},
+		{
+			name:      "Column already exists",
+			catalog:   New(""),
+			statement: "CREATE TABLE t (id INT, id INT);\nALTER TABLE t ADD COLUMN a INT;\nALTER TABLE t ADD COLUMN a INT, CHANGE id a INT;",
+			expected:  []string{"1:25 3", "3:15 3", "3:33 3"},
+		},
+		{
+			name:      "Column not found",
+			catalog:   New(""),
+			statement: "CREATE TABLE t (id INT);\nALTER TABLE t DROP COLUMN a, MODIFY b INT, ALTER COLUMN c SET DEFAULT 0;",
+			expected:  []string{"2:15 4", "2:30 4", "2:44 4"},
+		},
+		{
+			name:      "Index on unknown column",
+			catalog:   New(""),
+			statement: "CREATE TABLE t (id INT, KEY idx_a (a));\nCREATE INDEX idx_b ON t (b);\nALTER TABLE t ADD PRIMARY KEY (id), ADD PRIMARY KEY (id);",
+			expected:  []string{"1:25 7", "2:1 7", "3:37 8"},
+		},
+		{
+			name:      "Index names",
+			catalog:   New(""),
+			statement: "CREATE TABLE t (a INT, b INT, KEY (a), UNIQUE KEY (a));\nCREATE INDEX a_2 ON t (b);\nALTER TABLE t DROP INDEX a, DROP INDEX a_3, RENAME INDEX a_2 TO idx_b;\nDROP INDEX idx_b ON t;",
+			expected:  []string{"2:1 5", "3:29 6"},
+		},
+		{
+			name:      "Dropped column leaves its indexes",
+			catalog:   New(""),
+			statement: "CREATE TABLE t (a INT, b INT, KEY idx_a (a), KEY idx_ab (a, b));\nALTER TABLE t DROP COLUMN a;\nALTER TABLE t DROP INDEX idx_a;\nALTER TABLE t DROP INDEX idx_ab;",
+			expected:  []string{"3:15 6"},
+		},
+		{
+			name:      "Tables of an empty catalog",
+			catalog:   New(""),
+			statement: "ALTER TABLE users ADD COLUMN a INT, DROP COLUMN b, DROP INDEX idx_c;\nALTER TABLE users ADD COLUMN a INT;\nCREATE TABLE users (id INT);\nDROP TABLE orders;\nALTER TABLE orders ADD COLUMN a INT;\nDROP TABLE IF EXISTS orders;",
+			expected:  []string{"2:19 3", "3:1 2", "5:1 1"},
+		},
+		{
+			name:      "Seeded catalog",
+			catalog:   seededCatalog(),
+			statement: "ALTER TABLE orders ADD COLUMN a INT;\nCREATE TABLE users (id INT);\nCREATE TABLE IF NOT EXISTS users (id INT);\nALTER TABLE users ADD COLUMN email TEXT, DROP COLUMN name;\nALTER TABLE other.orders ADD COLUMN a INT;\nALTER TABLE app.users DROP INDEX uk_email;",
+			expected:  []string{"1:1 1", "2:1 2", "4:19 3", "4:42 4"},
+		},
+		{
+			name:      "Rename and LIKE",
+			catalog:   seededCatalog(),
+			statement: "RENAME TABLE users TO members;\nALTER TABLE users ADD COLUMN a INT;\nCREATE TABLE users LIKE members;\nALTER TABLE users DROP INDEX uk_email;\nCREATE TABLE t LIKE missing;\nALTER TABLE members RENAME TO users;",
+			expected:  []string{"2:1 1", "5:1 1", "6:21 2"},
+		},
+		{
+			name:      "CREATE TABLE ... AS SELECT",
+			catalog:   New(""),
+			statement: "CREATE TABLE t AS SELECT * FROM users;\nALTER TABLE t DROP COLUMN a, ADD INDEX idx_b (b);",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := walkThrough(t, tt.catalog, tt.statement)
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
+
+// TestWalkThroughState 测试 catalog 在语句之间的状态演进
+func TestWalkThroughState(t *testing.T) {
+	c := seededCatalog()
+	walkThrough(t, c, "ALTER TABLE users ADD COLUMN name VARCHAR(50) NOT NULL DEFAULT '', ADD INDEX idx_name (name);\nALTER TABLE users CHANGE email mail VARCHAR(100);\nCREATE TABLE orders (id BIGINT AUTO_INCREMENT PRIMARY KEY, user_id BIGINT);\nDROP TABLE orders;")
+
+	users := c.Table("", "USERS")
+	if users == nil {
+		t.Fatal("Expected table users")
+	}
+	name := users.Column("name")
+	if name == nil || name.Type != "varchar(50)" || name.Nullable || !name.HasDefault {
+		t.Errorf("Unexpected column name: %+v", name)
+	}
+	if users.Column("email") != nil || users.Column("mail") == nil {
+		t.Error("Expected email to be renamed to mail")
+	}
+	if index := users.Index("uk_email"); index == nil || !reflect.DeepEqual(index.Columns, []string{"mail"}) {
+		t.Errorf("Expected uk_email on mail, got %+v", index)
+	}
+	if index := users.Index("idx_name"); index == nil || !reflect.DeepEqual(index.Columns, []string{"name"}) {
+		t.Errorf("Expected idx_name on name, got %+v", index)
+	}
+	if exists, known := c.TableExists("", "orders"); exists || !known {
+		t.Errorf("Expected orders to be dropped, got exists=%v known=%v", exists, known)
+	}
+}
+
+// TestClone 测试复制的 catalog 与原 catalog 互不影响
+func TestClone(t *testing.T) {
+	c := seededCatalog()
+	walkThrough(t, c, "DROP TABLE IF EXISTS legacy;")
+	cloned := c.Clone()
+	walkThrough(t, cloned, "ALTER TABLE users ADD COLUMN name VARCHAR(50), DROP INDEX uk_email;\nCREATE TABLE orders (id BIGINT PRIMARY KEY);")
+
+	users := c.Table("", "users")
+	if users.Column("name") != nil || users.Index("uk_email") == nil {
+		t.Errorf("Expected the original users table unchanged, got %+v", users)
+	}
+	if exists, _ := c.TableExists("", "orders"); exists {
+		t.Error("Expected orders not to exist in the original catalog")
+	}
+
+	clonedUsers := cloned.Table("", "users")
+	if clonedUsers.Column("name") == nil || clonedUsers.Index("uk_email") != nil || clonedUsers.RowCount != 10 {
+		t.Errorf("Unexpected cloned users table: %+v", clonedUsers)
+	}
+	if exists, known := cloned.TableExists("", "legacy"); exists || !known {
+		t.Errorf("Expected legacy to stay dropped in the clone, got exists=%v known=%v", exists, known)
+	}
+}
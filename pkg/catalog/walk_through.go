@@ -0,0 +1,541 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+	mysql "github.com/bytebase/parser/mysql"
+
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+// ErrorType is the type of a walk-through error.
+type ErrorType int
+
+const (
+	// ErrorTableNotExists is a statement on a table that does not exist.
+	ErrorTableNotExists ErrorType = iota + 1
+	// ErrorTableExists is CREATE TABLE or RENAME TABLE to a table that
+	// already exists.
+	ErrorTableExists
+	// ErrorColumnExists is adding a column that already exists.
+	ErrorColumnExists
+	// ErrorColumnNotExists is changing or dropping a column that does not
+	// exist.
+	ErrorColumnNotExists
+	// ErrorIndexExists is adding an index whose name is taken.
+	ErrorIndexExists
+	// ErrorIndexNotExists is changing or dropping an index that does not
+	// exist.
+	ErrorIndexNotExists
+	// ErrorIndexColumnNotExists is an index on a column that does not exist.
+	ErrorIndexColumnNotExists
+	// ErrorPrimaryKeyExists is adding a second primary key.
+	ErrorPrimaryKeyExists
+)
+
+// WalkThroughError is a statement that would fail against the catalog, such
+// as adding a column that already exists.
+type WalkThroughError struct {
+	Type    ErrorType
+	Content string
+	// Line and Column are the 1-based position in the script.
+	Line   int
+	Column int
+}
+
+// Error implements the error interface.
+func (e *WalkThroughError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Content)
+}
+
+// WalkThrough applies a statement to the catalog and returns the errors the
+// statement would hit. The valid parts of a failing statement are still
+// applied, so that one mistake does not cascade into the statements after
+// it. Statements other than table and index DDL leave the catalog as is.
+func (c *Catalog) WalkThrough(stmt *mysqlparser.ParseResult) []*WalkThroughError {
+	w := &walker{BaseMySQLParserListener: &mysql.BaseMySQLParserListener{}, catalog: c, baseLine: stmt.BaseLine}
+	if root, ok := stmt.Tree.(antlr.ParserRuleContext); ok {
+		w.start = root.GetStart()
+	}
+	antlr.ParseTreeWalkerDefault.Walk(w, stmt.Tree)
+	return w.errs
+}
+
+// walker applies the DDL of one statement.
+type walker struct {
+	*mysql.BaseMySQLParserListener
+
+	catalog  *Catalog
+	baseLine int
+	// start is the first token of the statement, where errors on the
+	// statement as a whole are reported.
+	start antlr.Token
+	// table and tableKey are the table of the ALTER TABLE being walked.
+	// table is nil when the table does not exist.
+	table    *Table
+	tableKey string
+	errs     []*WalkThroughError
+}
+
+// EnterCreateTable implements mysql.MySQLParserListener.
+func (w *walker) EnterCreateTable(ctx *mysql.CreateTableContext) {
+	schema, name := mysqlparser.NormalizeMySQLTableName(ctx.TableName())
+	if exists, _ := w.catalog.TableExists(schema, name); exists {
+		if ctx.IfNotExists() == nil {
+			w.addError(ErrorTableExists, fmt.Sprintf("Table `%s` already exists", name), w.start)
+		}
+		return
+	}
+	key := w.catalog.key(schema, name)
+
+	// CREATE TABLE t LIKE s
+	if ctx.TableRef() != nil {
+		sourceSchema, source := mysqlparser.NormalizeMySQLTableRef(ctx.TableRef())
+		if table := w.catalog.Table(sourceSchema, source); table != nil {
+			w.catalog.putTable(key, table.clone(name))
+			return
+		}
+		if _, known := w.catalog.TableExists(sourceSchema, source); known {
+			w.addError(ErrorTableNotExists, fmt.Sprintf("Table `%s` does not exist", source), w.start)
+			return
+		}
+		w.catalog.putTable(key, &Table{Name: name, Partial: true})
+		return
+	}
+
+	table := &Table{Name: name, Partial: ctx.DuplicateAsQueryExpression() != nil}
+	if ctx.TableElementList() != nil {
+		// Columns first, so keys can use columns declared later.
+		elements := ctx.TableElementList().AllTableElement()
+		for _, element := range elements {
+			if column := element.ColumnDefinition(); column != nil {
+				w.addColumn(table, mysqlparser.NormalizeMySQLColumnName(column.ColumnName()), column.FieldDefinition(), column.GetStart())
+			}
+		}
+		for _, element := range elements {
+			if constraint := element.TableConstraintDef(); constraint != nil {
+				w.addConstraint(table, constraint, constraint.GetStart())
+			}
+		}
+	}
+	w.catalog.putTable(key, table)
+}
+
+// EnterAlterTable implements mysql.MySQLParserListener.
+func (w *walker) EnterAlterTable(ctx *mysql.AlterTableContext) {
+	schema, name := mysqlparser.NormalizeMySQLTableRef(ctx.TableRef())
+	w.table, w.tableKey = w.lookupTable(schema, name), w.catalog.key(schema, name)
+}
+
+// ExitAlterTable implements mysql.MySQLParserListener.
+func (w *walker) ExitAlterTable(*mysql.AlterTableContext) {
+	w.table, w.tableKey = nil, ""
+}
+
+// EnterAlterListItem implements mysql.MySQLParserListener.
+func (w *walker) EnterAlterListItem(ctx *mysql.AlterListItemContext) {
+	table := w.table
+	if table == nil {
+		return
+	}
+	token := ctx.GetStart()
+
+	switch {
+	// ADD COLUMN c INT
+	case ctx.ADD_SYMBOL() != nil && ctx.Identifier() != nil && ctx.FieldDefinition() != nil:
+		w.addColumn(table, mysqlparser.NormalizeMySQLIdentifier(ctx.Identifier()), ctx.FieldDefinition(), token)
+	// ADD COLUMN (c INT, ...)
+	case ctx.ADD_SYMBOL() != nil && ctx.TableElementList() != nil:
+		for _, element := range ctx.TableElementList().AllTableElement() {
+			if column := element.ColumnDefinition(); column != nil {
+				w.addColumn(table, mysqlparser.NormalizeMySQLColumnName(column.ColumnName()), column.FieldDefinition(), column.GetStart())
+			}
+			if constraint := element.TableConstraintDef(); constraint != nil {
+				w.addConstraint(table, constraint, constraint.GetStart())
+			}
+		}
+	// ADD INDEX, ADD PRIMARY KEY ...
+	case ctx.ADD_SYMBOL() != nil && ctx.TableConstraintDef() != nil:
+		w.addConstraint(table, ctx.TableConstraintDef(), token)
+	// CHANGE COLUMN a b INT
+	case ctx.CHANGE_SYMBOL() != nil && ctx.ColumnInternalRef() != nil:
+		w.changeColumn(table, mysqlparser.NormalizeMySQLIdentifier(ctx.ColumnInternalRef().Identifier()), mysqlparser.NormalizeMySQLIdentifier(ctx.Identifier()), ctx.FieldDefinition(), token)
+	// MODIFY COLUMN c INT
+	case ctx.MODIFY_SYMBOL() != nil && ctx.ColumnInternalRef() != nil:
+		name := mysqlparser.NormalizeMySQLIdentifier(ctx.ColumnInternalRef().Identifier())
+		w.changeColumn(table, name, name, ctx.FieldDefinition(), token)
+	// ALTER COLUMN c SET DEFAULT 0, ALTER COLUMN c DROP DEFAULT
+	case ctx.ALTER_SYMBOL() != nil && ctx.ColumnInternalRef() != nil:
+		column := w.column(table, mysqlparser.NormalizeMySQLIdentifier(ctx.ColumnInternalRef().Identifier()), token)
+		if column != nil && ctx.DEFAULT_SYMBOL() != nil {
+			column.HasDefault = ctx.SET_SYMBOL() != nil
+		}
+	// ALTER INDEX i INVISIBLE
+	case ctx.ALTER_SYMBOL() != nil && ctx.INDEX_SYMBOL() != nil && ctx.IndexRef() != nil:
+		w.index(table, mysqlparser.NormalizeMySQLFieldIdentifier(ctx.IndexRef().FieldIdentifier()), token)
+	// DROP COLUMN c
+	case ctx.DROP_SYMBOL() != nil && ctx.FOREIGN_SYMBOL() == nil && ctx.ColumnInternalRef() != nil:
+		w.dropColumn(table, mysqlparser.NormalizeMySQLIdentifier(ctx.ColumnInternalRef().Identifier()), token)
+	// DROP PRIMARY KEY
+	case ctx.DROP_SYMBOL() != nil && ctx.PRIMARY_SYMBOL() != nil:
+		if primaryKey := table.PrimaryKey(); primaryKey != nil {
+			table.removeIndex(primaryKey)
+		} else if !table.Partial {
+			w.addError(ErrorIndexNotExists, fmt.Sprintf("Table `%s` has no primary key", table.Name), token)
+		}
+	// DROP INDEX i
+	case ctx.DROP_SYMBOL() != nil && ctx.KeyOrIndex() != nil && ctx.IndexRef() != nil:
+		if index := w.index(table, mysqlparser.NormalizeMySQLFieldIdentifier(ctx.IndexRef().FieldIdentifier()), token); index != nil {
+			table.removeIndex(index)
+		}
+	// RENAME COLUMN a TO b
+	case ctx.RENAME_SYMBOL() != nil && ctx.ColumnInternalRef() != nil:
+		w.renameColumn(table, mysqlparser.NormalizeMySQLIdentifier(ctx.ColumnInternalRef().Identifier()), mysqlparser.NormalizeMySQLIdentifier(ctx.Identifier()), token)
+	// RENAME INDEX a TO b
+	case ctx.RENAME_SYMBOL() != nil && ctx.IndexRef() != nil && ctx.IndexName() != nil:
+		w.renameIndex(table, mysqlparser.NormalizeMySQLFieldIdentifier(ctx.IndexRef().FieldIdentifier()), mysqlparser.NormalizeMySQLIdentifier(ctx.IndexName().Identifier()), token)
+	// RENAME TO t2
+	case ctx.RENAME_SYMBOL() != nil && ctx.TableName() != nil:
+		schema, name := mysqlparser.NormalizeMySQLTableName(ctx.TableName())
+		if exists, _ := w.catalog.TableExists(schema, name); exists {
+			w.addError(ErrorTableExists, fmt.Sprintf("Table `%s` already exists", name), token)
+			return
+		}
+		w.catalog.dropTable(w.tableKey)
+		table.Name = name
+		w.tableKey = w.catalog.key(schema, name)
+		w.catalog.putTable(w.tableKey, table)
+	}
+}
+
+// EnterCreateIndex implements mysql.MySQLParserListener.
+func (w *walker) EnterCreateIndex(ctx *mysql.CreateIndexContext) {
+	target := ctx.CreateIndexTarget()
+	if target == nil || target.TableRef() == nil {
+		return
+	}
+	schema, name := mysqlparser.NormalizeMySQLTableRef(target.TableRef())
+	table := w.lookupTable(schema, name)
+	if table == nil {
+		return
+	}
+
+	index := &Index{
+		Columns:  mysqlparser.NormalizeMySQLKeyListVariants(target.KeyListVariants()),
+		Unique:   ctx.UNIQUE_SYMBOL() != nil,
+		Fulltext: ctx.GetType_() != nil && ctx.GetType_().GetTokenType() != mysql.MySQLParserINDEX_SYMBOL,
+	}
+	switch {
+	case ctx.IndexName() != nil:
+		index.Name = mysqlparser.NormalizeMySQLIdentifier(ctx.IndexName().Identifier())
+	case ctx.IndexNameAndType() != nil:
+		index.Name = mysqlparser.NormalizeMySQLIdentifier(ctx.IndexNameAndType().IndexName().Identifier())
+	}
+	w.addIndex(table, index, w.start)
+}
+
+// EnterDropIndex implements mysql.MySQLParserListener.
+func (w *walker) EnterDropIndex(ctx *mysql.DropIndexContext) {
+	if ctx.TableRef() == nil || ctx.IndexRef() == nil {
+		return
+	}
+	schema, name := mysqlparser.NormalizeMySQLTableRef(ctx.TableRef())
+	table := w.lookupTable(schema, name)
+	if table == nil {
+		return
+	}
+	if index := w.index(table, mysqlparser.NormalizeMySQLFieldIdentifier(ctx.IndexRef().FieldIdentifier()), w.start); index != nil {
+		table.removeIndex(index)
+	}
+}
+
+// EnterDropTable implements mysql.MySQLParserListener.
+func (w *walker) EnterDropTable(ctx *mysql.DropTableContext) {
+	if ctx.TableRefList() == nil {
+		return
+	}
+	for _, ref := range ctx.TableRefList().AllTableRef() {
+		schema, name := mysqlparser.NormalizeMySQLTableRef(ref)
+		if exists, known := w.catalog.TableExists(schema, name); !exists && known && ctx.IfExists() == nil {
+			w.addError(ErrorTableNotExists, fmt.Sprintf("Table `%s` does not exist", name), w.start)
+		}
+		w.catalog.dropTable(w.catalog.key(schema, name))
+	}
+}
+
+// EnterRenamePair implements mysql.MySQLParserListener.
+func (w *walker) EnterRenamePair(ctx *mysql.RenamePairContext) {
+	fromSchema, from := mysqlparser.NormalizeMySQLTableRef(ctx.TableRef())
+	toSchema, to := mysqlparser.NormalizeMySQLTableName(ctx.TableName())
+	table := w.lookupTable(fromSchema, from)
+	if table == nil {
+		return
+	}
+	if exists, _ := w.catalog.TableExists(toSchema, to); exists {
+		w.addError(ErrorTableExists, fmt.Sprintf("Table `%s` already exists", to), w.start)
+		return
+	}
+	w.catalog.dropTable(w.catalog.key(fromSchema, from))
+	table.Name = to
+	w.catalog.putTable(w.catalog.key(toSchema, to), table)
+}
+
+// lookupTable returns a table the statement changes. A table the catalog
+// does not know is added as a partial table, since the statement tells it
+// exists. It reports an error and returns nil when the table does not
+// exist.
+func (w *walker) lookupTable(schema, name string) *Table {
+	if table := w.catalog.Table(schema, name); table != nil {
+		return table
+	}
+	if _, known := w.catalog.TableExists(schema, name); known {
+		w.addError(ErrorTableNotExists, fmt.Sprintf("Table `%s` does not exist", name), w.start)
+		return nil
+	}
+	table := &Table{Name: name, Partial: true}
+	w.catalog.putTable(w.catalog.key(schema, name), table)
+	return table
+}
+
+// column returns a column the statement changes. It reports an error when
+// the column does not exist; on partial tables it returns nil silently.
+func (w *walker) column(table *Table, name string, token antlr.Token) *Column {
+	column := table.Column(name)
+	if column == nil && !table.Partial {
+		w.addError(ErrorColumnNotExists, fmt.Sprintf("Column `%s`.`%s` does not exist", table.Name, name), token)
+	}
+	return column
+}
+
+// index returns an index the statement changes, like column does.
+func (w *walker) index(table *Table, name string, token antlr.Token) *Index {
+	index := table.Index(name)
+	if index == nil && !table.Partial {
+		w.addError(ErrorIndexNotExists, fmt.Sprintf("Index `%s` does not exist on table `%s`", name, table.Name), token)
+	}
+	return index
+}
+
+func (w *walker) addColumn(table *Table, name string, definition mysql.IFieldDefinitionContext, token antlr.Token) {
+	if table.Column(name) != nil {
+		w.addError(ErrorColumnExists, fmt.Sprintf("Column `%s`.`%s` already exists", table.Name, name), token)
+		return
+	}
+	column, keys := newColumn(name, definition)
+	table.Columns = append(table.Columns, column)
+	for _, key := range keys {
+		w.addIndex(table, key, token)
+	}
+}
+
+// changeColumn replaces the definition of a column, renaming it from
+// oldName to newName.
+func (w *walker) changeColumn(table *Table, oldName, newName string, definition mysql.IFieldDefinitionContext, token antlr.Token) {
+	column := w.column(table, oldName, token)
+	if column == nil && !table.Partial {
+		return
+	}
+	if !strings.EqualFold(oldName, newName) && table.Column(newName) != nil {
+		w.addError(ErrorColumnExists, fmt.Sprintf("Column `%s`.`%s` already exists", table.Name, newName), token)
+		return
+	}
+	replacement, keys := newColumn(newName, definition)
+	if column == nil {
+		table.Columns = append(table.Columns, replacement)
+	} else {
+		table.renameIndexColumn(column.Name, newName)
+		*column = *replacement
+	}
+	for _, key := range keys {
+		w.addIndex(table, key, token)
+	}
+}
+
+func (w *walker) renameColumn(table *Table, oldName, newName string, token antlr.Token) {
+	column := w.column(table, oldName, token)
+	if column == nil {
+		return
+	}
+	if !strings.EqualFold(oldName, newName) && table.Column(newName) != nil {
+		w.addError(ErrorColumnExists, fmt.Sprintf("Column `%s`.`%s` already exists", table.Name, newName), token)
+		return
+	}
+	table.renameIndexColumn(column.Name, newName)
+	column.Name = newName
+}
+
+// dropColumn drops a column and removes it from the indexes. Indexes left
+// without columns are dropped, like MySQL does.
+func (w *walker) dropColumn(table *Table, name string, token antlr.Token) {
+	column := w.column(table, name, token)
+	if column == nil {
+		return
+	}
+	for i, existing := range table.Columns {
+		if existing == column {
+			table.Columns = append(table.Columns[:i], table.Columns[i+1:]...)
+			break
+		}
+	}
+	var indexes []*Index
+	for _, index := range table.Indexes {
+		var columns []string
+		for _, indexColumn := range index.Columns {
+			if !strings.EqualFold(indexColumn, name) {
+				columns = append(columns, indexColumn)
+			}
+		}
+		index.Columns = columns
+		if len(columns) > 0 {
+			indexes = append(indexes, index)
+		}
+	}
+	table.Indexes = indexes
+}
+
+func (w *walker) renameIndex(table *Table, oldName, newName string, token antlr.Token) {
+	index := w.index(table, oldName, token)
+	if index == nil {
+		return
+	}
+	if !strings.EqualFold(oldName, newName) && table.Index(newName) != nil {
+		w.addError(ErrorIndexExists, fmt.Sprintf("Index `%s` already exists on table `%s`", newName, table.Name), token)
+		return
+	}
+	index.Name = newName
+}
+
+// addConstraint adds the index of a table constraint. Foreign keys and
+// CHECK constraints are not tracked.
+func (w *walker) addConstraint(table *Table, ctx mysql.ITableConstraintDefContext, token antlr.Token) {
+	constraint, ok := ctx.(*mysql.TableConstraintDefContext)
+	if !ok || constraint.GetType_() == nil {
+		return
+	}
+	index := &Index{Columns: mysqlparser.NormalizeMySQLKeyListVariants(constraint.KeyListVariants())}
+	switch constraint.GetType_().GetTokenType() {
+	case mysql.MySQLParserPRIMARY_SYMBOL:
+		index.Primary, index.Unique = true, true
+	case mysql.MySQLParserUNIQUE_SYMBOL:
+		index.Unique = true
+	case mysql.MySQLParserFULLTEXT_SYMBOL, mysql.MySQLParserSPATIAL_SYMBOL:
+		index.Fulltext = true
+	case mysql.MySQLParserKEY_SYMBOL, mysql.MySQLParserINDEX_SYMBOL:
+	default:
+		return
+	}
+	switch {
+	case constraint.IndexNameAndType() != nil:
+		index.Name = mysqlparser.NormalizeMySQLIdentifier(constraint.IndexNameAndType().IndexName().Identifier())
+	case constraint.IndexName() != nil:
+		index.Name = mysqlparser.NormalizeMySQLIdentifier(constraint.IndexName().Identifier())
+	case constraint.ConstraintName() != nil && constraint.ConstraintName().Identifier() != nil:
+		index.Name = mysqlparser.NormalizeMySQLIdentifier(constraint.ConstraintName().Identifier())
+	}
+	w.addIndex(table, index, token)
+}
+
+func (w *walker) addIndex(table *Table, index *Index, token antlr.Token) {
+	switch {
+	case index.Primary:
+		if table.PrimaryKey() != nil {
+			w.addError(ErrorPrimaryKeyExists, fmt.Sprintf("Table `%s` already has a primary key", table.Name), token)
+			return
+		}
+		index.Name = "PRIMARY"
+	case index.Name == "":
+		first := "functional_index"
+		if len(index.Columns) > 0 {
+			first = index.Columns[0]
+		}
+		index.Name = table.uniqueIndexName(first)
+	case table.Index(index.Name) != nil:
+		w.addError(ErrorIndexExists, fmt.Sprintf("Index `%s` already exists on table `%s`", index.Name, table.Name), token)
+		return
+	}
+
+	for _, name := range index.Columns {
+		column := table.Column(name)
+		if column == nil {
+			if table.Partial {
+				continue
+			}
+			w.addError(ErrorIndexColumnNotExists, fmt.Sprintf("Index %s on `%s` uses column `%s` that does not exist", index, table.Name, name), token)
+			return
+		}
+		if index.Primary {
+			column.Nullable = false
+		}
+	}
+	table.Indexes = append(table.Indexes, index)
+}
+
+func (w *walker) addError(errorType ErrorType, content string, token antlr.Token) {
+	err := &WalkThroughError{Type: errorType, Content: content}
+	if token != nil {
+		err.Line = w.baseLine + token.GetLine()
+		err.Column = token.GetColumn() + 1
+	}
+	w.errs = append(w.errs, err)
+}
+
+// newColumn returns the column of a definition, and the keys declared on
+// it such as `id INT PRIMARY KEY`.
+func newColumn(name string, definition mysql.IFieldDefinitionContext) (*Column, []*Index) {
+	column := &Column{Name: name, Nullable: true}
+	if definition == nil {
+		return column, nil
+	}
+	if dataType := definition.DataType(); dataType != nil {
+		column.Type = strings.ToLower(mysqlparser.GetOriginalText(dataType))
+		if dataType.GetType_() != nil && dataType.GetType_().GetTokenType() == mysql.MySQLParserSERIAL_SYMBOL {
+			// SERIAL is BIGINT UNSIGNED NOT NULL AUTO_INCREMENT UNIQUE.
+			column.Nullable, column.AutoIncrement = false, true
+		}
+	}
+
+	var keys []*Index
+	for _, attribute := range definition.AllColumnAttribute() {
+		if attribute.NullLiteral() != nil {
+			column.Nullable = attribute.NOT_SYMBOL() == nil
+			continue
+		}
+		if attribute.GetValue() == nil {
+			continue
+		}
+		switch attribute.GetValue().GetTokenType() {
+		case mysql.MySQLParserDEFAULT_SYMBOL:
+			column.HasDefault = true
+		case mysql.MySQLParserAUTO_INCREMENT_SYMBOL:
+			column.AutoIncrement = true
+		case mysql.MySQLParserSERIAL_SYMBOL:
+			column.Nullable, column.AutoIncrement = false, true
+		case mysql.MySQLParserKEY_SYMBOL:
+			keys = append(keys, &Index{Columns: []string{name}, Primary: true, Unique: true})
+		case mysql.MySQLParserUNIQUE_SYMBOL:
+			keys = append(keys, &Index{Columns: []string{name}, Unique: true})
+		}
+	}
+	return column, keys
+}
+
+func (t *Table) removeIndex(index *Index) {
+	for i, existing := range t.Indexes {
+		if existing == index {
+			t.Indexes = append(t.Indexes[:i], t.Indexes[i+1:]...)
+			return
+		}
+	}
+}
+
+func (t *Table) renameIndexColumn(oldName, newName string) {
+	for _, index := range t.Indexes {
+		for i, column := range index.Columns {
+			if strings.EqualFold(column, oldName) {
+				index.Columns[i] = newName
+			}
+		}
+	}
+}
@@ -9,7 +9,8 @@ type Config struct {
 	Server   ServerConfig   `yaml:"server" mapstructure:"server"`
 	Database DatabaseConfig `yaml:"database" mapstructure:"database"`
 	Logging  LoggingConfig  `yaml:"logging" mapstructure:"logging"`
-	Rules    RulesConfig    `yaml:"rules" mapstructure:"rules"`
+	// 规则配置和审查策略，来自 rules.yaml
+	ReviewConfig `yaml:",inline" mapstructure:",squash"`
 }
 
 // ServerConfig 服务器配置
@@ -55,25 +56,50 @@ type LoggingConfig struct {
 	Format string `yaml:"format" mapstructure:"format" env:"LOG_FORMAT"`
 }
 
-// RulesConfig 规则配置
-type RulesConfig struct {
-	MySQL MySQLRulesConfig `yaml:"mysql" mapstructure:"mysql"`
+// ReviewConfig 审查配置：基础规则配置、审查策略和策略绑定
+type ReviewConfig struct {
+	Rules          RulesConfig             `yaml:"rules" mapstructure:"rules"`
+	Policies       map[string]PolicyConfig `yaml:"policies" mapstructure:"policies"`
+	PolicyBindings PolicyBindingsConfig    `yaml:"policy_bindings" mapstructure:"policy_bindings"`
 }
 
-// MySQLRulesConfig MySQL规则配置
-type MySQLRulesConfig struct {
-	TableRequirePK     RuleConfig `yaml:"table_require_pk" mapstructure:"table_require_pk"`
-	NamingConvention   RuleConfig `yaml:"naming_convention" mapstructure:"naming_convention"`
-	StatementSafety    RuleConfig `yaml:"statement_safety" mapstructure:"statement_safety"`
-	ColumnTypeCheck    RuleConfig `yaml:"column_type_check" mapstructure:"column_type_check"`
-	SelectPerformance  RuleConfig `yaml:"select_performance" mapstructure:"select_performance"`
+// PolicyConfig 审查策略，在基础规则配置之上调整规则
+type PolicyConfig struct {
+	// Extends 继承的内置策略模板名，如 mysql-prod，策略的配置叠加在模板之上
+	Extends string `yaml:"extends,omitempty" mapstructure:"extends"`
+	// Level 策略中所有规则的级别，策略中单独配置了级别的规则除外，不填时不调整
+	Level string `yaml:"level,omitempty" mapstructure:"level"`
+	// Rules 叠加在基础规则配置之上的规则配置，格式与 rules 相同
+	Rules RulesConfig `yaml:"rules,omitempty" mapstructure:"rules"`
 }
 
+// PolicyBindingsConfig 策略绑定，决定审查使用的策略
+type PolicyBindingsConfig struct {
+	// Environments 环境名（APP_ENV）到策略名的映射
+	Environments map[string]string `yaml:"environments" mapstructure:"environments"`
+	// Databases 按数据库名匹配的策略，按顺序匹配，第一个匹配的生效
+	Databases []DatabasePolicyBinding `yaml:"databases" mapstructure:"databases"`
+}
+
+// DatabasePolicyBinding 数据库名模式到策略的绑定
+type DatabasePolicyBinding struct {
+	// Pattern 数据库名的通配符模式，如 *_prod，语法同 path.Match
+	Pattern string `yaml:"pattern" mapstructure:"pattern"`
+	Policy  string `yaml:"policy" mapstructure:"policy"`
+}
+
+// RulesConfig 规则配置，按引擎名（如 mysql、postgresql）和规则类型（如 mysql.table.require-pk）组织，
+// 没有列出的规则使用默认配置
+type RulesConfig map[string]map[string]RuleConfig
+
 // RuleConfig 单个规则配置
 type RuleConfig struct {
-	Enabled bool                   `yaml:"enabled" mapstructure:"enabled"`
-	Level   string                 `yaml:"level" mapstructure:"level"`
-	Options map[string]interface{} `yaml:"options" mapstructure:"options"`
+	// Enabled 为 false 时不执行该规则，不填时启用
+	Enabled *bool `yaml:"enabled,omitempty" mapstructure:"enabled"`
+	// Level 规则级别：ERROR、WARNING、INFO，不填时使用规则的默认级别
+	Level string `yaml:"level,omitempty" mapstructure:"level"`
+	// Payload 规则参数，可用的参数由规则的参数结构声明，没有填写的参数使用默认值
+	Payload map[string]interface{} `yaml:"payload,omitempty" mapstructure:"payload"`
 }
 
 // GetDefaultConfig 获取默认配置
@@ -100,39 +126,5 @@ func GetDefaultConfig() *Config {
 			Level:  "info",
 			Format: "text",
 		},
-		Rules: RulesConfig{
-			MySQL: MySQLRulesConfig{
-				TableRequirePK: RuleConfig{
-					Enabled: true,
-					Level:   "ERROR",
-					Options: make(map[string]interface{}),
-				},
-				NamingConvention: RuleConfig{
-					Enabled: true,
-					Level:   "WARNING",
-					Options: map[string]interface{}{
-						"table_pattern":  "^[a-z][a-z0-9_]*$",
-						"column_pattern": "^[a-z][a-z0-9_]*$",
-					},
-				},
-				StatementSafety: RuleConfig{
-					Enabled: true,
-					Level:   "ERROR",
-					Options: make(map[string]interface{}),
-				},
-				ColumnTypeCheck: RuleConfig{
-					Enabled: false,
-					Level:   "WARNING",
-					Options: map[string]interface{}{
-						"forbidden_types": []string{"text", "blob"},
-					},
-				},
-				SelectPerformance: RuleConfig{
-					Enabled: true,
-					Level:   "WARNING",
-					Options: make(map[string]interface{}),
-				},
-			},
-		},
 	}
-}
\ No newline at end of file
+}
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -72,6 +74,19 @@ func (l *Loader) Load() (*Config, error) {
 	return config, nil
 }
 
+// LoadReview 只加载审查配置：rules.yaml 中的规则配置、审查策略和策略绑定，
+// rules.yaml 不存在时为空，所有规则使用默认配置
+func (l *Loader) LoadReview() (*ReviewConfig, error) {
+	config := GetDefaultConfig()
+	if err := l.loadRulesConfig(config); err != nil {
+		return nil, fmt.Errorf("failed to load rules config: %w", err)
+	}
+	if err := config.ReviewConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules config: %w", err)
+	}
+	return &config.ReviewConfig, nil
+}
+
 // loadYAMLFile 加载YAML文件
 func (l *Loader) loadYAMLFile(filename string, config *Config) error {
 	filepath := filepath.Join(l.configDir, filename)
@@ -97,16 +112,34 @@ func (l *Loader) loadRulesConfig(config *Config) error {
 		return err
 	}
 
-	// 只更新规则部分
-	var rulesConfig struct {
-		Rules RulesConfig `yaml:"rules"`
-	}
-
-	if err := yaml.Unmarshal(data, &rulesConfig); err != nil {
+	// 只更新审查配置部分，rules.yaml 中列出的规则和策略覆盖原有配置；
+	// 拼错的字段名（如 levle）直接报错，而不是被忽略
+	var review ReviewConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&review); err != nil {
+		if err == io.EOF {
+			return nil
+		}
 		return err
 	}
 
-	config.Rules = rulesConfig.Rules
+	config.Rules = config.Rules.Merge(review.Rules)
+	if config.Policies == nil {
+		config.Policies = make(map[string]PolicyConfig)
+	}
+	for name, policy := range review.Policies {
+		config.Policies[name] = policy
+	}
+	if config.PolicyBindings.Environments == nil {
+		config.PolicyBindings.Environments = make(map[string]string)
+	}
+	for env, policy := range review.PolicyBindings.Environments {
+		config.PolicyBindings.Environments[env] = policy
+	}
+	if len(review.PolicyBindings.Databases) > 0 {
+		config.PolicyBindings.Databases = review.PolicyBindings.Databases
+	}
 	return nil
 }
 
@@ -227,6 +260,11 @@ func (l *Loader) validateConfig(config *Config) error {
 		return fmt.Errorf("invalid log level: %s (must be one of: %v)", config.Logging.Level, validLevels)
 	}
 
+	// 验证规则配置
+	if err := config.ReviewConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid rules config: %w", err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+// Validate 校验审查配置：基础规则配置和各策略（含继承的模板）的规则配置有效，策略级别有效，
+// 继承的模板和绑定引用的策略存在，数据库名模式合法。返回所有发现的问题
+func (c *ReviewConfig) Validate() error {
+	var errs []error
+	if err := c.Rules.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, name := range c.policyNames() {
+		policy, err := c.Policies[name].Resolve()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("policies.%s: %w", name, err))
+			continue
+		}
+		if policy.Level != "" {
+			if _, err := advisor.ParseLevel(policy.Level); err != nil {
+				errs = append(errs, fmt.Errorf("policies.%s: %w", name, err))
+			}
+		}
+		if err := policy.Rules.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("policies.%s: %w", name, err))
+		}
+	}
+
+	var envs []string
+	for env := range c.PolicyBindings.Environments {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+	for _, env := range envs {
+		if err := c.checkPolicy(c.PolicyBindings.Environments[env]); err != nil {
+			errs = append(errs, fmt.Errorf("policy_bindings.environments.%s: %w", env, err))
+		}
+	}
+	for i, binding := range c.PolicyBindings.Databases {
+		if _, err := path.Match(binding.Pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("policy_bindings.databases[%d]: invalid pattern %q: %w", i, binding.Pattern, err))
+		}
+		if err := c.checkPolicy(binding.Policy); err != nil {
+			errs = append(errs, fmt.Errorf("policy_bindings.databases[%d]: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkPolicy 检查策略是否存在
+func (c *ReviewConfig) checkPolicy(name string) error {
+	if _, ok := c.Policies[name]; !ok {
+		return fmt.Errorf("unknown policy %q", name)
+	}
+	return nil
+}
+
+// policyNames 返回排序后的策略名
+func (c *ReviewConfig) policyNames() []string {
+	var names []string
+	for name := range c.Policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Merge 返回在 c 之上叠加 overlay 的规则配置：overlay 中填写的 enabled、level 覆盖 c 中的配置，
+// payload 按参数合并
+func (c RulesConfig) Merge(overlay RulesConfig) RulesConfig {
+	merged := make(RulesConfig)
+	for _, rules := range []RulesConfig{c, overlay} {
+		for engineName, engineRules := range rules {
+			if merged[engineName] == nil {
+				merged[engineName] = make(map[string]RuleConfig)
+			}
+			for ruleType, ruleConfig := range engineRules {
+				current := merged[engineName][ruleType]
+				if ruleConfig.Enabled != nil {
+					current.Enabled = ruleConfig.Enabled
+				}
+				if ruleConfig.Level != "" {
+					current.Level = ruleConfig.Level
+				}
+				if len(ruleConfig.Payload) > 0 {
+					payload := make(map[string]interface{})
+					for option, value := range current.Payload {
+						payload[option] = value
+					}
+					for option, value := range ruleConfig.Payload {
+						payload[option] = value
+					}
+					current.Payload = payload
+				}
+				merged[engineName][ruleType] = current
+			}
+		}
+	}
+	return merged
+}
+
+// PolicySet 构建好的审查策略，按策略绑定为每次审查选择规则
+type PolicySet struct {
+	env      string
+	bindings PolicyBindingsConfig
+	// rules 策略名到规则列表的映射，空策略名对应基础规则
+	rules map[string][]*advisor.Rule
+}
+
+// BuildPolicies 按审查配置构建基础规则和所有策略的规则。env 是当前环境名，用于按环境选择策略
+func (c *ReviewConfig) BuildPolicies(env string, defaults []*advisor.Rule) (*PolicySet, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	base, err := c.Rules.Build(defaults)
+	if err != nil {
+		return nil, err
+	}
+	set := &PolicySet{
+		env:      env,
+		bindings: c.PolicyBindings,
+		rules:    map[string][]*advisor.Rule{"": base},
+	}
+
+	for _, name := range c.policyNames() {
+		rules, err := c.PolicyRules(c.Policies[name], defaults)
+		if err != nil {
+			return nil, fmt.Errorf("policies.%s: %w", name, err)
+		}
+		set.rules[name] = rules
+	}
+	return set, nil
+}
+
+// PolicyRules 返回策略的规则列表：基础规则配置之上依次叠加继承的模板和策略的规则配置，
+// 再应用策略的级别。策略的级别不覆盖单独配置了级别的规则：级别由策略自己设置时只看策略自己的规则配置，
+// 级别来自模板时模板中单独配置的级别也保留，并且只作用于模板 rules 中列出的引擎
+func (c *ReviewConfig) PolicyRules(policy PolicyConfig, defaults []*advisor.Rule) ([]*advisor.Rule, error) {
+	resolved, err := policy.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := c.Rules.Merge(resolved.Rules).Build(defaults)
+	if err != nil {
+		return nil, err
+	}
+	if resolved.Level == "" {
+		return rules, nil
+	}
+
+	level, err := advisor.ParseLevel(resolved.Level)
+	if err != nil {
+		return nil, err
+	}
+	explicit := resolved.Rules
+	var levelEngines RulesConfig
+	if policy.Level != "" {
+		explicit = policy.Rules
+	} else {
+		// 如 postgresql-prod 的级别不改变 MySQL 规则的级别
+		template, err := LookupTemplate(policy.Extends)
+		if err != nil {
+			return nil, err
+		}
+		levelEngines = template.Rules
+	}
+	for _, rule := range rules {
+		if levelEngines != nil {
+			if _, ok := levelEngines.engineName(rule.Engine); !ok {
+				continue
+			}
+		}
+		engineName, ok := explicit.engineName(rule.Engine)
+		if ok && explicit[engineName][string(rule.Type)].Level != "" {
+			continue
+		}
+		rule.Level = level
+	}
+	return rules, nil
+}
+
+// Rules 返回策略的规则列表，name 为空时返回基础规则，策略不存在时返回 false
+func (s *PolicySet) Rules(name string) ([]*advisor.Rule, bool) {
+	rules, ok := s.rules[name]
+	return rules, ok
+}
+
+// Names 返回排序后的策略名
+func (s *PolicySet) Names() []string {
+	var names []string
+	for name := range s.rules {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve 选择审查使用的策略：连接指定的策略优先，其次是第一个匹配数据库名的绑定，
+// 再次是当前环境绑定的策略，都没有时返回空，即使用基础规则
+func (s *PolicySet) Resolve(connectionPolicy, databaseName string) string {
+	if connectionPolicy != "" {
+		return connectionPolicy
+	}
+	if databaseName != "" {
+		for _, binding := range s.bindings.Databases {
+			if matched, _ := path.Match(binding.Pattern, databaseName); matched {
+				return binding.Policy
+			}
+		}
+	}
+	return s.bindings.Environments[s.env]
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/rules/mysql"
+)
+
+// TestBuildPolicies 测试策略在基础规则之上调整规则级别和启用状态
+func TestBuildPolicies(t *testing.T) {
+	disabled := false
+	review := &ReviewConfig{
+		Rules: RulesConfig{
+			"mysql": {"mysql.table.comment": {Level: "INFO"}},
+		},
+		Policies: map[string]PolicyConfig{
+			"prod": {Level: "error"},
+			"dev": {
+				Level: "WARNING",
+				Rules: RulesConfig{
+					"mysql": {
+						"mysql.table.require-pk": {Level: "ERROR"},
+						"mysql.table.comment":    {Enabled: &disabled},
+					},
+				},
+			},
+		},
+	}
+	policies, err := review.BuildPolicies("development", mysql.DefaultRules())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if names := policies.Names(); strings.Join(names, ",") != "dev,prod" {
+		t.Errorf("Expected policies dev,prod, got %v", names)
+	}
+
+	levels := func(name string) map[advisor.Type]advisor.Level {
+		rules, ok := policies.Rules(name)
+		if !ok {
+			t.Fatalf("Expected policy %q", name)
+		}
+		result := make(map[advisor.Type]advisor.Level)
+		for _, rule := range rules {
+			result[rule.Type] = rule.Level
+		}
+		return result
+	}
+
+	// 基础规则不受策略影响
+	base := levels("")
+	if base[advisor.MySQLTableComment] != advisor.LevelInfo || base[advisor.MySQLNamingConvention] != advisor.LevelWarning {
+		t.Errorf("Unexpected base levels: %v", base)
+	}
+
+	for ruleType, level := range levels("prod") {
+		if level != advisor.LevelError {
+			t.Errorf("Expected %s at ERROR in prod, got %s", ruleType, level)
+		}
+	}
+
+	dev := levels("dev")
+	if _, ok := dev[advisor.MySQLTableComment]; ok {
+		t.Error("Expected the table comment rule to be disabled in dev")
+	}
+	if dev[advisor.MySQLTableRequirePK] != advisor.LevelError {
+		t.Errorf("Expected the level set for the rule in the policy to win, got %s", dev[advisor.MySQLTableRequirePK])
+	}
+	if dev[advisor.MySQLStatementSafety] != advisor.LevelWarning {
+		t.Errorf("Expected WARNING in dev, got %s", dev[advisor.MySQLStatementSafety])
+	}
+
+	if _, ok := policies.Rules("staging"); ok {
+		t.Error("Expected no staging policy")
+	}
+}
+
+// TestResolvePolicy 测试按连接、数据库名和环境选择策略
+func TestResolvePolicy(t *testing.T) {
+	review := &ReviewConfig{
+		Policies: map[string]PolicyConfig{"prod": {}, "dev": {}, "audit": {}},
+		PolicyBindings: PolicyBindingsConfig{
+			Environments: map[string]string{"production": "prod"},
+			Databases: []DatabasePolicyBinding{
+				{Pattern: "*_dev", Policy: "dev"},
+				{Pattern: "shop_*", Policy: "prod"},
+			},
+		},
+	}
+
+	tests := []struct {
+		env              string
+		connectionPolicy string
+		databaseName     string
+		expected         string
+	}{
+		{env: "production", connectionPolicy: "audit", databaseName: "shop_dev", expected: "audit"},
+		{env: "production", databaseName: "shop_dev", expected: "dev"},
+		{env: "development", databaseName: "shop_main", expected: "prod"},
+		{env: "production", databaseName: "crm", expected: "prod"},
+		{env: "development", databaseName: "crm", expected: ""},
+		{env: "development", expected: ""},
+	}
+	for _, tt := range tests {
+		policies, err := review.BuildPolicies(tt.env, mysql.DefaultRules())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if policy := policies.Resolve(tt.connectionPolicy, tt.databaseName); policy != tt.expected {
+			t.Errorf("Resolve(%q, %q) in %s: expected %q, got %q", tt.connectionPolicy, tt.databaseName, tt.env, tt.expected, policy)
+		}
+	}
+}
+
+// TestReviewConfigValidate 测试策略和策略绑定的校验
+func TestReviewConfigValidate(t *testing.T) {
+	review := &ReviewConfig{
+		Policies: map[string]PolicyConfig{
+			"prod": {Level: "FATAL"},
+			"dev":  {Rules: RulesConfig{"mysql": {"mysql.table.requre-pk": {}}}},
+		},
+		PolicyBindings: PolicyBindingsConfig{
+			Environments: map[string]string{"production": "production"},
+			Databases:    []DatabasePolicyBinding{{Pattern: "[shop", Policy: "prod"}},
+		},
+	}
+
+	err := review.Validate()
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	for _, expected := range []string{
+		`policies.prod: invalid level "FATAL"`,
+		`policies.dev: rules.mysql.mysql.table.requre-pk`,
+		`policy_bindings.environments.production: unknown policy "production"`,
+		`policy_bindings.databases[0]: invalid pattern "[shop"`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing %q, got %v", expected, err)
+		}
+	}
+}
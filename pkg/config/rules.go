@@ -0,0 +1,299 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+// Validate 校验规则配置：规则类型必须已在对应引擎注册，级别必须有效，
+// 参数必须是规则参数结构中声明的字段且类型一致。返回所有发现的问题
+func (c RulesConfig) Validate() error {
+	var errs []error
+	for _, engineName := range c.engineNames() {
+		for _, ruleType := range c.ruleTypes(engineName) {
+			if _, err := c.buildRule(engineName, ruleType, nil); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Build 按规则配置生成规则列表。defaults 中的规则保持原有顺序，enabled 为 false 的规则被移除，
+// 配置的级别和参数覆盖默认值；配置中不在 defaults 里的已注册规则按引擎和类型排序追加在后面，
+// 这些规则必须配置级别
+func (c RulesConfig) Build(defaults []*advisor.Rule) ([]*advisor.Rule, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[advisor.Engine]map[advisor.Type]bool)
+	var rules []*advisor.Rule
+	for _, defaultRule := range defaults {
+		// 复制默认规则，返回的规则可以修改而不影响 defaults
+		rule := *defaultRule
+		if seen[rule.Engine] == nil {
+			seen[rule.Engine] = make(map[advisor.Type]bool)
+		}
+		seen[rule.Engine][rule.Type] = true
+
+		engineName, ok := c.engineName(rule.Engine)
+		if !ok {
+			rules = append(rules, &rule)
+			continue
+		}
+		if _, ok := c[engineName][string(rule.Type)]; !ok {
+			rules = append(rules, &rule)
+			continue
+		}
+		configured, err := c.buildRule(engineName, string(rule.Type), &rule)
+		if err != nil {
+			return nil, err
+		}
+		if configured != nil {
+			rules = append(rules, configured)
+		}
+	}
+
+	for _, engineName := range c.engineNames() {
+		engine := advisor.EngineFromName(engineName)
+		for _, ruleType := range c.ruleTypes(engineName) {
+			if seen[engine][advisor.Type(ruleType)] {
+				continue
+			}
+			configured, err := c.buildRule(engineName, ruleType, nil)
+			if err != nil {
+				return nil, err
+			}
+			if configured == nil {
+				continue
+			}
+			if configured.Level == "" {
+				return nil, fmt.Errorf("rules.%s.%s: level is required for a rule that is not enabled by default", engineName, ruleType)
+			}
+			rules = append(rules, configured)
+		}
+	}
+	return rules, nil
+}
+
+// buildRule 按单个规则的配置生成规则，规则被禁用时返回 nil。defaultRule 提供默认级别和参数，可以为 nil，
+// 此时没有配置级别的规则级别为空
+func (c RulesConfig) buildRule(engineName, ruleType string, defaultRule *advisor.Rule) (*advisor.Rule, error) {
+	ruleConfig := c[engineName][ruleType]
+	engine := advisor.EngineFromName(engineName)
+
+	rule := &advisor.Rule{Type: advisor.Type(ruleType), Engine: engine}
+	if defaultRule != nil {
+		rule.Level = defaultRule.Level
+		rule.Payload = defaultRule.Payload
+	}
+
+	payload, err := advisor.DecodePayload(engine, rule.Type, ruleConfig.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("rules.%s.%s: %w", engineName, ruleType, err)
+	}
+	if len(ruleConfig.Payload) > 0 {
+		rule.Payload = payload
+	}
+
+	if ruleConfig.Level != "" {
+		level, err := advisor.ParseLevel(ruleConfig.Level)
+		if err != nil {
+			return nil, fmt.Errorf("rules.%s.%s: %w", engineName, ruleType, err)
+		}
+		rule.Level = level
+	}
+	if ruleConfig.Enabled != nil && !*ruleConfig.Enabled {
+		return nil, nil
+	}
+	return rule, nil
+}
+
+// engineName 返回配置中与引擎对应的引擎名
+func (c RulesConfig) engineName(engine advisor.Engine) (string, bool) {
+	for engineName := range c {
+		if advisor.EngineFromName(engineName) == engine {
+			return engineName, true
+		}
+	}
+	return "", false
+}
+
+// engineNames 返回排序后的引擎名
+func (c RulesConfig) engineNames() []string {
+	var names []string
+	for engineName := range c {
+		names = append(names, engineName)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ruleTypes 返回引擎下排序后的规则类型
+func (c RulesConfig) ruleTypes(engineName string) []string {
+	var types []string
+	for ruleType := range c[engineName] {
+		types = append(types, ruleType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// RulesSchema 返回 rules.yaml 的 JSON Schema，包含所有已注册引擎的规则及其参数结构、
+// 审查策略（含可继承的内置模板）和策略绑定，供编辑器校验和补全 rules.yaml
+func RulesSchema() (map[string]any, error) {
+	// 级别和引擎名不区分大小写，与 ParseLevel、EngineFromName 一致
+	levels := []string{string(advisor.LevelError), string(advisor.LevelWarning), string(advisor.LevelInfo)}
+	levelSchema := map[string]any{"type": "string", "pattern": advisor.CaseInsensitivePattern(levels), "examples": levels}
+	engines := make(map[string]any)
+	for _, engine := range advisor.GetRegisteredEngines() {
+		rules := make(map[string]any)
+		for _, ruleType := range advisor.GetRegisteredAdvisors(engine) {
+			payload, err := advisor.PayloadSchema(engine, ruleType)
+			if err != nil {
+				return nil, err
+			}
+			if payload == nil {
+				// 没有参数的规则只接受空的 payload
+				payload = map[string]any{"type": "object", "maxProperties": 0}
+			}
+
+			rule := map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"enabled": map[string]any{"type": "boolean", "default": true},
+					"level":   levelSchema,
+					"payload": payload,
+				},
+				"additionalProperties": false,
+			}
+			for _, info := range advisor.DescribeRules([]*advisor.Rule{{Type: ruleType, Engine: engine}}) {
+				rule["title"] = info.Title
+				if info.Description != "" {
+					rule["description"] = info.Description
+				}
+			}
+			rules[string(ruleType)] = rule
+		}
+		engines[advisor.CaseInsensitivePattern([]string{strings.ToLower(string(engine))})] = map[string]any{
+			"type":                 "object",
+			"properties":           rules,
+			"additionalProperties": false,
+		}
+	}
+
+	var templateNames []string
+	for _, template := range Templates() {
+		templateNames = append(templateNames, template.Name)
+	}
+
+	return map[string]any{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title":   "SQL Review rules.yaml",
+		"type":    "object",
+		"definitions": map[string]any{
+			"rules": map[string]any{
+				"type":                 "object",
+				"patternProperties":    engines,
+				"additionalProperties": false,
+			},
+		},
+		"properties": map[string]any{
+			"rules": map[string]any{"$ref": "#/definitions/rules"},
+			"policies": map[string]any{
+				"type": "object",
+				"additionalProperties": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"extends": map[string]any{"type": "string", "enum": templateNames},
+						"level":   levelSchema,
+						"rules":   map[string]any{"$ref": "#/definitions/rules"},
+					},
+					"additionalProperties": false,
+				},
+			},
+			"policy_bindings": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"environments": map[string]any{
+						"type":                 "object",
+						"additionalProperties": map[string]any{"type": "string"},
+					},
+					"databases": map[string]any{
+						"type": "array",
+						"items": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"pattern": map[string]any{"type": "string"},
+								"policy":  map[string]any{"type": "string"},
+							},
+							"required":             []string{"pattern", "policy"},
+							"additionalProperties": false,
+						},
+					},
+				},
+				"additionalProperties": false,
+			},
+		},
+		"additionalProperties": false,
+	}, nil
+}
+
+// ExportRules 把规则列表转换为完整的规则配置：每个已注册规则都列出，
+// 列表中的规则给出级别和包含默认值的全部参数，不在列表中的规则标记为禁用
+func ExportRules(rules []*advisor.Rule) (RulesConfig, error) {
+	enabled := make(map[advisor.Engine]map[advisor.Type]*advisor.Rule)
+	for _, rule := range rules {
+		if enabled[rule.Engine] == nil {
+			enabled[rule.Engine] = make(map[advisor.Type]*advisor.Rule)
+		}
+		enabled[rule.Engine][rule.Type] = rule
+	}
+
+	exported := make(RulesConfig)
+	for _, engine := range advisor.GetRegisteredEngines() {
+		engineName := strings.ToLower(string(engine))
+		exported[engineName] = make(map[string]RuleConfig)
+		for _, ruleType := range advisor.GetRegisteredAdvisors(engine) {
+			rule, ok := enabled[engine][ruleType]
+			if !ok {
+				disabled := false
+				exported[engineName][string(ruleType)] = RuleConfig{Enabled: &disabled}
+				continue
+			}
+
+			// 规则的参数可能是配置中的 map 或参数结构，统一解析为参数结构以补全默认值
+			var options map[string]interface{}
+			if err := convertJSON(rule.Payload, &options); err != nil {
+				return nil, fmt.Errorf("rules.%s.%s: %w", engineName, ruleType, err)
+			}
+			payload, err := advisor.DecodePayload(engine, ruleType, options)
+			if err != nil {
+				return nil, fmt.Errorf("rules.%s.%s: %w", engineName, ruleType, err)
+			}
+			ruleConfig := RuleConfig{Level: string(rule.Level)}
+			if payload != nil {
+				if err := convertJSON(payload, &ruleConfig.Payload); err != nil {
+					return nil, fmt.Errorf("rules.%s.%s: %w", engineName, ruleType, err)
+				}
+			}
+			exported[engineName][string(ruleType)] = ruleConfig
+		}
+	}
+	return exported, nil
+}
+
+// convertJSON 通过 JSON 编解码把 from 转换为 to
+func convertJSON(from, to any) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
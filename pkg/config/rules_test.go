@@ -0,0 +1,261 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/rules/mysql"
+)
+
+// TestRulesConfigBuild 测试按规则配置启用规则、设置级别和参数
+func TestRulesConfigBuild(t *testing.T) {
+	// 空配置与默认规则一致
+	rules, err := RulesConfig(nil).Build(mysql.DefaultRules())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defaults := mysql.DefaultRules()
+	if len(rules) != len(defaults) {
+		t.Fatalf("Expected %d rules, got %d", len(defaults), len(rules))
+	}
+	for i, rule := range rules {
+		if rule.Type != defaults[i].Type || rule.Level != defaults[i].Level {
+			t.Errorf("Expected %s at %s, got %s at %s", defaults[i].Type, defaults[i].Level, rule.Type, rule.Level)
+		}
+	}
+
+	disabled := false
+	cfg := RulesConfig{
+		"mysql": {
+			"mysql.table.require-pk":  {Level: "warning"},
+			"mysql.naming.convention": {Payload: map[string]interface{}{"table_pattern": "^t_[a-z]+$"}},
+			"mysql.statement.safety":  {Enabled: &disabled, Level: "ERROR"},
+		},
+	}
+	rules, err = cfg.Build(mysql.DefaultRules())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rules) != len(defaults)-1 {
+		t.Fatalf("Expected %d rules, got %d", len(defaults)-1, len(rules))
+	}
+	// 参数按规则声明的参数结构解析，没有填写的参数使用默认值
+	payload, ok := rules[1].Payload.(*mysql.NamingConventionPayload)
+	if !ok || payload.TablePattern != "^t_[a-z]+$" || payload.MaxLength != mysql.DefaultNamingConventionPayload().MaxLength {
+		t.Errorf("Expected a typed naming convention payload, got %#v", rules[1].Payload)
+	}
+
+	advices, err := advisor.SQLReviewCheck(context.Background(), rules, advisor.Context{
+		SQL:    "CREATE TABLE users (id INT);\nDELETE FROM users;",
+		Engine: advisor.MySQL,
+		Rules:  []string{"mysql.table.require-pk", "mysql.naming.convention", "mysql.statement.safety"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var results []string
+	for _, advice := range advices {
+		results = append(results, fmt.Sprintf("%s %d %s", advice.RuleID, advice.Code, advice.Status))
+	}
+	// 级别覆盖体现在 Status 中，禁用的规则不执行
+	expected := []string{
+		"mysql.table.require-pk 801 WARNING",
+		"mysql.naming.convention 802 WARNING",
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+}
+
+// TestRulesConfigValidate 测试规则类型、级别和参数的校验
+func TestRulesConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RulesConfig
+		errMsg string
+	}{
+		{
+			name:   "Misspelled rule type",
+			config: RulesConfig{"mysql": {"mysql.table.requre-pk": {}}},
+			errMsg: `did you mean "mysql.table.require-pk"?`,
+		},
+		{
+			name:   "Unknown engine",
+			config: RulesConfig{"oracle": {"oracle.table.require-pk": {}}},
+			errMsg: "unknown engine type ORACLE",
+		},
+		{
+			name:   "Invalid level",
+			config: RulesConfig{"mysql": {"mysql.table.require-pk": {Level: "FATAL"}}},
+			errMsg: `invalid level "FATAL"`,
+		},
+		{
+			name:   "Misspelled option",
+			config: RulesConfig{"mysql": {"mysql.naming.convention": {Payload: map[string]interface{}{"max_lenght": 64}}}},
+			errMsg: `unknown option "max_lenght" of rule mysql.naming.convention, did you mean "max_length"?`,
+		},
+		{
+			name:   "Option of wrong type",
+			config: RulesConfig{"mysql": {"mysql.naming.convention": {Payload: map[string]interface{}{"max_length": "64"}}}},
+			errMsg: `option "max_length" of rule mysql.naming.convention must be int, got string`,
+		},
+		{
+			name:   "Option below its minimum",
+			config: RulesConfig{"mysql": {"mysql.select.performance": {Payload: map[string]interface{}{"max_limit": -1}}}},
+			errMsg: `option "max_limit" of rule mysql.select.performance: must be at least 0, got -1`,
+		},
+		{
+			name:   "Option that is not a list",
+			config: RulesConfig{"mysql": {"mysql.column.type-check": {Payload: map[string]interface{}{"forbidden_types": "text"}}}},
+			errMsg: `option "forbidden_types" of rule mysql.column.type-check must be []string, got string`,
+		},
+		{
+			name:   "Invalid regular expression",
+			config: RulesConfig{"mysql": {"mysql.column.type-check": {Payload: map[string]interface{}{"money_column_pattern": "(price"}}}},
+			errMsg: `option "money_column_pattern" of rule mysql.column.type-check: is not a valid regular expression`,
+		},
+		{
+			name:   "Invalid enum value",
+			config: RulesConfig{"mysql": {"mysql.table.partition": {Payload: map[string]interface{}{"allowed_partition_types": []interface{}{"RANGE", "rnge"}}}}},
+			errMsg: `has invalid value "rnge", must be one of range, list, hash, key, did you mean "range"?`,
+		},
+		{
+			name:   "Unknown naming template",
+			config: RulesConfig{"mysql": {"mysql.naming.convention": {Payload: map[string]interface{}{"index_pattern": "^idx_{{tabel}}$"}}}},
+			errMsg: "unknown template",
+		},
+		{
+			name:   "Payload of rule without options",
+			config: RulesConfig{"mysql": {"mysql.table.require-pk": {Payload: map[string]interface{}{"strict": true}}}},
+			errMsg: "rule mysql.table.require-pk takes no payload",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	// 所有规则的默认参数都满足约束
+	all := RulesConfig{"mysql": {}}
+	for _, ruleType := range advisor.GetRegisteredAdvisors(advisor.MySQL) {
+		all["mysql"][string(ruleType)] = RuleConfig{}
+	}
+	all["mysql"]["mysql.table.require-pk"] = RuleConfig{Payload: map[string]interface{}{}}
+	if err := all.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// TestRulesSchema 测试 config/rules.schema.json 与规则声明的参数结构一致，
+// 规则或参数变化后需要执行 make schema 重新生成
+func TestRulesSchema(t *testing.T) {
+	schema, err := RulesSchema()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join("..", "..", "config", "rules.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, buf.Bytes()) {
+		t.Error("config/rules.schema.json is out of date, run make schema")
+	}
+
+	payload := mysqlRuleSchema(t, schema, "mysql.select.performance")["properties"].(map[string]any)["payload"].(map[string]any)
+	maxLimit := payload["properties"].(map[string]any)["max_limit"].(map[string]any)
+	if maxLimit["type"] != "integer" || maxLimit["minimum"] != int64(0) || maxLimit["default"] != float64(1000) {
+		t.Errorf("Unexpected schema of max_limit: %v", maxLimit)
+	}
+}
+
+// TestRulesSchemaIgnoresCase 测试 JSON Schema 与配置校验一样不区分级别、引擎名和枚举参数的大小写
+func TestRulesSchemaIgnoresCase(t *testing.T) {
+	schema, err := RulesSchema()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	matches := func(pattern, value string) bool {
+		return regexp.MustCompile(pattern).MatchString(value)
+	}
+
+	rule := mysqlRuleSchema(t, schema, "mysql.table.partition")
+	levelPattern := rule["properties"].(map[string]any)["level"].(map[string]any)["pattern"].(string)
+	for _, level := range []string{"ERROR", "warning", "Info", "warn", "ERRORS"} {
+		_, err := advisor.ParseLevel(level)
+		if accepted := err == nil; matches(levelPattern, level) != accepted {
+			t.Errorf("Level %q: accepted by ParseLevel %v, by the schema %v", level, accepted, !accepted)
+		}
+	}
+
+	partitionTypes := rule["properties"].(map[string]any)["payload"].(map[string]any)["properties"].(map[string]any)["allowed_partition_types"].(map[string]any)
+	typePattern := partitionTypes["items"].(map[string]any)["pattern"].(string)
+	for _, partitionType := range []string{"range", "RANGE", "Hash", "rnge", "range list"} {
+		config := RulesConfig{"mysql": {"mysql.table.partition": {Payload: map[string]interface{}{"allowed_partition_types": []interface{}{partitionType}}}}}
+		if accepted := config.Validate() == nil; matches(typePattern, partitionType) != accepted {
+			t.Errorf("Partition type %q: accepted by Validate %v, by the schema %v", partitionType, accepted, !accepted)
+		}
+	}
+
+	rules := schema["definitions"].(map[string]any)["rules"].(map[string]any)["patternProperties"].(map[string]any)
+	for engine := range rules {
+		if !matches(engine, "MySQL") || !matches(engine, "mysql") || matches(engine, "mysql8") {
+			t.Errorf("Expected engine pattern %q to match mysql in any case", engine)
+		}
+	}
+}
+
+// mysqlRuleSchema 返回 JSON Schema 中 MySQL 规则的 schema
+func mysqlRuleSchema(t *testing.T, schema map[string]any, ruleType string) map[string]any {
+	t.Helper()
+
+	for engine, rules := range schema["definitions"].(map[string]any)["rules"].(map[string]any)["patternProperties"].(map[string]any) {
+		if regexp.MustCompile(engine).MatchString("mysql") {
+			return rules.(map[string]any)["properties"].(map[string]any)[ruleType].(map[string]any)
+		}
+	}
+	t.Fatal("Expected the rules of mysql in the schema")
+	return nil
+}
+
+// TestLoadReview 测试加载 rules.yaml，项目自带的规则配置有效，拼错的字段名报错
+func TestLoadReview(t *testing.T) {
+	review, err := NewLoader(filepath.Join("..", "..", "config")).LoadReview()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := review.BuildPolicies("development", mysql.DefaultRules()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dir := t.TempDir()
+	content := "rules:\n  mysql:\n    mysql.table.require-pk:\n      levle: ERROR\n"
+	if err := os.WriteFile(filepath.Join(dir, "rules.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewLoader(dir).LoadReview(); err == nil || !strings.Contains(err.Error(), "levle") {
+		t.Errorf("Expected an error for the misspelled field, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed templates/*.yaml
+var templateFiles embed.FS
+
+// PolicyTemplate 内置的审查策略模板，rules.yaml 中的策略可以通过 extends 在模板之上调整
+type PolicyTemplate struct {
+	// Name 模板名，即模板文件名，如 mysql-prod
+	Name        string `yaml:"-"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	// Level 和 Rules 与策略的含义相同，模板通常为每个规则单独设置级别和参数。
+	// 与策略不同，模板的 Level 只作用于 Rules 中列出的引擎
+	Level string      `yaml:"level,omitempty"`
+	Rules RulesConfig `yaml:"rules"`
+}
+
+// policyTemplates 按模板名索引的内置模板
+var policyTemplates = mustLoadTemplates()
+
+// mustLoadTemplates 解析内置模板，模板有误属于程序错误，直接 panic
+func mustLoadTemplates() map[string]*PolicyTemplate {
+	entries, err := templateFiles.ReadDir("templates")
+	if err != nil {
+		panic(fmt.Sprintf("config: failed to read policy templates: %v", err))
+	}
+
+	templates := make(map[string]*PolicyTemplate)
+	for _, entry := range entries {
+		data, err := templateFiles.ReadFile(path.Join("templates", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("config: failed to read policy template %s: %v", entry.Name(), err))
+		}
+
+		template := &PolicyTemplate{Name: strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))}
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(template); err != nil {
+			panic(fmt.Sprintf("config: invalid policy template %s: %v", entry.Name(), err))
+		}
+		templates[template.Name] = template
+	}
+	return templates
+}
+
+// Templates 返回按模板名排序的内置策略模板
+func Templates() []*PolicyTemplate {
+	var templates []*PolicyTemplate
+	for _, template := range policyTemplates {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates
+}
+
+// LookupTemplate 按模板名查找内置策略模板
+func LookupTemplate(name string) (*PolicyTemplate, error) {
+	template, ok := policyTemplates[name]
+	if !ok {
+		var names []string
+		for _, template := range Templates() {
+			names = append(names, template.Name)
+		}
+		return nil, fmt.Errorf("unknown policy template %q, available templates: %s", name, strings.Join(names, ", "))
+	}
+	return template, nil
+}
+
+// Resolve 返回展开 extends 后的策略：模板的级别和规则配置在下，策略自己的配置在上
+func (p PolicyConfig) Resolve() (PolicyConfig, error) {
+	if p.Extends == "" {
+		return p, nil
+	}
+	template, err := LookupTemplate(p.Extends)
+	if err != nil {
+		return PolicyConfig{}, err
+	}
+
+	resolved := PolicyConfig{
+		Level: template.Level,
+		Rules: template.Rules.Merge(p.Rules),
+	}
+	if p.Level != "" {
+		resolved.Level = p.Level
+	}
+	return resolved, nil
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/rules/mysql"
+)
+
+// TestTemplates 测试内置策略模板都能通过校验，MySQL 模板为每个已注册规则设置级别
+func TestTemplates(t *testing.T) {
+	templates := Templates()
+	if len(templates) == 0 {
+		t.Fatal("Expected built-in templates")
+	}
+
+	for _, template := range templates {
+		t.Run(template.Name, func(t *testing.T) {
+			if template.Title == "" || template.Description == "" {
+				t.Error("Expected a title and a description")
+			}
+			// 模板至少设置级别或配置规则，否则继承它的策略等于没有模板
+			configured := template.Level != ""
+			for _, engineRules := range template.Rules {
+				configured = configured || len(engineRules) > 0
+			}
+			if !configured {
+				t.Error("Expected a level or rules")
+			}
+			review := &ReviewConfig{Policies: map[string]PolicyConfig{"p": {Extends: template.Name}}}
+			if _, err := review.BuildPolicies("", mysql.DefaultRules()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !strings.HasPrefix(template.Name, "mysql-") {
+				return
+			}
+			for _, ruleType := range advisor.GetRegisteredAdvisors(advisor.MySQL) {
+				if template.Rules["mysql"][string(ruleType)].Level == "" {
+					t.Errorf("Expected a level for %s", ruleType)
+				}
+			}
+		})
+	}
+
+	if _, err := LookupTemplate("mysql-production"); err == nil || !strings.Contains(err.Error(), "mysql-prod") {
+		t.Errorf("Expected an error listing the templates, got %v", err)
+	}
+}
+
+// TestPolicyExtends 测试策略在模板之上覆盖级别和参数
+func TestPolicyExtends(t *testing.T) {
+	review := &ReviewConfig{
+		Policies: map[string]PolicyConfig{
+			"prod": {
+				Extends: "mysql-prod",
+				Rules: RulesConfig{"mysql": {
+					"mysql.table.comment":      {Level: "WARNING"},
+					"mysql.select.performance": {Payload: map[string]interface{}{"max_limit": 500}},
+				}},
+			},
+			"trial": {Extends: "mysql-prod", Level: "INFO"},
+			"typo":  {Extends: "mysql-prd"},
+		},
+	}
+	if err := review.Validate(); err == nil || !strings.Contains(err.Error(), `policies.typo: unknown policy template "mysql-prd"`) {
+		t.Fatalf("Expected an error for the unknown template, got %v", err)
+	}
+	delete(review.Policies, "typo")
+
+	rules, err := review.PolicyRules(review.Policies["prod"], mysql.DefaultRules())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	byType := make(map[advisor.Type]*advisor.Rule)
+	for _, rule := range rules {
+		byType[rule.Type] = rule
+	}
+	if byType[advisor.MySQLTableComment].Level != advisor.LevelWarning || byType[advisor.MySQLNamingConvention].Level != advisor.LevelError {
+		t.Errorf("Expected the policy level to override the template, got %s and %s", byType[advisor.MySQLTableComment].Level, byType[advisor.MySQLNamingConvention].Level)
+	}
+	// 参数按参数合并：策略覆盖 max_limit，模板设置的其他参数保留
+	payload := byType[advisor.MySQLSelectPerformance].Payload.(*mysql.SelectPerformancePayload)
+	if payload.MaxLimit != 500 || payload.MaxOffset != 10000 || !payload.RequireLimit {
+		t.Errorf("Unexpected select performance payload: %+v", payload)
+	}
+
+	// 策略自己的 level 覆盖模板中每个规则的级别
+	rules, err = review.PolicyRules(review.Policies["trial"], mysql.DefaultRules())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, rule := range rules {
+		if rule.Level != advisor.LevelInfo {
+			t.Errorf("Expected %s at INFO, got %s", rule.Type, rule.Level)
+		}
+	}
+}
+
+// TestTemplateLevelEngines 测试模板的级别只作用于模板列出的引擎：导出 postgresql-prod 时 MySQL 规则保持基础级别
+func TestTemplateLevelEngines(t *testing.T) {
+	review := &ReviewConfig{}
+	base, err := review.Rules.Build(mysql.DefaultRules())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rules, err := review.PolicyRules(PolicyConfig{Extends: "postgresql-prod"}, mysql.DefaultRules())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	exported, err := ExportRules(rules)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(rules) != len(base) {
+		t.Fatalf("Expected %d rules, got %d", len(base), len(rules))
+	}
+	for _, rule := range base {
+		if level := exported["mysql"][string(rule.Type)].Level; level != string(rule.Level) {
+			t.Errorf("Expected %s exported at %s, got %s", rule.Type, rule.Level, level)
+		}
+	}
+
+	// 策略自己设置的级别仍然作用于所有引擎
+	rules, err = review.PolicyRules(PolicyConfig{Extends: "postgresql-prod", Level: "INFO"}, mysql.DefaultRules())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, rule := range rules {
+		if rule.Level != advisor.LevelInfo {
+			t.Errorf("Expected %s at INFO, got %s", rule.Type, rule.Level)
+		}
+	}
+}
+
+// TestExportRules 测试导出的完整规则配置作为策略使用时得到相同的规则
+func TestExportRules(t *testing.T) {
+	disabled := false
+	review := &ReviewConfig{
+		Policies: map[string]PolicyConfig{
+			"dev": {
+				Extends: "mysql-dev",
+				Rules:   RulesConfig{"mysql": {"mysql.table.engine": {Enabled: &disabled}}},
+			},
+		},
+	}
+	rules, err := review.PolicyRules(review.Policies["dev"], mysql.DefaultRules())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	exported, err := ExportRules(rules)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if engine := exported["mysql"]["mysql.table.engine"]; engine.Enabled == nil || *engine.Enabled {
+		t.Errorf("Expected the table engine rule to be exported as disabled, got %+v", engine)
+	}
+	if options := exported["mysql"]["mysql.naming.convention"].Payload; options["max_length"] != float64(64) {
+		t.Errorf("Expected the default options to be exported, got %v", options)
+	}
+
+	reimported, err := review.PolicyRules(PolicyConfig{Rules: exported}, mysql.DefaultRules())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(reimported) != len(rules) {
+		t.Fatalf("Expected %d rules, got %d", len(rules), len(reimported))
+	}
+	for i := range rules {
+		if reimported[i].Type != rules[i].Type || reimported[i].Level != rules[i].Level {
+			t.Errorf("Expected %s at %s, got %s at %s", rules[i].Type, rules[i].Level, reimported[i].Type, reimported[i].Level)
+		}
+		expected, err := advisor.DecodePayload(advisor.MySQL, rules[i].Type, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rules[i].Payload != nil {
+			expected = rules[i].Payload
+		}
+		if reimported[i].Payload != nil && !reflect.DeepEqual(reimported[i].Payload, expected) {
+			t.Errorf("Expected payload %+v of %s, got %+v", expected, rules[i].Type, reimported[i].Payload)
+		}
+	}
+}
@@ -19,6 +19,7 @@ type ConnectionConfig struct {
 	Username string `json:"username" yaml:"username"`
 	Password string `json:"password" yaml:"password"`
 	Engine   string `json:"engine" yaml:"engine"` // mysql, postgresql
+	Policy   string `json:"policy,omitempty" yaml:"policy"` // 审查策略名，为空时按数据库名和环境选择
 }
 
 // DatabaseManager 数据库连接管理器
@@ -13,6 +13,8 @@ type Table struct {
 	Comment string   `json:"comment"`
 	Columns []Column `json:"columns"`
 	Indexes []Index  `json:"indexes"`
+	// RowCount 估算行数（information_schema.TABLES.TABLE_ROWS），0 表示空表或未知
+	RowCount int64 `json:"row_count"`
 }
 
 // Column 列信息
@@ -83,7 +85,7 @@ func (sm *SchemaManager) GetTables(databaseName string) ([]Table, error) {
 // getMySQLTables 获取MySQL表信息
 func (sm *SchemaManager) getMySQLTables(databaseName string) ([]Table, error) {
 	query := `
-		SELECT TABLE_NAME, ENGINE, TABLE_COMMENT
+		SELECT TABLE_NAME, IFNULL(ENGINE, ''), TABLE_COMMENT, IFNULL(TABLE_ROWS, 0)
 		FROM information_schema.TABLES
 		WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'
 		ORDER BY TABLE_NAME`
@@ -97,7 +99,7 @@ func (sm *SchemaManager) getMySQLTables(databaseName string) ([]Table, error) {
 	var tables []Table
 	for rows.Next() {
 		var table Table
-		if err := rows.Scan(&table.Name, &table.Engine, &table.Comment); err != nil {
+		if err := rows.Scan(&table.Name, &table.Engine, &table.Comment, &table.RowCount); err != nil {
 			return nil, err
 		}
 
@@ -0,0 +1,414 @@
+// Package mysql wraps the ANTLR-generated MySQL grammar so that review rules
+// can walk a parse tree instead of matching the raw SQL text.
+package mysql
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/antlr4-go/antlr/v4"
+	mysql "github.com/bytebase/parser/mysql"
+)
+
+// ParseResult is the parse tree of a single statement.
+type ParseResult struct {
+	// Tree is the root node, a *mysql.ScriptContext.
+	Tree antlr.ParseTree
+	// Tokens is the token stream the tree was built from.
+	Tokens *antlr.CommonTokenStream
+	// Text is the statement text without its delimiter.
+	Text string
+	// ByteOffset is the byte offset of the statement in the script.
+	ByteOffset int
+	// BaseLine is the number of lines in the script before the statement.
+	// Token lines are relative to the statement, so rules add BaseLine to
+	// them. Token columns are already relative to the script.
+	BaseLine int
+}
+
+// SyntaxError is the first syntax error reported by the lexer or parser.
+type SyntaxError struct {
+	// Line is the 1-based line of the offending token.
+	Line int
+	// Column is the 1-based column of the offending token.
+	Column int
+	// Token is the text of the offending token, empty at end of input.
+	Token string
+	// Message is the message produced by ANTLR, e.g. the expected tokens.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// SyntaxErrors holds one SyntaxError for each statement that failed to parse.
+type SyntaxErrors []*SyntaxError
+
+// Error implements the error interface.
+func (e SyntaxErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ParseMySQL splits the script into statements and parses each of them.
+//
+// A statement that does not match the grammar does not stop the others: the
+// parse results of the valid statements are returned together with a
+// SyntaxErrors error, positioned in the script, for the invalid ones.
+func ParseMySQL(statement string) ([]*ParseResult, error) {
+	list, err := SplitSQL(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*ParseResult
+	var syntaxErrors SyntaxErrors
+	for _, single := range list {
+		result, err := parseSingle(single)
+		if err != nil {
+			syntaxErr, ok := err.(*SyntaxError)
+			if !ok {
+				return nil, err
+			}
+			syntaxErrors = append(syntaxErrors, syntaxErr)
+			continue
+		}
+		results = append(results, result)
+	}
+
+	if len(syntaxErrors) > 0 {
+		return results, syntaxErrors
+	}
+	return results, nil
+}
+
+// parseSingle parses one statement. It first tries the faster SLL
+// prediction mode and only falls back to full LL when SLL reports an error,
+// which keeps the error message identical to a plain LL parse.
+func parseSingle(single *SingleSQL) (*ParseResult, error) {
+	// 用空格补齐首行的列偏移，使 token 的列号与脚本中的列号一致；
+	// 每个 query 都必须以分号结尾，补齐语句的分号
+	body := strings.Repeat(" ", single.StartColumn-1) + strings.TrimRight(single.Text, " \t\r\n\f;")
+	text := body + "\n;"
+	baseLine := single.StartLine - 1
+
+	result, err := parse(text, antlr.PredictionModeSLL)
+	if err != nil {
+		result, err = parse(text, antlr.PredictionModeLL)
+	}
+	if err != nil {
+		if syntaxErr, ok := err.(*SyntaxError); ok {
+			// 报在补齐的分号上的错误，实际是语句意外结束
+			lastLine := strings.Count(body, "\n") + 1
+			if syntaxErr.Line > lastLine {
+				syntaxErr.Line = lastLine
+				syntaxErr.Column = utf8.RuneCountInString(body[strings.LastIndex(body, "\n")+1:]) + 1
+				syntaxErr.Token = ""
+			}
+			syntaxErr.Line += baseLine
+		}
+		return nil, err
+	}
+
+	result.Text = single.Text
+	result.ByteOffset = single.ByteOffset
+	result.BaseLine = baseLine
+	return result, nil
+}
+
+func parse(statement string, mode int) (*ParseResult, error) {
+	input := antlr.NewInputStream(statement)
+	lexer := mysql.NewMySQLLexer(input)
+	stream := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	p := mysql.NewMySQLParser(stream)
+	p.Interpreter.SetPredictionMode(mode)
+
+	errorListener := &syntaxErrorListener{}
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(errorListener)
+	p.RemoveErrorListeners()
+	p.AddErrorListener(errorListener)
+	p.BuildParseTrees = true
+
+	tree := p.Script()
+	if errorListener.err != nil {
+		return nil, errorListener.err
+	}
+
+	return &ParseResult{
+		Tree:   tree,
+		Tokens: stream,
+	}, nil
+}
+
+// syntaxErrorListener keeps the first error reported by the lexer or parser.
+type syntaxErrorListener struct {
+	*antlr.DefaultErrorListener
+	err *SyntaxError
+}
+
+// SyntaxError implements antlr.ErrorListener.
+func (l *syntaxErrorListener) SyntaxError(_ antlr.Recognizer, offendingSymbol any, line, column int, msg string, _ antlr.RecognitionException) {
+	if l.err != nil {
+		return
+	}
+
+	var tokenText string
+	if token, ok := offendingSymbol.(antlr.Token); ok && token.GetTokenType() != antlr.TokenEOF {
+		tokenText = token.GetText()
+	}
+
+	l.err = &SyntaxError{
+		Line:    line,
+		Column:  column + 1,
+		Token:   tokenText,
+		Message: shortenExpecting(msg),
+	}
+}
+
+// maxExpectedTokens is the number of expected tokens kept in a message.
+const maxExpectedTokens = 8
+
+// shortenExpecting makes the "expecting {...}" part of an ANTLR message
+// readable: MySQL statements often allow hundreds of tokens at a position,
+// and the token names carry a _SYMBOL suffix from the grammar.
+func shortenExpecting(msg string) string {
+	const marker = "expecting {"
+	start := strings.Index(msg, marker)
+	if start < 0 {
+		return strings.ReplaceAll(msg, "_SYMBOL", "")
+	}
+	end := strings.LastIndex(msg, "}")
+	if end < start {
+		return strings.ReplaceAll(msg, "_SYMBOL", "")
+	}
+
+	tokens := strings.Split(msg[start+len(marker):end], ", ")
+	for i, token := range tokens {
+		tokens[i] = strings.TrimSuffix(token, "_SYMBOL")
+	}
+	expected := strings.Join(tokens, ", ")
+	if len(tokens) > maxExpectedTokens {
+		expected = fmt.Sprintf("%s, ... (%d more)", strings.Join(tokens[:maxExpectedTokens], ", "), len(tokens)-maxExpectedTokens)
+	}
+	return strings.ReplaceAll(msg[:start], "_SYMBOL", "") + marker + expected + msg[end:]
+}
+
+// NormalizeStatement returns the tokens of a statement in lower case,
+// separated by single spaces and without comments, so that reformatting a
+// statement does not change its normalized text.
+func NormalizeStatement(statement string) string {
+	lexer := mysql.NewMySQLLexer(antlr.NewInputStream(statement))
+	lexer.RemoveErrorListeners()
+
+	var tokens []string
+	for token := lexer.NextToken(); token.GetTokenType() != antlr.TokenEOF; token = lexer.NextToken() {
+		if token.GetChannel() != antlr.TokenDefaultChannel {
+			continue
+		}
+		tokens = append(tokens, strings.ToLower(token.GetText()))
+	}
+	return strings.Join(tokens, " ")
+}
+
+// NormalizeMySQLIdentifier returns the identifier name without quotes.
+func NormalizeMySQLIdentifier(ctx mysql.IIdentifierContext) string {
+	if ctx == nil {
+		return ""
+	}
+	return unquoteIdentifier(ctx.GetText())
+}
+
+// NormalizeMySQLTableName returns the database and table name of a table
+// name node. The database name is empty when the name is not qualified.
+func NormalizeMySQLTableName(ctx mysql.ITableNameContext) (string, string) {
+	if ctx == nil {
+		return "", ""
+	}
+	if ctx.QualifiedIdentifier() != nil {
+		return normalizeQualifiedIdentifier(ctx.QualifiedIdentifier())
+	}
+	if ctx.DotIdentifier() != nil {
+		return "", NormalizeMySQLIdentifier(ctx.DotIdentifier().Identifier())
+	}
+	return "", ""
+}
+
+// NormalizeMySQLTableRef returns the database and table name of a table
+// reference node.
+func NormalizeMySQLTableRef(ctx mysql.ITableRefContext) (string, string) {
+	if ctx == nil {
+		return "", ""
+	}
+	if ctx.QualifiedIdentifier() != nil {
+		return normalizeQualifiedIdentifier(ctx.QualifiedIdentifier())
+	}
+	if ctx.DotIdentifier() != nil {
+		return "", NormalizeMySQLIdentifier(ctx.DotIdentifier().Identifier())
+	}
+	return "", ""
+}
+
+// NormalizeMySQLColumnName returns the column name of a column name node.
+// Qualified names such as t.c return the last part.
+func NormalizeMySQLColumnName(ctx mysql.IColumnNameContext) string {
+	if ctx == nil {
+		return ""
+	}
+	if ctx.Identifier() != nil {
+		return NormalizeMySQLIdentifier(ctx.Identifier())
+	}
+	return NormalizeMySQLFieldIdentifier(ctx.FieldIdentifier())
+}
+
+// NormalizeMySQLFieldIdentifier returns the last part of a possibly
+// qualified field identifier.
+func NormalizeMySQLFieldIdentifier(ctx mysql.IFieldIdentifierContext) string {
+	if ctx == nil {
+		return ""
+	}
+	if ctx.DotIdentifier() != nil {
+		return NormalizeMySQLIdentifier(ctx.DotIdentifier().Identifier())
+	}
+	if ctx.QualifiedIdentifier() != nil {
+		_, name := normalizeQualifiedIdentifier(ctx.QualifiedIdentifier())
+		return name
+	}
+	return ""
+}
+
+// NormalizeMySQLColumnRef returns the database, table and column name of a
+// column reference. The database and table names are empty when the
+// reference is not qualified.
+func NormalizeMySQLColumnRef(ctx mysql.IColumnRefContext) (string, string, string) {
+	if ctx == nil || ctx.FieldIdentifier() == nil {
+		return "", "", ""
+	}
+	field := ctx.FieldIdentifier()
+	if field.QualifiedIdentifier() == nil {
+		return "", "", NormalizeMySQLFieldIdentifier(field)
+	}
+	first, second := normalizeQualifiedIdentifier(field.QualifiedIdentifier())
+	if field.DotIdentifier() != nil {
+		return first, second, NormalizeMySQLIdentifier(field.DotIdentifier().Identifier())
+	}
+	return "", first, second
+}
+
+// GetOriginalText returns the source text of a node, including the
+// whitespace and comments between its tokens.
+func GetOriginalText(ctx antlr.ParserRuleContext) string {
+	start, stop := ctx.GetStart(), ctx.GetStop()
+	if start == nil || stop == nil || stop.GetStop() < start.GetStart() {
+		return ""
+	}
+	return start.GetInputStream().GetTextFromInterval(antlr.NewInterval(start.GetStart(), stop.GetStop()))
+}
+
+// NormalizeMySQLKeyListVariants returns the column names of an index key
+// list. Expression key parts have no column name and are skipped.
+func NormalizeMySQLKeyListVariants(ctx mysql.IKeyListVariantsContext) []string {
+	if ctx == nil {
+		return nil
+	}
+	var columns []string
+	if ctx.KeyList() != nil {
+		for _, keyPart := range ctx.KeyList().AllKeyPart() {
+			columns = append(columns, NormalizeMySQLIdentifier(keyPart.Identifier()))
+		}
+	}
+	if ctx.KeyListWithExpression() != nil {
+		for _, keyPart := range ctx.KeyListWithExpression().AllKeyPartOrExpression() {
+			if keyPart.KeyPart() != nil {
+				columns = append(columns, NormalizeMySQLIdentifier(keyPart.KeyPart().Identifier()))
+			}
+		}
+	}
+	return columns
+}
+
+// NormalizeMySQLIdentifierList returns the names of an identifier list.
+func NormalizeMySQLIdentifierList(ctx mysql.IIdentifierListContext) []string {
+	if ctx == nil {
+		return nil
+	}
+	var names []string
+	for _, identifier := range ctx.AllIdentifier() {
+		names = append(names, NormalizeMySQLIdentifier(identifier))
+	}
+	return names
+}
+
+// NormalizeMySQLTextLiteral returns the value of a string literal, with the
+// quotes stripped and adjacent strings concatenated. Doubled quotes are
+// collapsed, other escape sequences are kept as written.
+func NormalizeMySQLTextLiteral(ctx mysql.ITextLiteralContext) string {
+	if ctx == nil {
+		return ""
+	}
+	var value strings.Builder
+	if ctx.NCHAR_TEXT() != nil {
+		// N'text'
+		value.WriteString(unquoteString(ctx.NCHAR_TEXT().GetText()[1:]))
+	}
+	for _, text := range ctx.AllTextStringLiteral() {
+		value.WriteString(unquoteString(text.GetText()))
+	}
+	return value.String()
+}
+
+// NormalizeMySQLTextStringLiteral returns the value of a single string,
+// such as a table COMMENT.
+func NormalizeMySQLTextStringLiteral(ctx mysql.ITextStringLiteralContext) string {
+	if ctx == nil {
+		return ""
+	}
+	return unquoteString(ctx.GetText())
+}
+
+// NormalizeMySQLTextOrIdentifier returns the value of a name that may be
+// written as an identifier or a string, such as an engine or charset name.
+func NormalizeMySQLTextOrIdentifier(ctx mysql.ITextOrIdentifierContext) string {
+	if ctx == nil {
+		return ""
+	}
+	if ctx.Identifier() != nil {
+		return NormalizeMySQLIdentifier(ctx.Identifier())
+	}
+	return NormalizeMySQLTextStringLiteral(ctx.TextStringLiteral())
+}
+
+func normalizeQualifiedIdentifier(ctx mysql.IQualifiedIdentifierContext) (string, string) {
+	name := NormalizeMySQLIdentifier(ctx.Identifier())
+	if ctx.DotIdentifier() != nil {
+		return name, NormalizeMySQLIdentifier(ctx.DotIdentifier().Identifier())
+	}
+	return "", name
+}
+
+// unquoteString strips single or double quotes and collapses doubled
+// quotes.
+func unquoteString(text string) string {
+	if len(text) < 2 {
+		return text
+	}
+	quote := text[:1]
+	if (quote != "'" && quote != `"`) || !strings.HasSuffix(text, quote) {
+		return text
+	}
+	return strings.ReplaceAll(text[1:len(text)-1], quote+quote, quote)
+}
+
+// unquoteIdentifier strips backticks and collapses doubled backticks.
+func unquoteIdentifier(text string) string {
+	if len(text) >= 2 && strings.HasPrefix(text, "`") && strings.HasSuffix(text, "`") {
+		return strings.ReplaceAll(text[1:len(text)-1], "``", "`")
+	}
+	return text
+}
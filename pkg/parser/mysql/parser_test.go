@@ -0,0 +1,104 @@
+package mysql
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestParseMySQL 测试MySQL语句解析
+func TestParseMySQL(t *testing.T) {
+	tests := []struct {
+		name        string
+		statement   string
+		expectError bool
+		errorLine   int
+		errorColumn int
+		errorToken  string
+	}{
+		{
+			name:      "Create table",
+			statement: "CREATE TABLE t (id INT PRIMARY KEY, c VARCHAR(10) COMMENT 'PRIMARY KEY');",
+		},
+		{
+			name:      "Missing trailing semicolon",
+			statement: "SELECT 1",
+		},
+		{
+			name:      "Multiple statements",
+			statement: "CREATE TABLE t (id INT);\nINSERT INTO t VALUES (1);\nSELECT * FROM t;",
+		},
+		{
+			name:        "Syntax error",
+			statement:   "SELECT 1;\nCREATE TABLEE t (id INT);",
+			expectError: true,
+			errorLine:   2,
+			errorColumn: 8,
+			errorToken:  "TABLEE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := ParseMySQL(tt.statement)
+
+			if !tt.expectError {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(results) == 0 || results[0].Tree == nil {
+					t.Fatalf("Expected a parse tree, got %v", results)
+				}
+				return
+			}
+
+			var syntaxErrors SyntaxErrors
+			if !errors.As(err, &syntaxErrors) || len(syntaxErrors) != 1 {
+				t.Fatalf("Expected one syntax error, got %v", err)
+			}
+			syntaxErr := syntaxErrors[0]
+			if syntaxErr.Line != tt.errorLine || syntaxErr.Column != tt.errorColumn {
+				t.Errorf("Expected error at %d:%d, got %d:%d", tt.errorLine, tt.errorColumn, syntaxErr.Line, syntaxErr.Column)
+			}
+			if syntaxErr.Token != tt.errorToken {
+				t.Errorf("Expected offending token %q, got %q", tt.errorToken, syntaxErr.Token)
+			}
+		})
+	}
+}
+
+// TestParseMySQLContinuesAfterSyntaxError 测试语法错误不影响其他语句的解析
+func TestParseMySQLContinuesAfterSyntaxError(t *testing.T) {
+	script := "SELEC 1;\nCREATE TABLE t (id INT PRIMARY KEY);\nUPDATE t SET;"
+
+	results, err := ParseMySQL(script)
+
+	var syntaxErrors SyntaxErrors
+	if !errors.As(err, &syntaxErrors) {
+		t.Fatalf("Expected SyntaxErrors, got %v", err)
+	}
+	if len(syntaxErrors) != 2 {
+		t.Fatalf("Expected 2 syntax errors, got %d: %v", len(syntaxErrors), syntaxErrors)
+	}
+	if syntaxErrors[0].Line != 1 || syntaxErrors[0].Token != "SELEC" {
+		t.Errorf("Unexpected first error: %+v", syntaxErrors[0])
+	}
+	if syntaxErrors[1].Line != 3 || syntaxErrors[1].Column != 13 || syntaxErrors[1].Token != "" {
+		t.Errorf("Unexpected second error: %+v", syntaxErrors[1])
+	}
+	if len(results) != 1 || results[0].BaseLine != 1 {
+		t.Errorf("Expected the CREATE TABLE statement to be parsed, got %v", results)
+	}
+}
+
+// TestNormalizeStatement 测试格式不同的同一语句规范化后相同
+func TestNormalizeStatement(t *testing.T) {
+	want := "create table t ( id int primary key , name varchar ( 50 ) )"
+	for _, statement := range []string{
+		"CREATE TABLE t (id INT PRIMARY KEY, name VARCHAR(50))",
+		"create table t (\n  id   INT  PRIMARY KEY, -- key\n  name VARCHAR(50) /* name */\n)",
+	} {
+		if got := NormalizeStatement(statement); got != want {
+			t.Errorf("NormalizeStatement(%q) = %q, expected %q", statement, got, want)
+		}
+	}
+}
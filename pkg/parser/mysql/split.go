@@ -0,0 +1,268 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultDelimiter is the statement delimiter used until a DELIMITER command
+// changes it.
+const defaultDelimiter = ";"
+
+// SingleSQL is one statement split out of a script.
+type SingleSQL struct {
+	// Text is the statement text without its delimiter. It starts at the
+	// first character of the statement, so leading comments are not included.
+	Text string
+	// ByteOffset is the byte offset of Text in the script.
+	ByteOffset int
+	// StartLine is the 1-based line of the first character of Text.
+	StartLine int
+	// StartColumn is the 1-based column of the first character of Text,
+	// counted in characters like the columns reported by the parser.
+	StartColumn int
+}
+
+// Comment is a comment of a script.
+type Comment struct {
+	// Text is the comment including its markers, e.g. "-- note" or
+	// "/* note */".
+	Text string
+	// Line is the 1-based line the comment starts on.
+	Line int
+	// Column is the 1-based column the comment starts at.
+	Column int
+	// EndLine is the 1-based line the comment ends on.
+	EndLine int
+}
+
+// SplitSQL splits a script into statements.
+//
+// It understands quoted strings, backtick identifiers, the three MySQL
+// comment styles and the client-side DELIMITER command, so a procedure body
+// written between "DELIMITER $$" and "DELIMITER ;" stays in one statement.
+// DELIMITER commands and empty statements are not returned.
+func SplitSQL(statement string) ([]*SingleSQL, error) {
+	s := &splitter{
+		text:      statement,
+		line:      1,
+		column:    1,
+		delimiter: defaultDelimiter,
+	}
+	return s.split()
+}
+
+// SplitComments returns the comments of a script in order, both those
+// between statements and those inside them. Executable comments such as
+// /*!40101 SET NAMES utf8 */ and comment markers inside quoted strings are
+// not comments.
+func SplitComments(statement string) ([]*Comment, error) {
+	s := &splitter{
+		text:      statement,
+		line:      1,
+		column:    1,
+		delimiter: defaultDelimiter,
+	}
+	if _, err := s.split(); err != nil {
+		return nil, err
+	}
+	return s.comments, nil
+}
+
+// splitter is a small scanner that tracks the line and column of each byte.
+type splitter struct {
+	text      string
+	pos       int
+	line      int
+	column    int
+	delimiter string
+	result    []*SingleSQL
+	comments  []*Comment
+}
+
+func (s *splitter) split() ([]*SingleSQL, error) {
+	for {
+		s.skipSpaceAndComments()
+		if s.pos >= len(s.text) {
+			return s.result, nil
+		}
+
+		if isDelimiterCommand(s.text[s.pos:]) {
+			if err := s.readDelimiterCommand(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		s.readStatement()
+	}
+}
+
+// readStatement consumes one statement and its delimiter.
+func (s *splitter) readStatement() {
+	start, line, column := s.pos, s.line, s.column
+	end := len(s.text)
+
+	for s.pos < len(s.text) {
+		if strings.HasPrefix(s.text[s.pos:], s.delimiter) {
+			end = s.pos
+			s.advance(len(s.delimiter))
+			break
+		}
+
+		switch c := s.text[s.pos]; {
+		case c == '\'' || c == '"' || c == '`':
+			s.skipQuoted(c)
+		case s.atLineComment():
+			s.skipLine()
+		case strings.HasPrefix(s.text[s.pos:], "/*"):
+			s.skipBlockComment()
+		default:
+			s.advance(1)
+		}
+	}
+
+	text := strings.TrimRight(s.text[start:end], " \t\r\n\f")
+	if text == "" {
+		return
+	}
+	s.result = append(s.result, &SingleSQL{
+		Text:        text,
+		ByteOffset:  start,
+		StartLine:   line,
+		StartColumn: column,
+	})
+}
+
+// readDelimiterCommand handles "DELIMITER <token>", which runs until the end
+// of the line.
+func (s *splitter) readDelimiterCommand() error {
+	line := s.line
+	end := strings.IndexByte(s.text[s.pos:], '\n')
+	if end < 0 {
+		end = len(s.text) - s.pos
+	}
+	fields := strings.Fields(s.text[s.pos : s.pos+end])
+	if len(fields) < 2 {
+		return fmt.Errorf("DELIMITER command at line %d requires a delimiter", line)
+	}
+	s.delimiter = fields[1]
+	s.advance(end)
+	return nil
+}
+
+// skipSpaceAndComments skips everything before the next statement. Executable
+// comments such as /*!40101 SET NAMES utf8 */ are statements, not comments.
+func (s *splitter) skipSpaceAndComments() {
+	for s.pos < len(s.text) {
+		switch c := s.text[s.pos]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f':
+			s.advance(1)
+		case s.atLineComment():
+			s.skipLine()
+		case strings.HasPrefix(s.text[s.pos:], "/*") && !strings.HasPrefix(s.text[s.pos:], "/*!"):
+			s.skipBlockComment()
+		default:
+			return
+		}
+	}
+}
+
+// atLineComment reports whether a "#" or "-- " comment starts at pos.
+// MySQL only treats "--" as a comment when a space or control character
+// follows it.
+func (s *splitter) atLineComment() bool {
+	rest := s.text[s.pos:]
+	if strings.HasPrefix(rest, "#") {
+		return true
+	}
+	if !strings.HasPrefix(rest, "--") {
+		return false
+	}
+	return len(rest) == 2 || rest[2] <= ' '
+}
+
+// skipLine skips a line comment up to, but not including, the next newline.
+func (s *splitter) skipLine() {
+	end := strings.IndexByte(s.text[s.pos:], '\n')
+	if end < 0 {
+		end = len(s.text) - s.pos
+	}
+	s.skipComment(end)
+}
+
+// skipBlockComment skips a /* ... */ comment. An unterminated comment runs to
+// the end of the script and is left for the parser to report.
+func (s *splitter) skipBlockComment() {
+	end := strings.Index(s.text[s.pos+2:], "*/")
+	if end < 0 {
+		s.skipComment(len(s.text) - s.pos)
+		return
+	}
+	s.skipComment(end + 4)
+}
+
+// skipComment skips the n bytes of a comment and records it unless it is
+// an executable comment.
+func (s *splitter) skipComment(n int) {
+	comment := &Comment{
+		Text:   strings.TrimRight(s.text[s.pos:s.pos+n], " \t\r\f"),
+		Line:   s.line,
+		Column: s.column,
+	}
+	s.advance(n)
+	if strings.HasPrefix(comment.Text, "/*!") {
+		return
+	}
+	comment.EndLine = s.line
+	s.comments = append(s.comments, comment)
+}
+
+// skipQuoted skips a string or identifier quoted with quote. A doubled quote
+// stands for the quote itself, and strings also accept backslash escapes.
+func (s *splitter) skipQuoted(quote byte) {
+	s.advance(1)
+	for s.pos < len(s.text) {
+		c := s.text[s.pos]
+		switch {
+		case c == '\\' && quote != '`' && s.pos+1 < len(s.text):
+			s.advance(2)
+		case c == quote:
+			if s.pos+1 < len(s.text) && s.text[s.pos+1] == quote {
+				s.advance(2)
+				continue
+			}
+			s.advance(1)
+			return
+		default:
+			s.advance(1)
+		}
+	}
+}
+
+// advance moves pos forward by n bytes and keeps line and column in sync.
+func (s *splitter) advance(n int) {
+	end := s.pos + n
+	for s.pos < end {
+		r, size := utf8.DecodeRuneInString(s.text[s.pos:])
+		if r == '\n' {
+			s.line++
+			s.column = 1
+		} else {
+			s.column++
+		}
+		s.pos += size
+	}
+}
+
+// isDelimiterCommand reports whether text starts with the DELIMITER client
+// command.
+func isDelimiterCommand(text string) bool {
+	const keyword = "DELIMITER"
+	if len(text) <= len(keyword) || !strings.EqualFold(text[:len(keyword)], keyword) {
+		return false
+	}
+	c := text[len(keyword)]
+	return c == ' ' || c == '\t'
+}
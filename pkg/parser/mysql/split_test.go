@@ -0,0 +1,155 @@
+package mysql
+
+import (
+	"testing"
+)
+
+// TestSplitSQL 测试脚本拆分及每条语句的起始位置
+func TestSplitSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []SingleSQL
+	}{
+		{
+			name:   "Simple statements",
+			script: "SELECT 1;\nSELECT 2;",
+			want: []SingleSQL{
+				{Text: "SELECT 1", ByteOffset: 0, StartLine: 1, StartColumn: 1},
+				{Text: "SELECT 2", ByteOffset: 10, StartLine: 2, StartColumn: 1},
+			},
+		},
+		{
+			name:   "Statements on the same line",
+			script: "SELECT 1;  SELECT 2",
+			want: []SingleSQL{
+				{Text: "SELECT 1", ByteOffset: 0, StartLine: 1, StartColumn: 1},
+				{Text: "SELECT 2", ByteOffset: 11, StartLine: 1, StartColumn: 12},
+			},
+		},
+		{
+			name:   "Comments are skipped",
+			script: "-- first; comment\n# second; comment\n/* block; comment */ SELECT 1; -- trailing",
+			want: []SingleSQL{
+				{Text: "SELECT 1", ByteOffset: 57, StartLine: 3, StartColumn: 22},
+			},
+		},
+		{
+			name:   "Delimiters inside quotes",
+			script: "INSERT INTO t VALUES ('a;b', \"c;d\", 'it''s;', 'x\\';y');\nSELECT `semi;colon` FROM t;",
+			want: []SingleSQL{
+				{Text: "INSERT INTO t VALUES ('a;b', \"c;d\", 'it''s;', 'x\\';y')", ByteOffset: 0, StartLine: 1, StartColumn: 1},
+				{Text: "SELECT `semi;colon` FROM t", ByteOffset: 56, StartLine: 2, StartColumn: 1},
+			},
+		},
+		{
+			name:   "Multi-byte characters count as one column",
+			script: "SELECT '中文';SELECT 2;",
+			want: []SingleSQL{
+				{Text: "SELECT '中文'", ByteOffset: 0, StartLine: 1, StartColumn: 1},
+				{Text: "SELECT 2", ByteOffset: 16, StartLine: 1, StartColumn: 13},
+			},
+		},
+		{
+			name: "DELIMITER command",
+			script: "DELIMITER $$\n" +
+				"CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\nEND$$\n" +
+				"DELIMITER ;\n" +
+				"SELECT 2;",
+			want: []SingleSQL{
+				{Text: "CREATE PROCEDURE p()\nBEGIN\n  SELECT 1;\nEND", ByteOffset: 13, StartLine: 2, StartColumn: 1},
+				{Text: "SELECT 2", ByteOffset: 70, StartLine: 7, StartColumn: 1},
+			},
+		},
+		{
+			name:   "Empty statements",
+			script: ";;\n  ;\n",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitSQL(tt.script)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d statements, got %d: %+v", len(tt.want), len(got), got)
+			}
+			for i, want := range tt.want {
+				if *got[i] != want {
+					t.Errorf("Statement %d: expected %+v, got %+v", i, want, *got[i])
+				}
+			}
+		})
+	}
+}
+
+// TestSplitComments 测试注释及其位置的提取
+func TestSplitComments(t *testing.T) {
+	script := "-- header\nSELECT '-- not a comment', 1; # trailing\n/*!40101 SET NAMES utf8 */;\nCREATE TABLE t (\n  id INT /* multi\n  line */\n);"
+
+	got, err := SplitComments(script)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []Comment{
+		{Text: "-- header", Line: 1, Column: 1, EndLine: 1},
+		{Text: "# trailing", Line: 2, Column: 31, EndLine: 2},
+		{Text: "/* multi\n  line */", Line: 5, Column: 10, EndLine: 6},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d comments, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if *got[i] != want[i] {
+			t.Errorf("Comment %d: expected %+v, got %+v", i, want[i], *got[i])
+		}
+	}
+}
+
+// TestParseMySQLPositions 测试拆分后语法树中的位置与脚本一致
+func TestParseMySQLPositions(t *testing.T) {
+	script := "-- header\nSELECT 1;  CREATE TABLE t (id INT);\n\nDELIMITER //\nCREATE PROCEDURE p() BEGIN SELECT 1; END//\nDELIMITER ;\nSELECT 2"
+
+	results, err := ParseMySQL(script)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []struct {
+		line   int
+		column int
+	}{
+		{line: 2, column: 1},
+		{line: 2, column: 12},
+		{line: 5, column: 1},
+		{line: 7, column: 1},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d statements, got %d", len(want), len(results))
+	}
+	for i, result := range results {
+		// 第一个 token 是语句的起始位置
+		token := result.Tokens.Get(0)
+		for token.GetChannel() != 0 {
+			token = result.Tokens.Get(token.GetTokenIndex() + 1)
+		}
+		line := result.BaseLine + token.GetLine()
+		column := token.GetColumn() + 1
+		if line != want[i].line || column != want[i].column {
+			t.Errorf("Statement %d: expected %d:%d, got %d:%d", i, want[i].line, want[i].column, line, column)
+		}
+	}
+
+	_, err = ParseMySQL("SELECT 1;\n\n  SELEC 2;")
+	syntaxErrors, ok := err.(SyntaxErrors)
+	if !ok || len(syntaxErrors) != 1 {
+		t.Fatalf("Expected one syntax error, got %v", err)
+	}
+	syntaxErr := syntaxErrors[0]
+	if syntaxErr.Line != 3 || syntaxErr.Column != 3 {
+		t.Errorf("Expected syntax error at 3:3, got %d:%d", syntaxErr.Line, syntaxErr.Column)
+	}
+}
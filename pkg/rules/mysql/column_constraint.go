@@ -0,0 +1,411 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/antlr4-go/antlr/v4"
+	mysql "github.com/bytebase/parser/mysql"
+	"github.com/pkg/errors"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+func init() {
+	advisor.Register(advisor.MySQL, advisor.MySQLColumnConstraint, &ColumnConstraintAdvisor{})
+}
+
+// ColumnConstraintPayload is the payload of the column constraint rule.
+type ColumnConstraintPayload struct {
+	// RequireNotNull flags nullable columns. Primary key columns are always
+	// NOT NULL.
+	RequireNotNull bool `json:"require_not_null" yaml:"require_not_null"`
+	// RequireDefault requires a DEFAULT for NOT NULL columns, except primary
+	// key, AUTO_INCREMENT and generated columns and the types that cannot
+	// have a literal default such as TEXT, BLOB and JSON.
+	RequireDefault bool `json:"require_default" yaml:"require_default"`
+	RequireComment bool `json:"require_comment" yaml:"require_comment"`
+	// MaxCommentLength caps column comments in characters, 0 disables the
+	// check.
+	MaxCommentLength int `json:"max_comment_length" yaml:"max_comment_length" validate:"min=0"`
+	// RequireAutoIncrementInteger and RequireAutoIncrementUnsigned check the
+	// type of AUTO_INCREMENT columns.
+	RequireAutoIncrementInteger  bool `json:"require_auto_increment_integer" yaml:"require_auto_increment_integer"`
+	RequireAutoIncrementUnsigned bool `json:"require_auto_increment_unsigned" yaml:"require_auto_increment_unsigned"`
+	// CreatedColumnPattern matches creation time columns, which need DEFAULT
+	// CURRENT_TIMESTAMP. UpdatedColumnPattern matches update time columns,
+	// which also need ON UPDATE CURRENT_TIMESTAMP. Only TIMESTAMP and
+	// DATETIME columns are checked, empty patterns disable the checks.
+	CreatedColumnPattern string `json:"created_column_pattern" yaml:"created_column_pattern" validate:"regexp"`
+	UpdatedColumnPattern string `json:"updated_column_pattern" yaml:"updated_column_pattern" validate:"regexp"`
+	// RequireTimeColumns requires every new table to have a creation time
+	// and an update time column.
+	RequireTimeColumns bool `json:"require_time_columns" yaml:"require_time_columns"`
+	// ForbidColumnCharset flags CHARACTER SET on a column, the table charset
+	// should be used instead.
+	ForbidColumnCharset bool `json:"forbid_column_charset" yaml:"forbid_column_charset"`
+}
+
+// DefaultColumnConstraintPayload returns the payload used for options that
+// are not configured.
+func DefaultColumnConstraintPayload() *ColumnConstraintPayload {
+	return &ColumnConstraintPayload{
+		MaxCommentLength:            256,
+		RequireAutoIncrementInteger: true,
+		CreatedColumnPattern:        "(?i)^(created_at|create_time|created_time|gmt_create)$",
+		UpdatedColumnPattern:        "(?i)^(updated_at|update_time|updated_time|gmt_modified)$",
+		ForbidColumnCharset:         true,
+	}
+}
+
+// ColumnConstraintAdvisor checks the nullability, defaults, comments and
+// other attributes of columns.
+type ColumnConstraintAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *ColumnConstraintAdvisor) Title() string {
+	return "列约束检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *ColumnConstraintAdvisor) Description() string {
+	return "检查列的 NOT NULL、DEFAULT、注释及长度、自增列类型、创建/更新时间列的 CURRENT_TIMESTAMP，以及禁止单独设置列字符集"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *ColumnConstraintAdvisor) DefaultPayload() any {
+	return DefaultColumnConstraintPayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *ColumnConstraintAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *ColumnConstraintAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultColumnConstraintPayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+
+	rule := &columnConstraintRule{
+		BaseRule: advisor.NewBaseRule(checkCtx.Rule),
+		payload:  payload,
+	}
+	var err error
+	if rule.createdColumn, err = compileOptionalPattern(payload.CreatedColumnPattern); err != nil {
+		return nil, errors.Wrapf(err, "invalid payload of rule %s", checkCtx.Rule.Type)
+	}
+	if rule.updatedColumn, err = compileOptionalPattern(payload.UpdatedColumnPattern); err != nil {
+		return nil, errors.Wrapf(err, "invalid payload of rule %s", checkCtx.Rule.Type)
+	}
+	return rule, nil
+}
+
+// columnConstraintRule checks the columns of CREATE TABLE and the columns
+// added or changed by ALTER TABLE.
+type columnConstraintRule struct {
+	advisor.BaseRule
+
+	payload       *ColumnConstraintPayload
+	createdColumn *regexp.Regexp
+	updatedColumn *regexp.Regexp
+}
+
+// columnAttributes are the column attributes the rule checks.
+type columnAttributes struct {
+	notNull       bool
+	hasDefault    bool
+	defaultNow    bool
+	onUpdateNow   bool
+	autoIncrement bool
+	primaryKey    bool
+	// hasComment is set when the column has a COMMENT, with its value in
+	// comment and its position in commentToken.
+	hasComment   bool
+	comment      string
+	commentToken antlr.Token
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *columnConstraintRule) NodeTypes() []string {
+	return []string{nodeTypeCreateTable, nodeTypeAlterListItem}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *columnConstraintRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	switch ctx := ctx.(type) {
+	case *mysql.CreateTableContext:
+		r.checkCreateTable(ctx)
+	case *mysql.AlterListItemContext:
+		r.checkAlterListItem(ctx)
+	}
+	return nil
+}
+
+func (r *columnConstraintRule) checkCreateTable(ctx *mysql.CreateTableContext) {
+	if ctx.TableElementList() == nil {
+		return
+	}
+	_, table := mysqlparser.NormalizeMySQLTableName(ctx.TableName())
+	elements := ctx.TableElementList().AllTableElement()
+
+	primaryKey := make(map[string]bool)
+	for _, element := range elements {
+		constraint := element.TableConstraintDef()
+		if constraint == nil || constraint.GetType_() == nil || constraint.GetType_().GetTokenType() != mysql.MySQLParserPRIMARY_SYMBOL {
+			continue
+		}
+		for _, column := range indexKeyColumns(constraint.KeyListVariants()) {
+			primaryKey[column] = true
+		}
+	}
+
+	hasCreated, hasUpdated := false, false
+	for _, element := range elements {
+		column := element.ColumnDefinition()
+		if column == nil {
+			continue
+		}
+		name := mysqlparser.NormalizeMySQLColumnName(column.ColumnName())
+		r.checkColumn(table, name, column.FieldDefinition(), primaryKey[strings.ToLower(name)], column.GetStart())
+		hasCreated = hasCreated || (r.createdColumn != nil && r.createdColumn.MatchString(name))
+		hasUpdated = hasUpdated || (r.updatedColumn != nil && r.updatedColumn.MatchString(name))
+	}
+
+	if !r.payload.RequireTimeColumns {
+		return
+	}
+	var missing []string
+	if r.createdColumn != nil && !hasCreated {
+		missing = append(missing, "creation time")
+	}
+	if r.updatedColumn != nil && !hasUpdated {
+		missing = append(missing, "update time")
+	}
+	if len(missing) > 0 {
+		r.AddAdvice(
+			advisor.CodeTableRequireTimeColumns,
+			"Require time columns",
+			fmt.Sprintf("Table `%s` requires %s column", table, strings.Join(missing, " and ")),
+			statementStart(ctx),
+		)
+	}
+}
+
+func (r *columnConstraintRule) checkAlterListItem(ctx *mysql.AlterListItemContext) {
+	table := enclosingTableName(ctx)
+	switch {
+	// ADD COLUMN c INT
+	case ctx.ADD_SYMBOL() != nil && ctx.Identifier() != nil && ctx.FieldDefinition() != nil:
+		r.checkColumn(table, mysqlparser.NormalizeMySQLIdentifier(ctx.Identifier()), ctx.FieldDefinition(), false, ctx.Identifier().GetStart())
+	// ADD COLUMN (c INT, ...)
+	case ctx.ADD_SYMBOL() != nil && ctx.TableElementList() != nil:
+		for _, element := range ctx.TableElementList().AllTableElement() {
+			if column := element.ColumnDefinition(); column != nil {
+				r.checkColumn(table, mysqlparser.NormalizeMySQLColumnName(column.ColumnName()), column.FieldDefinition(), false, column.GetStart())
+			}
+		}
+	// CHANGE COLUMN a c INT
+	case ctx.CHANGE_SYMBOL() != nil && ctx.Identifier() != nil:
+		r.checkColumn(table, mysqlparser.NormalizeMySQLIdentifier(ctx.Identifier()), ctx.FieldDefinition(), false, ctx.Identifier().GetStart())
+	// MODIFY COLUMN c INT
+	case ctx.MODIFY_SYMBOL() != nil && ctx.ColumnInternalRef() != nil:
+		r.checkColumn(table, mysqlparser.NormalizeMySQLIdentifier(ctx.ColumnInternalRef().Identifier()), ctx.FieldDefinition(), false, ctx.ColumnInternalRef().GetStart())
+	}
+}
+
+// checkColumn checks one column definition. primaryKey is set for columns
+// of a table-level PRIMARY KEY.
+func (r *columnConstraintRule) checkColumn(table, column string, definition mysql.IFieldDefinitionContext, primaryKey bool, token antlr.Token) {
+	if definition == nil || definition.DataType() == nil {
+		return
+	}
+	dataType := definition.DataType()
+	typeName := columnTypeName(dataType)
+	attributes := columnAttributesOf(definition)
+	primaryKey = primaryKey || attributes.primaryKey
+	generated := definition.AS_SYMBOL() != nil
+
+	if r.payload.RequireNotNull && !attributes.notNull && !primaryKey {
+		r.AddAdvice(
+			advisor.CodeColumnCannotNull,
+			"Column requires NOT NULL",
+			fmt.Sprintf("Column `%s`.`%s` should be NOT NULL", table, column),
+			token,
+		)
+	}
+
+	if r.payload.RequireDefault && attributes.notNull && !attributes.hasDefault && !primaryKey && !attributes.autoIncrement && !generated && !noLiteralDefaultTypes[typeName] {
+		r.AddAdvice(
+			advisor.CodeColumnRequireDefault,
+			"Column requires default",
+			fmt.Sprintf("NOT NULL column `%s`.`%s` requires a DEFAULT value", table, column),
+			token,
+		)
+	}
+
+	if !attributes.hasComment {
+		if r.payload.RequireComment {
+			r.AddAdvice(
+				advisor.CodeColumnRequireComment,
+				"Column requires comment",
+				fmt.Sprintf("Column `%s`.`%s` requires a COMMENT", table, column),
+				token,
+			)
+		}
+	} else if r.payload.MaxCommentLength > 0 {
+		if length := utf8.RuneCountInString(attributes.comment); length > r.payload.MaxCommentLength {
+			r.AddAdvice(
+				advisor.CodeColumnCommentTooLong,
+				"Column comment too long",
+				fmt.Sprintf("Comment of column `%s`.`%s` has %d characters, exceeding the maximum %d", table, column, length, r.payload.MaxCommentLength),
+				attributes.commentToken,
+			)
+		}
+	}
+
+	if attributes.autoIncrement {
+		if r.payload.RequireAutoIncrementInteger && !integerTypes[typeName] {
+			r.AddAdvice(
+				advisor.CodeAutoIncrementColumnInvalid,
+				"Auto-increment column type",
+				fmt.Sprintf("AUTO_INCREMENT column `%s`.`%s` should be an integer type instead of %s", table, column, strings.ToUpper(typeName)),
+				dataType.GetStart(),
+			)
+		} else if r.payload.RequireAutoIncrementUnsigned && typeName != "serial" && (dataType.FieldOptions() == nil || len(dataType.FieldOptions().AllUNSIGNED_SYMBOL()) == 0) {
+			r.AddAdvice(
+				advisor.CodeAutoIncrementColumnInvalid,
+				"Auto-increment column type",
+				fmt.Sprintf("AUTO_INCREMENT column `%s`.`%s` should be UNSIGNED", table, column),
+				dataType.GetStart(),
+			)
+		}
+	}
+
+	if typeName == "timestamp" || typeName == "datetime" {
+		switch {
+		case r.updatedColumn != nil && r.updatedColumn.MatchString(column):
+			if !attributes.defaultNow || !attributes.onUpdateNow {
+				r.addTimestampAdvice(fmt.Sprintf("Update time column `%s`.`%s` requires DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP", table, column), token)
+			}
+		case r.createdColumn != nil && r.createdColumn.MatchString(column):
+			if !attributes.defaultNow {
+				r.addTimestampAdvice(fmt.Sprintf("Creation time column `%s`.`%s` requires DEFAULT CURRENT_TIMESTAMP", table, column), token)
+			}
+		}
+	}
+
+	if r.payload.ForbidColumnCharset {
+		if charset := dataType.CharsetWithOptBinary(); charset != nil && (charset.Charset() != nil || charset.Ascii() != nil || charset.Unicode() != nil) {
+			r.AddAdvice(
+				advisor.CodeColumnCharsetDisallowed,
+				"Disallow column charset",
+				fmt.Sprintf("Column `%s`.`%s` sets its own charset, use the table charset instead", table, column),
+				charset.GetStart(),
+			)
+		}
+	}
+}
+
+func (r *columnConstraintRule) addTimestampAdvice(content string, token antlr.Token) {
+	r.AddAdvice(advisor.CodeColumnRequireCurrentTimestamp, "Time column default", content, token)
+}
+
+// columnAttributesOf collects the attributes of a column definition,
+// including the attributes of a generated column that follow AS (expr).
+func columnAttributesOf(definition mysql.IFieldDefinitionContext) *columnAttributes {
+	attributes := &columnAttributes{}
+	if definition.DataType() != nil && definition.DataType().GetType_() != nil &&
+		definition.DataType().GetType_().GetTokenType() == mysql.MySQLParserSERIAL_SYMBOL {
+		// SERIAL is BIGINT UNSIGNED NOT NULL AUTO_INCREMENT UNIQUE.
+		attributes.notNull, attributes.autoIncrement = true, true
+	}
+	for _, attribute := range definition.AllColumnAttribute() {
+		if attribute.NullLiteral() != nil {
+			attributes.notNull = attribute.NOT_SYMBOL() != nil
+			continue
+		}
+		if attribute.GetValue() == nil {
+			continue
+		}
+		switch attribute.GetValue().GetTokenType() {
+		case mysql.MySQLParserDEFAULT_SYMBOL:
+			attributes.hasDefault = true
+			attributes.defaultNow = attribute.NOW_SYMBOL() != nil
+		case mysql.MySQLParserON_SYMBOL:
+			attributes.onUpdateNow = true
+		case mysql.MySQLParserAUTO_INCREMENT_SYMBOL:
+			attributes.autoIncrement = true
+		case mysql.MySQLParserSERIAL_SYMBOL:
+			// SERIAL DEFAULT VALUE is NOT NULL AUTO_INCREMENT UNIQUE.
+			attributes.notNull, attributes.autoIncrement = true, true
+		case mysql.MySQLParserKEY_SYMBOL:
+			attributes.primaryKey = true
+		case mysql.MySQLParserCOMMENT_SYMBOL:
+			attributes.hasComment = true
+			attributes.comment = mysqlparser.NormalizeMySQLTextLiteral(attribute.TextLiteral())
+			attributes.commentToken = attribute.TextLiteral().GetStart()
+		}
+	}
+	for _, attribute := range definition.AllGcolAttribute() {
+		switch {
+		case attribute.NULL_SYMBOL() != nil:
+			attributes.notNull = attribute.NotRule() != nil
+		case attribute.COMMENT_SYMBOL() != nil:
+			attributes.hasComment = true
+			attributes.comment = mysqlparser.NormalizeMySQLTextStringLiteral(attribute.TextString().TextStringLiteral())
+			attributes.commentToken = attribute.TextString().GetStart()
+		case attribute.PRIMARY_SYMBOL() != nil || (attribute.KEY_SYMBOL() != nil && attribute.UNIQUE_SYMBOL() == nil):
+			attributes.primaryKey = true
+		}
+	}
+	return attributes
+}
+
+// compileOptionalPattern compiles a pattern, returning nil for an empty
+// pattern.
+func compileOptionalPattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+var integerTypes = map[string]bool{
+	"tinyint":   true,
+	"smallint":  true,
+	"mediumint": true,
+	"int":       true,
+	"bigint":    true,
+	"serial":    true,
+}
+
+// noLiteralDefaultTypes are the types that cannot have a literal DEFAULT.
+var noLiteralDefaultTypes = map[string]bool{
+	"tinytext":           true,
+	"text":               true,
+	"mediumtext":         true,
+	"longtext":           true,
+	"tinyblob":           true,
+	"blob":               true,
+	"mediumblob":         true,
+	"longblob":           true,
+	"json":               true,
+	"geometry":           true,
+	"geometrycollection": true,
+	"point":              true,
+	"multipoint":         true,
+	"linestring":         true,
+	"multilinestring":    true,
+	"polygon":            true,
+	"multipolygon":       true,
+}
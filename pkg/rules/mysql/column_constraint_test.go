@@ -0,0 +1,147 @@
+package mysql
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/config"
+)
+
+// TestColumnConstraint 测试列约束检查
+func TestColumnConstraint(t *testing.T) {
+	strict := map[string]any{
+		"require_not_null":                true,
+		"require_default":                 true,
+		"require_comment":                 true,
+		"require_auto_increment_unsigned": true,
+	}
+
+	tests := []struct {
+		name      string
+		payload   any
+		statement string
+		expected  []string
+	}{
+		{
+			name:      "Good columns",
+			payload:   strict,
+			statement: "CREATE TABLE t (\n  id BIGINT UNSIGNED AUTO_INCREMENT COMMENT 'id',\n  name VARCHAR(50) NOT NULL DEFAULT '' COMMENT 'name',\n  body TEXT NOT NULL COMMENT 'body',\n  PRIMARY KEY (id)\n);",
+		},
+		{
+			name: "Generated columns",
+			payload: map[string]any{
+				"require_not_null":   true,
+				"require_default":    true,
+				"require_comment":    true,
+				"max_comment_length": 20,
+			},
+			statement: "CREATE TABLE t (\n  id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'id',\n  price DECIMAL(10,2) NOT NULL DEFAULT 0 COMMENT 'price',\n  total DECIMAL(10,2) AS (price * 2) STORED NOT NULL COMMENT 'total',\n  label VARCHAR(20) GENERATED ALWAYS AS (CONCAT('p', price)) VIRTUAL NOT NULL COMMENT 'label',\n  code VARCHAR(20) AS (CONCAT('c', id)) STORED PRIMARY KEY COMMENT 'code',\n  note VARCHAR(20) AS (CONCAT('n', price)) COMMENT 'a comment that is too long'\n);",
+			expected:  []string{"7:3 905", "7:52 907"},
+		},
+		{
+			name:      "Nullable columns",
+			payload:   map[string]any{"require_not_null": true},
+			statement: "CREATE TABLE t (id INT PRIMARY KEY, a INT, b INT NULL, c INT NOT NULL);\nALTER TABLE t ADD COLUMN d INT;",
+			expected:  []string{"1:37 905", "1:44 905", "2:26 905"},
+		},
+		{
+			name:      "Missing default",
+			payload:   map[string]any{"require_default": true},
+			statement: "CREATE TABLE t (id INT NOT NULL AUTO_INCREMENT, a INT NOT NULL, b JSON NOT NULL, c INT NOT NULL DEFAULT 0, PRIMARY KEY (id));",
+			expected:  []string{"1:49 903"},
+		},
+		{
+			name:      "Column comments",
+			payload:   map[string]any{"require_comment": true, "max_comment_length": 5},
+			statement: "CREATE TABLE t (a INT COMMENT 'short', b INT COMMENT 'too long', c INT);",
+			expected:  []string{"1:54 907", "1:66 906"},
+		},
+		{
+			name:      "Auto-increment columns",
+			payload:   map[string]any{"require_auto_increment_unsigned": true},
+			statement: "CREATE TABLE a (id BIGINT AUTO_INCREMENT PRIMARY KEY);\nCREATE TABLE b (id DECIMAL(20) AUTO_INCREMENT PRIMARY KEY);\nCREATE TABLE c (id SERIAL PRIMARY KEY);",
+			expected:  []string{"1:20 908", "2:20 908"},
+		},
+		{
+			name:      "Time columns",
+			statement: "CREATE TABLE t (created_at DATETIME NOT NULL, updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, create_time BIGINT NOT NULL);\nALTER TABLE t MODIFY updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;",
+			expected:  []string{"1:17 909", "1:47 909"},
+		},
+		{
+			name:      "Require time columns",
+			payload:   map[string]any{"require_time_columns": true},
+			statement: "CREATE TABLE a (id INT PRIMARY KEY, created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP);\nCREATE TABLE b (id INT PRIMARY KEY, create_time DATETIME DEFAULT NOW(), update_time DATETIME DEFAULT NOW() ON UPDATE NOW());",
+			expected:  []string{"1:1 804"},
+		},
+		{
+			name:      "Column charset",
+			statement: "CREATE TABLE t (a VARCHAR(10) CHARACTER SET latin1, b VARCHAR(10) COLLATE utf8mb4_bin);\nALTER TABLE t ADD COLUMN c TEXT CHARSET utf8mb4;",
+			expected:  []string{"1:31 910", "2:33 910"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checkRule(t, advisor.MySQLColumnConstraint, tt.payload, tt.statement)
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
+
+// TestColumnConstraintReferenceTable 测试 mixed_examples.sql 中作为参考的 customer_accounts 表在 rules.yaml 的列约束配置下不会产生问题。
+// rules.yaml 默认只开启自增列类型、时间列和列字符集检查，NOT NULL、DEFAULT、注释和 UNSIGNED 自增列由 mysql-prod 模板开启
+func TestColumnConstraintReferenceTable(t *testing.T) {
+	content, err := os.ReadFile("../../../examples/mixed_examples.sql")
+	if err != nil {
+		t.Fatalf("Failed to read examples: %v", err)
+	}
+	statement := string(content)
+	start := strings.Index(statement, "CREATE TABLE customer_accounts")
+	end := strings.Index(statement[start:], ";")
+	if start < 0 || end < 0 {
+		t.Fatal("customer_accounts not found in mixed_examples.sql")
+	}
+
+	review, err := config.NewLoader("../../../config").LoadReview()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rules, err := review.Rules.Build(DefaultRules())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var payload any
+	for _, rule := range rules {
+		if rule.Type == advisor.MySQLColumnConstraint {
+			payload = rule.Payload
+		}
+	}
+	expected := &ColumnConstraintPayload{
+		MaxCommentLength:            256,
+		RequireAutoIncrementInteger: true,
+		CreatedColumnPattern:        "(?i)^(created_at|create_time|created_time|gmt_create)$",
+		UpdatedColumnPattern:        "(?i)^(updated_at|update_time|updated_time|gmt_modified)$",
+		ForbidColumnCharset:         true,
+	}
+	if !reflect.DeepEqual(payload, expected) {
+		t.Fatalf("Expected the column constraint payload of rules.yaml to be %+v, got %+v", expected, payload)
+	}
+
+	advices, err := advisor.SQLReviewCheck(context.Background(), rules, advisor.Context{
+		SQL:    statement[start : start+end+1],
+		Engine: advisor.MySQL,
+		Rules:  []string{string(advisor.MySQLColumnConstraint)},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, advice := range advices {
+		t.Errorf("Expected no advice for customer_accounts, got %s: %s", advice.RuleID, advice.Message)
+	}
+}
@@ -0,0 +1,397 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+	mysql "github.com/bytebase/parser/mysql"
+	"github.com/pkg/errors"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+func init() {
+	advisor.Register(advisor.MySQL, advisor.MySQLColumnTypeCheck, &ColumnTypeCheckAdvisor{})
+}
+
+// ColumnTypeCheckPayload is the payload of the column type rule. Type names
+// are lowercase MySQL type names such as "varchar" or "longtext".
+type ColumnTypeCheckPayload struct {
+	// AllowedTypes is an allowlist of column types, empty allows all types.
+	AllowedTypes   []string `json:"allowed_types" yaml:"allowed_types"`
+	ForbiddenTypes []string `json:"forbidden_types" yaml:"forbidden_types"`
+	// MaxVarcharLength and MaxCharLength cap the declared length of VARCHAR
+	// and CHAR columns, 0 disables the check.
+	MaxVarcharLength int `json:"max_varchar_length" yaml:"max_varchar_length" validate:"min=0,max=65535"`
+	MaxCharLength    int `json:"max_char_length" yaml:"max_char_length" validate:"min=0,max=255"`
+	// MoneyColumnPattern matches column names that hold money, which must
+	// not use FLOAT or DOUBLE. Empty disables the check. The default
+	// matches names ending in a money word, such as unit_price, but not
+	// names that only contain one, such as amount_unit_name.
+	MoneyColumnPattern string `json:"money_column_pattern" yaml:"money_column_pattern" validate:"regexp"`
+	// ForbidEnum flags ENUM and SET columns.
+	ForbidEnum bool `json:"forbid_enum" yaml:"forbid_enum"`
+	// CheckForeignKeyType flags foreign key columns whose type differs from
+	// the referenced column.
+	CheckForeignKeyType bool `json:"check_foreign_key_type" yaml:"check_foreign_key_type"`
+}
+
+// DefaultColumnTypeCheckPayload returns the payload used for options that
+// are not configured.
+func DefaultColumnTypeCheckPayload() *ColumnTypeCheckPayload {
+	return &ColumnTypeCheckPayload{
+		ForbiddenTypes:      []string{"text", "blob", "longtext", "longblob"},
+		MaxVarcharLength:    255,
+		MaxCharLength:       20,
+		MoneyColumnPattern:  "(?i)^(.*_)?(price|amount|money|cost|balance|fee|salary)s?$",
+		ForbidEnum:          true,
+		CheckForeignKeyType: true,
+	}
+}
+
+// ColumnTypeCheckAdvisor checks column types against the type policy.
+type ColumnTypeCheckAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *ColumnTypeCheckAdvisor) Title() string {
+	return "列类型检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *ColumnTypeCheckAdvisor) Description() string {
+	return "列类型需要符合允许/禁止的类型列表和长度限制，金额列不能使用浮点类型，不建议使用 ENUM/SET，外键列类型需要和引用列一致"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *ColumnTypeCheckAdvisor) DefaultPayload() any {
+	return DefaultColumnTypeCheckPayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *ColumnTypeCheckAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *ColumnTypeCheckAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultColumnTypeCheckPayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+
+	rule := &columnTypeCheckRule{
+		BaseRule:  advisor.NewBaseRule(checkCtx.Rule),
+		payload:   payload,
+		allowed:   toLowerSet(payload.AllowedTypes),
+		forbidden: toLowerSet(payload.ForbiddenTypes),
+		columns:   make(map[string]map[string]string),
+	}
+	if payload.MoneyColumnPattern != "" {
+		moneyColumn, err := regexp.Compile(payload.MoneyColumnPattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid payload of rule %s", checkCtx.Rule.Type)
+		}
+		rule.moneyColumn = moneyColumn
+	}
+	return rule, nil
+}
+
+// columnTypeCheckRule checks the columns of CREATE TABLE and ALTER TABLE.
+// Foreign keys are checked against the column types in the catalog and
+// the columns defined by the statement itself.
+type columnTypeCheckRule struct {
+	advisor.BaseRule
+
+	payload     *ColumnTypeCheckPayload
+	allowed     map[string]bool
+	forbidden   map[string]bool
+	moneyColumn *regexp.Regexp
+	// columns maps lowercase table and column names to the type signatures
+	// of the columns defined by the statement being walked. The catalog
+	// only applies a statement after the rules have seen it.
+	columns map[string]map[string]string
+}
+
+// SetBaseLine implements the advisor.NodeListener interface. It is called
+// before each statement, when the catalog holds the previous statements.
+func (r *columnTypeCheckRule) SetBaseLine(baseLine int) {
+	r.BaseRule.SetBaseLine(baseLine)
+	clear(r.columns)
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *columnTypeCheckRule) NodeTypes() []string {
+	return []string{nodeTypeCreateTable, nodeTypeAlterListItem}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *columnTypeCheckRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	switch ctx := ctx.(type) {
+	case *mysql.CreateTableContext:
+		r.checkCreateTable(ctx)
+	case *mysql.AlterListItemContext:
+		r.checkAlterListItem(ctx)
+	}
+	return nil
+}
+
+func (r *columnTypeCheckRule) checkCreateTable(ctx *mysql.CreateTableContext) {
+	_, table := mysqlparser.NormalizeMySQLTableName(ctx.TableName())
+	if ctx.TableElementList() == nil {
+		return
+	}
+	// Columns first, so foreign keys can reference columns declared later.
+	elements := ctx.TableElementList().AllTableElement()
+	for _, element := range elements {
+		if column := element.ColumnDefinition(); column != nil {
+			r.checkColumn(table, mysqlparser.NormalizeMySQLColumnName(column.ColumnName()), column.FieldDefinition())
+		}
+	}
+	for _, element := range elements {
+		if constraint := element.TableConstraintDef(); constraint != nil {
+			r.checkForeignKey(table, constraint)
+		}
+	}
+}
+
+func (r *columnTypeCheckRule) checkAlterListItem(ctx *mysql.AlterListItemContext) {
+	table := enclosingTableName(ctx)
+
+	switch {
+	// ADD COLUMN c INT
+	case ctx.ADD_SYMBOL() != nil && ctx.Identifier() != nil && ctx.FieldDefinition() != nil:
+		r.checkColumn(table, mysqlparser.NormalizeMySQLIdentifier(ctx.Identifier()), ctx.FieldDefinition())
+	// ADD COLUMN (c INT, ...)
+	case ctx.ADD_SYMBOL() != nil && ctx.TableElementList() != nil:
+		for _, element := range ctx.TableElementList().AllTableElement() {
+			if column := element.ColumnDefinition(); column != nil {
+				r.checkColumn(table, mysqlparser.NormalizeMySQLColumnName(column.ColumnName()), column.FieldDefinition())
+			}
+		}
+	// ADD CONSTRAINT ... FOREIGN KEY
+	case ctx.ADD_SYMBOL() != nil && ctx.TableConstraintDef() != nil:
+		r.checkForeignKey(table, ctx.TableConstraintDef())
+	// CHANGE COLUMN a c INT
+	case ctx.CHANGE_SYMBOL() != nil && ctx.Identifier() != nil:
+		r.checkColumn(table, mysqlparser.NormalizeMySQLIdentifier(ctx.Identifier()), ctx.FieldDefinition())
+	// MODIFY COLUMN c INT
+	case ctx.MODIFY_SYMBOL() != nil && ctx.ColumnInternalRef() != nil:
+		r.checkColumn(table, mysqlparser.NormalizeMySQLIdentifier(ctx.ColumnInternalRef().Identifier()), ctx.FieldDefinition())
+	}
+}
+
+// checkColumn checks the type of one column definition and records it.
+func (r *columnTypeCheckRule) checkColumn(table, column string, definition mysql.IFieldDefinitionContext) {
+	if definition == nil || definition.DataType() == nil {
+		return
+	}
+	dataType := definition.DataType()
+	typeName := columnTypeName(dataType)
+	token := dataType.GetStart()
+	key := strings.ToLower(table)
+	if r.columns[key] == nil {
+		r.columns[key] = make(map[string]string)
+	}
+	r.columns[key][strings.ToLower(column)] = typeSignature(dataType)
+
+	if len(r.allowed) > 0 && !r.allowed[typeName] {
+		r.addTypeAdvice(fmt.Sprintf("Column `%s` uses type %s which is not in the allowed types", column, strings.ToUpper(typeName)), token)
+	}
+	if r.forbidden[typeName] {
+		r.addTypeAdvice(fmt.Sprintf("Column `%s` uses forbidden type %s", column, strings.ToUpper(typeName)), token)
+	}
+	if r.payload.ForbidEnum && (typeName == "enum" || typeName == "set") {
+		r.addTypeAdvice(fmt.Sprintf("Column `%s` uses %s, use a lookup table or a VARCHAR column instead", column, strings.ToUpper(typeName)), token)
+	}
+
+	if length, ok := fieldLength(dataType); ok {
+		if typeName == "varchar" && r.payload.MaxVarcharLength > 0 && length > r.payload.MaxVarcharLength {
+			r.addTypeAdvice(fmt.Sprintf("Column `%s` VARCHAR(%d) exceeds the maximum length %d", column, length, r.payload.MaxVarcharLength), token)
+		}
+		if typeName == "char" && r.payload.MaxCharLength > 0 && length > r.payload.MaxCharLength {
+			r.addTypeAdvice(fmt.Sprintf("Column `%s` CHAR(%d) exceeds the maximum length %d", column, length, r.payload.MaxCharLength), token)
+		}
+	}
+
+	if r.moneyColumn != nil && (typeName == "float" || typeName == "double") && r.moneyColumn.MatchString(column) {
+		r.addTypeAdvice(fmt.Sprintf("Column `%s` holds money and should use DECIMAL instead of %s", column, strings.ToUpper(typeName)), token)
+	}
+}
+
+// checkForeignKey compares the types of the columns of a foreign key with
+// the referenced columns. Referenced tables that are unknown are skipped.
+func (r *columnTypeCheckRule) checkForeignKey(table string, ctx mysql.ITableConstraintDefContext) {
+	if !r.payload.CheckForeignKeyType || ctx.GetType_() == nil || ctx.GetType_().GetTokenType() != mysql.MySQLParserFOREIGN_SYMBOL {
+		return
+	}
+	references := ctx.References()
+	if ctx.KeyList() == nil || references == nil || references.IdentifierListWithParentheses() == nil {
+		return
+	}
+
+	_, referencedTable := mysqlparser.NormalizeMySQLTableRef(references.TableRef())
+	referencedColumns := mysqlparser.NormalizeMySQLIdentifierList(references.IdentifierListWithParentheses().IdentifierList())
+
+	for i, keyPart := range ctx.KeyList().AllKeyPart() {
+		if i >= len(referencedColumns) {
+			break
+		}
+		column := mysqlparser.NormalizeMySQLIdentifier(keyPart.Identifier())
+		columnType, ok := r.columnType(table, column)
+		if !ok {
+			continue
+		}
+		referencedType, ok := r.columnType(referencedTable, referencedColumns[i])
+		if !ok || referencedType == columnType {
+			continue
+		}
+		r.AddAdvice(
+			advisor.CodeColumnTypeMismatch,
+			"Foreign key type mismatch",
+			fmt.Sprintf("Foreign key column `%s`.`%s` is %s but references `%s`.`%s` of type %s",
+				table, column, strings.ToUpper(columnType), referencedTable, referencedColumns[i], strings.ToUpper(referencedType)),
+			keyPart.GetStart(),
+		)
+	}
+}
+
+// columnType returns the type signature of a column defined by the
+// statement being walked, or else of the column in the catalog. Unknown
+// columns report false.
+func (r *columnTypeCheckRule) columnType(table, column string) (string, bool) {
+	if columnType, ok := r.columns[strings.ToLower(table)][strings.ToLower(column)]; ok {
+		return columnType, true
+	}
+	if r.Catalog() == nil {
+		return "", false
+	}
+	catalogTable := r.Catalog().Table("", table)
+	if catalogTable == nil {
+		return "", false
+	}
+	catalogColumn := catalogTable.Column(column)
+	if catalogColumn == nil || catalogColumn.Type == "" {
+		return "", false
+	}
+	return metadataTypeSignature(catalogColumn.Type), true
+}
+
+func (r *columnTypeCheckRule) addTypeAdvice(content string, token antlr.Token) {
+	r.AddAdvice(advisor.CodeColumnTypeDisallowed, "Column type policy", content, token)
+}
+
+// columnTypeName returns the lowercase name of a column type, with the
+// synonyms folded, e.g. "varchar" for CHARACTER VARYING.
+func columnTypeName(ctx mysql.IDataTypeContext) string {
+	typeToken := ctx.GetType_()
+	if typeToken == nil {
+		// NCHAR(n), NATIONAL CHAR(n)
+		return "char"
+	}
+
+	switch typeToken.GetTokenType() {
+	case mysql.MySQLParserCHAR_SYMBOL:
+		if ctx.VARYING_SYMBOL() != nil {
+			return "varchar"
+		}
+		return "char"
+	case mysql.MySQLParserNATIONAL_SYMBOL, mysql.MySQLParserNVARCHAR_SYMBOL, mysql.MySQLParserNCHAR_SYMBOL:
+		return "varchar"
+	case mysql.MySQLParserLONG_SYMBOL:
+		if ctx.VARBINARY_SYMBOL() != nil {
+			return "mediumblob"
+		}
+		return "mediumtext"
+	case mysql.MySQLParserREAL_SYMBOL:
+		return "double"
+	case mysql.MySQLParserNUMERIC_SYMBOL, mysql.MySQLParserFIXED_SYMBOL:
+		return "decimal"
+	case mysql.MySQLParserBOOLEAN_SYMBOL:
+		return "bool"
+	}
+	return strings.ToLower(strings.TrimSuffix(mysql.MySQLParserParserStaticData.SymbolicNames[typeToken.GetTokenType()], "_SYMBOL"))
+}
+
+// typeSignature returns the type name with UNSIGNED, which must match
+// between a foreign key column and the referenced column.
+func typeSignature(ctx mysql.IDataTypeContext) string {
+	signature := columnTypeName(ctx)
+	if ctx.FieldOptions() != nil && len(ctx.FieldOptions().AllUNSIGNED_SYMBOL()) > 0 {
+		signature += " unsigned"
+	}
+	return signature
+}
+
+// metadataTypeSignature converts a column type from the database or the
+// catalog, such as "int(10) unsigned" or "integer", to a type signature.
+// Synonyms are folded like columnTypeName does.
+func metadataTypeSignature(columnType string) string {
+	columnType = strings.ToLower(strings.TrimSpace(columnType))
+	name := columnType
+	if i := strings.Index(name, "("); i >= 0 {
+		name = name[:i]
+	}
+	words := strings.Fields(name)
+	if len(words) > 1 && words[0] == "national" {
+		words = words[1:]
+	}
+
+	signature := ""
+	if len(words) > 0 {
+		signature = words[0]
+	}
+	varying := strings.Contains(name, "varying") || strings.Contains(name, "varchar")
+	switch signature {
+	case "character", "char", "nchar":
+		signature = "char"
+		if varying {
+			signature = "varchar"
+		}
+	case "nvarchar":
+		signature = "varchar"
+	case "integer":
+		signature = "int"
+	case "boolean":
+		signature = "bool"
+	case "numeric", "fixed", "dec":
+		signature = "decimal"
+	case "real":
+		signature = "double"
+	case "long":
+		signature = "mediumtext"
+		if strings.Contains(name, "varbinary") {
+			signature = "mediumblob"
+		}
+	}
+	if strings.Contains(columnType, "unsigned") {
+		signature += " unsigned"
+	}
+	return signature
+}
+
+// fieldLength returns the declared length of a column type, e.g. 50 for
+// VARCHAR(50).
+func fieldLength(ctx mysql.IDataTypeContext) (int, bool) {
+	if ctx.FieldLength() == nil {
+		return 0, false
+	}
+	text := strings.Trim(ctx.FieldLength().GetText(), "()")
+	length, err := strconv.Atoi(text)
+	return length, err == nil
+}
+
+func toLowerSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[strings.ToLower(value)] = true
+	}
+	return set
+}
@@ -0,0 +1,82 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+// TestColumnTypeCheck 测试列类型检查
+func TestColumnTypeCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   any
+		statement string
+		expected  []string
+	}{
+		{
+			name:      "Good types",
+			statement: "CREATE TABLE orders (id BIGINT PRIMARY KEY, code CHAR(8), name VARCHAR(100), price DECIMAL(10,2));",
+		},
+		{
+			name:      "Forbidden types and lengths",
+			statement: "CREATE TABLE risky_table (\n    id INT PRIMARY KEY,\n    data TEXT,\n    blob_data LONGBLOB,\n    description VARCHAR(65535),\n    code NCHAR(32)\n);",
+			expected:  []string{"3:10 901", "4:15 901", "5:17 901", "6:10 901"},
+		},
+		{
+			name:      "Money columns and ENUM",
+			statement: "CREATE TABLE items (id INT PRIMARY KEY, unit_price DOUBLE, weight FLOAT, status ENUM('a', 'b'));",
+			expected:  []string{"1:52 901", "1:81 901"},
+		},
+		{
+			name:      "Money word inside a column name",
+			statement: "CREATE TABLE items (id INT PRIMARY KEY, amount_unit_name FLOAT, Total_Fees DOUBLE, prices FLOAT);",
+			expected:  []string{"1:76 901", "1:91 901"},
+		},
+		{
+			name:      "Allowlist",
+			payload:   map[string]any{"allowed_types": []string{"int", "varchar"}, "forbidden_types": []string{}},
+			statement: "CREATE TABLE t_ok (id INT PRIMARY KEY, name CHARACTER VARYING(20), born DATE);",
+			expected:  []string{"1:73 901"},
+		},
+		{
+			name:      "Alter table",
+			statement: "ALTER TABLE users ADD COLUMN bio TEXT, MODIFY COLUMN name VARCHAR(300), CHANGE COLUMN fee total_fee FLOAT;",
+			expected:  []string{"1:34 901", "1:59 901", "1:101 901"},
+		},
+		{
+			name: "Foreign key type mismatch",
+			statement: "CREATE TABLE users (id BIGINT PRIMARY KEY);\n" +
+				"CREATE TABLE orders (id BIGINT PRIMARY KEY, user_id INT, FOREIGN KEY (user_id) REFERENCES users (id));\n" +
+				"CREATE TABLE payments (id BIGINT PRIMARY KEY, user_id BIGINT, FOREIGN KEY (user_id) REFERENCES users (id));\n" +
+				"ALTER TABLE payments ADD COLUMN order_id INT UNSIGNED, ADD CONSTRAINT fk_payments_order FOREIGN KEY (order_id) REFERENCES orders (id);",
+			expected: []string{"2:71 904", "4:102 904"},
+		},
+		{
+			name: "Foreign key after rename and type synonyms",
+			statement: "CREATE TABLE accounts (id INTEGER UNSIGNED PRIMARY KEY, code NUMERIC(10));\n" +
+				"RENAME TABLE accounts TO users;\n" +
+				"CREATE TABLE orders (id BIGINT PRIMARY KEY, user_id INT UNSIGNED, code DECIMAL(10), FOREIGN KEY (user_id) REFERENCES users (id), FOREIGN KEY (code) REFERENCES users (code));\n" +
+				"CREATE TABLE refunds (id BIGINT PRIMARY KEY, user_id INT, FOREIGN KEY (user_id) REFERENCES accounts (id));",
+		},
+		{
+			name:      "Self-referencing foreign key",
+			statement: "CREATE TABLE nodes (id BIGINT PRIMARY KEY, parent_id INT, FOREIGN KEY (parent_id) REFERENCES nodes (id));",
+			expected:  []string{"1:72 904"},
+		},
+		{
+			name:      "Foreign key to unknown table",
+			statement: "CREATE TABLE orders (id BIGINT PRIMARY KEY, user_id INT, FOREIGN KEY (user_id) REFERENCES users (id));",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checkRule(t, advisor.MySQLColumnTypeCheck, tt.payload, tt.statement)
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package mysql
+
+import (
+	"strconv"
+	"strings"
+
+	mysql "github.com/bytebase/parser/mysql"
+
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+// simpleExprOfPredicate returns the simple expression of a predicate that
+// has no operators, e.g. the column in `WHERE c`.
+func simpleExprOfPredicate(ctx mysql.IPredicateContext) mysql.ISimpleExprContext {
+	if ctx == nil || ctx.PredicateOperations() != nil || ctx.MEMBER_SYMBOL() != nil || ctx.SOUNDS_SYMBOL() != nil {
+		return nil
+	}
+	bitExprs := ctx.AllBitExpr()
+	if len(bitExprs) != 1 {
+		return nil
+	}
+	return bitExprs[0].SimpleExpr()
+}
+
+// simpleExprOfBoolPri returns the simple expression of a boolean primary
+// that is a plain predicate.
+func simpleExprOfBoolPri(ctx mysql.IBoolPriContext) mysql.ISimpleExprContext {
+	predicate, ok := ctx.(*mysql.PrimaryExprPredicateContext)
+	if !ok {
+		return nil
+	}
+	return simpleExprOfPredicate(predicate.Predicate())
+}
+
+// isAlwaysTrue reports whether a condition holds for every row, such as
+// `1`, `TRUE`, `1 = 1`, `id = id` or `x OR 1 = 1`.
+func isAlwaysTrue(ctx mysql.IExprContext) bool {
+	switch ctx := ctx.(type) {
+	case *mysql.ExprOrContext:
+		return isAlwaysTrue(ctx.Expr(0)) || isAlwaysTrue(ctx.Expr(1))
+	case *mysql.ExprAndContext:
+		return isAlwaysTrue(ctx.Expr(0)) && isAlwaysTrue(ctx.Expr(1))
+	case *mysql.ExprIsContext:
+		// IS TRUE / IS NOT FALSE ... are left alone.
+		if ctx.GetType_() != nil {
+			return false
+		}
+		return isAlwaysTrueBoolPri(ctx.BoolPri())
+	}
+	return false
+}
+
+func isAlwaysTrueBoolPri(ctx mysql.IBoolPriContext) bool {
+	switch ctx := ctx.(type) {
+	case *mysql.PrimaryExprPredicateContext:
+		switch simple := simpleExprOfPredicate(ctx.Predicate()).(type) {
+		case *mysql.SimpleExprLiteralContext:
+			return isTruthyLiteral(simple.Literal())
+		case *mysql.SimpleExprListContext:
+			// (1 = 1)
+			if simple.ROW_SYMBOL() == nil && simple.ExprList() != nil && len(simple.ExprList().AllExpr()) == 1 {
+				return isAlwaysTrue(simple.ExprList().Expr(0))
+			}
+		}
+	case *mysql.PrimaryExprCompareContext:
+		left, right := simpleExprOfBoolPri(ctx.BoolPri()), simpleExprOfPredicate(ctx.Predicate())
+		if left == nil || right == nil || ctx.CompOp() == nil {
+			return false
+		}
+		op := ctx.CompOp().GetStart().GetTokenType()
+
+		leftValue, leftOK := numericLiteral(left)
+		rightValue, rightOK := numericLiteral(right)
+		if leftOK && rightOK {
+			return compareNumbers(op, leftValue, rightValue)
+		}
+
+		if sameOperand(left, right) {
+			switch op {
+			case mysql.MySQLParserEQUAL_OPERATOR, mysql.MySQLParserNULL_SAFE_EQUAL_OPERATOR,
+				mysql.MySQLParserGREATER_OR_EQUAL_OPERATOR, mysql.MySQLParserLESS_OR_EQUAL_OPERATOR:
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTruthyLiteral reports whether a literal is a non-zero number or TRUE.
+func isTruthyLiteral(ctx mysql.ILiteralContext) bool {
+	if ctx == nil {
+		return false
+	}
+	if ctx.BoolLiteral() != nil {
+		return ctx.BoolLiteral().TRUE_SYMBOL() != nil
+	}
+	if ctx.NumLiteral() != nil {
+		value, err := strconv.ParseFloat(ctx.NumLiteral().GetText(), 64)
+		return err == nil && value != 0
+	}
+	return false
+}
+
+// numericLiteral returns the value of a numeric literal expression.
+func numericLiteral(ctx mysql.ISimpleExprContext) (float64, bool) {
+	literal, ok := ctx.(*mysql.SimpleExprLiteralContext)
+	if !ok || literal.Literal() == nil || literal.Literal().NumLiteral() == nil {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(literal.Literal().NumLiteral().GetText(), 64)
+	return value, err == nil
+}
+
+func compareNumbers(op int, left, right float64) bool {
+	switch op {
+	case mysql.MySQLParserEQUAL_OPERATOR, mysql.MySQLParserNULL_SAFE_EQUAL_OPERATOR:
+		return left == right
+	case mysql.MySQLParserNOT_EQUAL_OPERATOR:
+		return left != right
+	case mysql.MySQLParserGREATER_OR_EQUAL_OPERATOR:
+		return left >= right
+	case mysql.MySQLParserGREATER_THAN_OPERATOR:
+		return left > right
+	case mysql.MySQLParserLESS_OR_EQUAL_OPERATOR:
+		return left <= right
+	case mysql.MySQLParserLESS_THAN_OPERATOR:
+		return left < right
+	}
+	return false
+}
+
+// sameOperand reports whether two expressions are the same column or the
+// same literal.
+func sameOperand(left, right mysql.ISimpleExprContext) bool {
+	switch left := left.(type) {
+	case *mysql.SimpleExprColumnRefContext:
+		right, ok := right.(*mysql.SimpleExprColumnRefContext)
+		if !ok || left.JsonOperator() != nil || right.JsonOperator() != nil {
+			return false
+		}
+		_, leftTable, leftColumn := mysqlparser.NormalizeMySQLColumnRef(left.ColumnRef())
+		_, rightTable, rightColumn := mysqlparser.NormalizeMySQLColumnRef(right.ColumnRef())
+		return strings.EqualFold(leftTable, rightTable) && strings.EqualFold(leftColumn, rightColumn)
+	case *mysql.SimpleExprLiteralContext:
+		right, ok := right.(*mysql.SimpleExprLiteralContext)
+		return ok && left.GetText() == right.GetText()
+	}
+	return false
+}
+
+// columnRefOf returns the column reference of a plain column expression.
+func columnRefOf(ctx mysql.ISimpleExprContext) mysql.IColumnRefContext {
+	column, ok := ctx.(*mysql.SimpleExprColumnRefContext)
+	if !ok || column.JsonOperator() != nil {
+		return nil
+	}
+	return column.ColumnRef()
+}
@@ -0,0 +1,601 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+	mysql "github.com/bytebase/parser/mysql"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/catalog"
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+func init() {
+	advisor.Register(advisor.MySQL, advisor.MySQLIndexDesign, &IndexDesignAdvisor{})
+}
+
+// IndexDesignPayload is the payload of the index design rule. Numeric
+// limits of 0 disable the check.
+type IndexDesignPayload struct {
+	// MaxIndexCount caps the indexes of a table, not counting the primary
+	// key.
+	MaxIndexCount   int `json:"max_index_count" yaml:"max_index_count" validate:"min=0"`
+	MaxIndexColumns int `json:"max_index_columns" yaml:"max_index_columns" validate:"min=0,max=16"`
+	// ForbidRedundantIndex flags indexes with the same columns as another
+	// index, and non-unique indexes that are a left prefix of another index.
+	ForbidRedundantIndex bool `json:"forbid_redundant_index" yaml:"forbid_redundant_index"`
+	// LowSelectivityTypes are column types such as "bool" or "enum" that
+	// make a poor index on their own. Empty disables the check.
+	LowSelectivityTypes []string `json:"low_selectivity_types" yaml:"low_selectivity_types"`
+	// PrimaryKeyTypes is an allowlist of primary key column types, either
+	// a type name such as "bigint" or a signature such as "bigint unsigned".
+	// Empty allows all types.
+	PrimaryKeyTypes          []string `json:"primary_key_types" yaml:"primary_key_types"`
+	ForbiddenPrimaryKeyTypes []string `json:"forbidden_primary_key_types" yaml:"forbidden_primary_key_types"`
+	// RequirePrimaryKeyAutoIncrement requires single-column primary keys to
+	// be AUTO_INCREMENT.
+	RequirePrimaryKeyAutoIncrement bool `json:"require_primary_key_auto_increment" yaml:"require_primary_key_auto_increment"`
+	ForbidForeignKey               bool `json:"forbid_foreign_key" yaml:"forbid_foreign_key"`
+}
+
+// DefaultIndexDesignPayload returns the payload used for options that are
+// not configured.
+func DefaultIndexDesignPayload() *IndexDesignPayload {
+	return &IndexDesignPayload{
+		MaxIndexCount:            5,
+		MaxIndexColumns:          5,
+		ForbidRedundantIndex:     true,
+		LowSelectivityTypes:      []string{"bool", "bit", "enum", "set"},
+		ForbiddenPrimaryKeyTypes: []string{"char", "varchar"},
+		ForbidForeignKey:         true,
+	}
+}
+
+// IndexDesignAdvisor checks the indexes and keys of a table.
+type IndexDesignAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *IndexDesignAdvisor) Title() string {
+	return "索引设计检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *IndexDesignAdvisor) Description() string {
+	return "限制每张表的索引数和每个索引的列数，禁止重复和冗余（左前缀）索引，不建议在低区分度类型的列上建索引，检查主键列类型，禁止外键"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *IndexDesignAdvisor) DefaultPayload() any {
+	return DefaultIndexDesignPayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *IndexDesignAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *IndexDesignAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultIndexDesignPayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+
+	rule := &indexDesignRule{
+		BaseRule:          advisor.NewBaseRule(checkCtx.Rule),
+		payload:           payload,
+		lowSelectivity:    toLowerSet(payload.LowSelectivityTypes),
+		primaryKeyTypes:   toLowerSet(payload.PrimaryKeyTypes),
+		forbiddenKeyTypes: toLowerSet(payload.ForbiddenPrimaryKeyTypes),
+		tables:            make(map[string]*indexedTable),
+		countReported:     make(map[string]bool),
+	}
+	return rule, nil
+}
+
+// indexDesignRule checks the indexes of CREATE TABLE, CREATE INDEX and
+// ALTER TABLE against the indexes the table has in the catalog.
+type indexDesignRule struct {
+	advisor.BaseRule
+
+	payload           *IndexDesignPayload
+	lowSelectivity    map[string]bool
+	primaryKeyTypes   map[string]bool
+	forbiddenKeyTypes map[string]bool
+
+	// tables maps lowercase table names to the columns and indexes of the
+	// tables changed by the statement being walked, starting from the
+	// catalog. The catalog only applies a statement after the rules have
+	// seen it, so a statement adding several indexes is checked index by
+	// index here.
+	tables map[string]*indexedTable
+	// countReported holds the lowercase names of the tables whose index
+	// count has been reported, so that each table is reported once.
+	countReported map[string]bool
+}
+
+// SetBaseLine implements the advisor.NodeListener interface. It is called
+// before each statement, when the catalog holds the previous statements.
+func (r *indexDesignRule) SetBaseLine(baseLine int) {
+	r.BaseRule.SetBaseLine(baseLine)
+	clear(r.tables)
+}
+
+// indexedTable is the state of a table known to the index design rule.
+type indexedTable struct {
+	// columns maps lowercase column names to their types.
+	columns map[string]*indexedColumn
+	indexes []*tableIndex
+}
+
+type indexedColumn struct {
+	typeName      string
+	signature     string
+	autoIncrement bool
+}
+
+type tableIndex struct {
+	name string
+	// columns are lowercase column names, or the text of expression key
+	// parts.
+	columns []string
+	primary bool
+	unique  bool
+	// fulltext is set for FULLTEXT and SPATIAL indexes, which are not
+	// compared with B-tree indexes.
+	fulltext bool
+}
+
+func (i *tableIndex) String() string {
+	if i.primary {
+		return "PRIMARY KEY"
+	}
+	if i.name != "" {
+		return fmt.Sprintf("`%s`", i.name)
+	}
+	return fmt.Sprintf("(%s)", strings.Join(i.columns, ", "))
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *indexDesignRule) NodeTypes() []string {
+	return []string{nodeTypeCreateTable, nodeTypeCreateIndex, nodeTypeAlterListItem}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *indexDesignRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	switch ctx := ctx.(type) {
+	case *mysql.CreateTableContext:
+		r.checkCreateTable(ctx)
+	case *mysql.CreateIndexContext:
+		r.checkCreateIndex(ctx)
+	case *mysql.AlterListItemContext:
+		r.checkAlterListItem(ctx)
+	}
+	return nil
+}
+
+func (r *indexDesignRule) checkCreateTable(ctx *mysql.CreateTableContext) {
+	// CREATE TABLE ... LIKE copies the indexes of a table that were checked
+	// when they were created.
+	if ctx.TableRef() != nil || ctx.TableElementList() == nil {
+		return
+	}
+	_, table := mysqlparser.NormalizeMySQLTableName(ctx.TableName())
+	state := newIndexedTable()
+	r.tables[strings.ToLower(table)] = state
+	// Columns first, so keys can use columns declared later.
+	elements := ctx.TableElementList().AllTableElement()
+	for _, element := range elements {
+		if column := element.ColumnDefinition(); column != nil {
+			r.addColumn(table, state, mysqlparser.NormalizeMySQLColumnName(column.ColumnName()), column.FieldDefinition(), column.CheckOrReferences())
+		}
+	}
+	for _, element := range elements {
+		if constraint := element.TableConstraintDef(); constraint != nil {
+			r.addConstraint(table, state, constraint)
+		}
+	}
+}
+
+func (r *indexDesignRule) checkCreateIndex(ctx *mysql.CreateIndexContext) {
+	target := ctx.CreateIndexTarget()
+	if target == nil || target.TableRef() == nil {
+		return
+	}
+	_, table := mysqlparser.NormalizeMySQLTableRef(target.TableRef())
+	state := r.table(table)
+
+	index := &tableIndex{
+		columns:  indexKeyColumns(target.KeyListVariants()),
+		unique:   ctx.UNIQUE_SYMBOL() != nil,
+		fulltext: ctx.GetType_() != nil && ctx.GetType_().GetTokenType() != mysql.MySQLParserINDEX_SYMBOL,
+	}
+	switch {
+	case ctx.IndexName() != nil:
+		index.name = mysqlparser.NormalizeMySQLIdentifier(ctx.IndexName().Identifier())
+	case ctx.IndexNameAndType() != nil:
+		index.name = mysqlparser.NormalizeMySQLIdentifier(ctx.IndexNameAndType().IndexName().Identifier())
+	}
+	r.addIndex(table, state, index, statementStart(ctx))
+}
+
+func (r *indexDesignRule) checkAlterListItem(ctx *mysql.AlterListItemContext) {
+	table := enclosingTableName(ctx)
+	state := r.table(table)
+
+	switch {
+	// ADD COLUMN c INT
+	case ctx.ADD_SYMBOL() != nil && ctx.Identifier() != nil && ctx.FieldDefinition() != nil:
+		r.addColumn(table, state, mysqlparser.NormalizeMySQLIdentifier(ctx.Identifier()), ctx.FieldDefinition(), ctx.CheckOrReferences())
+	// ADD COLUMN (c INT, ...)
+	case ctx.ADD_SYMBOL() != nil && ctx.TableElementList() != nil:
+		for _, element := range ctx.TableElementList().AllTableElement() {
+			if column := element.ColumnDefinition(); column != nil {
+				r.addColumn(table, state, mysqlparser.NormalizeMySQLColumnName(column.ColumnName()), column.FieldDefinition(), column.CheckOrReferences())
+			}
+		}
+	// ADD INDEX, ADD PRIMARY KEY, ADD FOREIGN KEY ...
+	case ctx.ADD_SYMBOL() != nil && ctx.TableConstraintDef() != nil:
+		r.addConstraint(table, state, ctx.TableConstraintDef())
+	// CHANGE COLUMN a c INT
+	case ctx.CHANGE_SYMBOL() != nil && ctx.Identifier() != nil:
+		if ctx.ColumnInternalRef() != nil {
+			delete(state.columns, strings.ToLower(mysqlparser.NormalizeMySQLIdentifier(ctx.ColumnInternalRef().Identifier())))
+		}
+		r.addColumn(table, state, mysqlparser.NormalizeMySQLIdentifier(ctx.Identifier()), ctx.FieldDefinition(), nil)
+	// MODIFY COLUMN c INT
+	case ctx.MODIFY_SYMBOL() != nil && ctx.ColumnInternalRef() != nil:
+		r.addColumn(table, state, mysqlparser.NormalizeMySQLIdentifier(ctx.ColumnInternalRef().Identifier()), ctx.FieldDefinition(), nil)
+	// DROP PRIMARY KEY
+	case ctx.DROP_SYMBOL() != nil && ctx.PRIMARY_SYMBOL() != nil:
+		state.dropPrimaryKey()
+	// DROP INDEX i
+	case ctx.DROP_SYMBOL() != nil && ctx.KeyOrIndex() != nil && ctx.IndexRef() != nil:
+		state.dropIndex(mysqlparser.NormalizeMySQLFieldIdentifier(ctx.IndexRef().FieldIdentifier()))
+	// RENAME INDEX a TO b
+	case ctx.RENAME_SYMBOL() != nil && ctx.IndexRef() != nil && ctx.IndexName() != nil:
+		if index := state.findIndex(mysqlparser.NormalizeMySQLFieldIdentifier(ctx.IndexRef().FieldIdentifier())); index != nil {
+			index.name = mysqlparser.NormalizeMySQLIdentifier(ctx.IndexName().Identifier())
+		}
+	}
+}
+
+// addColumn records a column and checks the keys declared on it, such as
+// `id BIGINT PRIMARY KEY` or an inline REFERENCES clause.
+func (r *indexDesignRule) addColumn(table string, state *indexedTable, column string, definition mysql.IFieldDefinitionContext, references mysql.ICheckOrReferencesContext) {
+	if definition == nil || definition.DataType() == nil {
+		return
+	}
+	columnState := &indexedColumn{
+		typeName:  columnTypeName(definition.DataType()),
+		signature: typeSignature(definition.DataType()),
+	}
+	var keys []*tableIndex
+	var keyTokens []antlr.Token
+	for _, attribute := range definition.AllColumnAttribute() {
+		if attribute.GetValue() == nil {
+			continue
+		}
+		switch attribute.GetValue().GetTokenType() {
+		case mysql.MySQLParserAUTO_INCREMENT_SYMBOL:
+			columnState.autoIncrement = true
+		case mysql.MySQLParserKEY_SYMBOL:
+			keys = append(keys, &tableIndex{columns: []string{strings.ToLower(column)}, primary: true, unique: true})
+			keyTokens = append(keyTokens, attribute.GetStart())
+		case mysql.MySQLParserUNIQUE_SYMBOL:
+			keys = append(keys, &tableIndex{name: column, columns: []string{strings.ToLower(column)}, unique: true})
+			keyTokens = append(keyTokens, attribute.GetStart())
+		}
+	}
+	state.columns[strings.ToLower(column)] = columnState
+
+	for i, key := range keys {
+		r.addIndex(table, state, key, keyTokens[i])
+	}
+	if references != nil && references.References() != nil {
+		r.checkForeignKey(table, references.References().GetStart())
+	}
+}
+
+// addConstraint checks an index or key of CREATE TABLE or ALTER TABLE ADD.
+func (r *indexDesignRule) addConstraint(table string, state *indexedTable, ctx mysql.ITableConstraintDefContext) {
+	if ctx.GetType_() == nil {
+		return
+	}
+	index := &tableIndex{columns: indexKeyColumns(ctx.KeyListVariants())}
+	switch ctx.GetType_().GetTokenType() {
+	case mysql.MySQLParserPRIMARY_SYMBOL:
+		index.primary, index.unique = true, true
+	case mysql.MySQLParserUNIQUE_SYMBOL:
+		index.unique = true
+	case mysql.MySQLParserFULLTEXT_SYMBOL, mysql.MySQLParserSPATIAL_SYMBOL:
+		index.fulltext = true
+	case mysql.MySQLParserFOREIGN_SYMBOL:
+		r.checkForeignKey(table, ctx.GetStart())
+		return
+	case mysql.MySQLParserKEY_SYMBOL, mysql.MySQLParserINDEX_SYMBOL:
+	default:
+		// CHECK constraints
+		return
+	}
+	if name := constraintIndexName(ctx.(*mysql.TableConstraintDefContext)); name != nil {
+		index.name = mysqlparser.NormalizeMySQLIdentifier(name)
+	}
+	r.addIndex(table, state, index, ctx.GetStart())
+}
+
+// addIndex checks a new index against the indexes the table already has
+// and records it.
+func (r *indexDesignRule) addIndex(table string, state *indexedTable, index *tableIndex, token antlr.Token) {
+	if index.primary {
+		r.checkPrimaryKey(table, state, index, token)
+	}
+
+	if r.payload.MaxIndexColumns > 0 && len(index.columns) > r.payload.MaxIndexColumns {
+		r.AddAdvice(
+			advisor.CodeIndexColumnCountExceeded,
+			"Too many index columns",
+			fmt.Sprintf("Index %s on `%s` has %d columns, exceeding the maximum %d", index, table, len(index.columns), r.payload.MaxIndexColumns),
+			token,
+		)
+	}
+
+	if !index.primary && !index.fulltext && len(r.lowSelectivity) > 0 && state.allColumnTypesIn(index.columns, r.lowSelectivity) {
+		r.AddAdvice(
+			advisor.CodeIndexLowSelectivity,
+			"Low selectivity index",
+			fmt.Sprintf("Index %s on `%s` only covers low-selectivity columns (%s)", index, table, strings.Join(index.columns, ", ")),
+			token,
+		)
+	}
+
+	if r.payload.ForbidRedundantIndex && !index.fulltext {
+		r.checkRedundantIndex(table, state, index, token)
+	}
+
+	state.indexes = append(state.indexes, index)
+	if r.payload.MaxIndexCount > 0 && !r.countReported[strings.ToLower(table)] {
+		if count := state.secondaryIndexCount(); count > r.payload.MaxIndexCount {
+			r.countReported[strings.ToLower(table)] = true
+			r.AddAdvice(
+				advisor.CodeIndexCountExceeded,
+				"Too many indexes",
+				fmt.Sprintf("Table `%s` has %d indexes, exceeding the maximum %d", table, count, r.payload.MaxIndexCount),
+				token,
+			)
+		}
+	}
+}
+
+// checkRedundantIndex reports the existing indexes that a new index
+// duplicates. Without a duplicate, it reports every existing index that
+// the new index is a left prefix of or makes redundant.
+func (r *indexDesignRule) checkRedundantIndex(table string, state *indexedTable, index *tableIndex, token antlr.Token) {
+	duplicated := false
+	for _, existing := range state.indexes {
+		if !existing.fulltext && len(index.columns) == len(existing.columns) && isLeftPrefix(index.columns, existing.columns) {
+			duplicated = true
+			r.AddAdvice(
+				advisor.CodeIndexDuplicate,
+				"Duplicate index",
+				fmt.Sprintf("Index %s on `%s` has the same columns as %s", index, table, existing),
+				token,
+			)
+		}
+	}
+	// The prefixes of a duplicated index were reported on the index itself
+	if duplicated {
+		return
+	}
+
+	for _, existing := range state.indexes {
+		if existing.fulltext {
+			continue
+		}
+		switch {
+		case !index.unique && isLeftPrefix(index.columns, existing.columns):
+			r.AddAdvice(
+				advisor.CodeIndexRedundant,
+				"Redundant index",
+				fmt.Sprintf("Index %s on `%s` is a left prefix of %s", index, table, existing),
+				token,
+			)
+		case !existing.unique && isLeftPrefix(existing.columns, index.columns):
+			r.AddAdvice(
+				advisor.CodeIndexRedundant,
+				"Redundant index",
+				fmt.Sprintf("Index %s on `%s` makes %s redundant, which is a left prefix of it", index, table, existing),
+				token,
+			)
+		}
+	}
+}
+
+// checkPrimaryKey checks the column types of a primary key. Columns with
+// an unknown type are skipped.
+func (r *indexDesignRule) checkPrimaryKey(table string, state *indexedTable, index *tableIndex, token antlr.Token) {
+	for _, column := range index.columns {
+		columnState, ok := state.columns[column]
+		if !ok {
+			continue
+		}
+		if len(r.primaryKeyTypes) > 0 && !r.primaryKeyTypes[columnState.signature] && !r.primaryKeyTypes[columnState.typeName] {
+			r.addPrimaryKeyAdvice(fmt.Sprintf("Primary key column `%s`.`%s` is %s which is not in the allowed primary key types", table, column, strings.ToUpper(columnState.signature)), token)
+		}
+		if r.forbiddenKeyTypes[columnState.typeName] {
+			r.addPrimaryKeyAdvice(fmt.Sprintf("Primary key column `%s`.`%s` uses forbidden type %s", table, column, strings.ToUpper(columnState.typeName)), token)
+		}
+	}
+	if r.payload.RequirePrimaryKeyAutoIncrement && len(index.columns) == 1 {
+		if columnState, ok := state.columns[index.columns[0]]; ok && !columnState.autoIncrement {
+			r.addPrimaryKeyAdvice(fmt.Sprintf("Primary key column `%s`.`%s` should be AUTO_INCREMENT", table, index.columns[0]), token)
+		}
+	}
+}
+
+func (r *indexDesignRule) addPrimaryKeyAdvice(content string, token antlr.Token) {
+	r.AddAdvice(advisor.CodePrimaryKeyTypeInvalid, "Primary key type", content, token)
+}
+
+func (r *indexDesignRule) checkForeignKey(table string, token antlr.Token) {
+	if !r.payload.ForbidForeignKey {
+		return
+	}
+	r.AddAdvice(
+		advisor.CodeForeignKeyDisallowed,
+		"Disallow foreign key",
+		fmt.Sprintf("Foreign key on `%s` is not allowed, keep the reference in the application instead", table),
+		token,
+	)
+}
+
+// table returns the state of a table for the statement being walked,
+// starting from the table in the catalog on first use. Tables unknown to
+// the catalog start empty.
+func (r *indexDesignRule) table(name string) *indexedTable {
+	key := strings.ToLower(name)
+	if state, ok := r.tables[key]; ok {
+		return state
+	}
+
+	state := newIndexedTable()
+	if r.Catalog() != nil {
+		if table := r.Catalog().Table("", name); table != nil {
+			for _, column := range table.Columns {
+				state.columns[strings.ToLower(column.Name)] = newCatalogColumn(column)
+			}
+			for _, index := range table.Indexes {
+				state.indexes = append(state.indexes, newCatalogIndex(index))
+			}
+		}
+	}
+	r.tables[key] = state
+	return state
+}
+
+func newIndexedTable() *indexedTable {
+	return &indexedTable{columns: make(map[string]*indexedColumn)}
+}
+
+// newCatalogColumn converts a column of the catalog, whose type is the
+// text of the column definition or the type from the database, such as
+// "int(10) unsigned".
+func newCatalogColumn(column *catalog.Column) *indexedColumn {
+	signature := metadataTypeSignature(column.Type)
+	return &indexedColumn{
+		typeName:      strings.TrimSuffix(signature, " unsigned"),
+		signature:     signature,
+		autoIncrement: column.AutoIncrement,
+	}
+}
+
+// newCatalogIndex converts an index of the catalog.
+func newCatalogIndex(index *catalog.Index) *tableIndex {
+	converted := &tableIndex{
+		name:     index.Name,
+		primary:  index.Primary,
+		unique:   index.Unique,
+		fulltext: index.Fulltext,
+	}
+	for _, column := range index.Columns {
+		converted.columns = append(converted.columns, strings.ToLower(column))
+	}
+	return converted
+}
+
+func (t *indexedTable) findIndex(name string) *tableIndex {
+	for _, index := range t.indexes {
+		if strings.EqualFold(index.name, name) {
+			return index
+		}
+	}
+	return nil
+}
+
+func (t *indexedTable) dropIndex(name string) {
+	for i, index := range t.indexes {
+		if !index.primary && strings.EqualFold(index.name, name) {
+			t.indexes = append(t.indexes[:i], t.indexes[i+1:]...)
+			return
+		}
+	}
+}
+
+func (t *indexedTable) dropPrimaryKey() {
+	for i, index := range t.indexes {
+		if index.primary {
+			t.indexes = append(t.indexes[:i], t.indexes[i+1:]...)
+			return
+		}
+	}
+}
+
+func (t *indexedTable) secondaryIndexCount() int {
+	count := 0
+	for _, index := range t.indexes {
+		if !index.primary {
+			count++
+		}
+	}
+	return count
+}
+
+// allColumnTypesIn reports whether all columns are known and have one of
+// the types.
+func (t *indexedTable) allColumnTypesIn(columns []string, types map[string]bool) bool {
+	if len(columns) == 0 {
+		return false
+	}
+	for _, column := range columns {
+		columnState, ok := t.columns[column]
+		if !ok || !types[columnState.typeName] {
+			return false
+		}
+	}
+	return true
+}
+
+// indexKeyColumns returns the lowercase column names of an index key list.
+// Expression key parts are returned as their text, so that two indexes on
+// different expressions are not taken as equal.
+func indexKeyColumns(ctx mysql.IKeyListVariantsContext) []string {
+	if ctx == nil {
+		return nil
+	}
+	var columns []string
+	if ctx.KeyList() != nil {
+		for _, keyPart := range ctx.KeyList().AllKeyPart() {
+			columns = append(columns, strings.ToLower(mysqlparser.NormalizeMySQLIdentifier(keyPart.Identifier())))
+		}
+	}
+	if ctx.KeyListWithExpression() != nil {
+		for _, keyPart := range ctx.KeyListWithExpression().AllKeyPartOrExpression() {
+			if keyPart.KeyPart() != nil {
+				columns = append(columns, strings.ToLower(mysqlparser.NormalizeMySQLIdentifier(keyPart.KeyPart().Identifier())))
+				continue
+			}
+			columns = append(columns, strings.ToLower(keyPart.GetText()))
+		}
+	}
+	return columns
+}
+
+// isLeftPrefix reports whether prefix is a left prefix of columns, or
+// equal to it.
+func isLeftPrefix(prefix, columns []string) bool {
+	if len(prefix) == 0 || len(prefix) > len(columns) {
+		return false
+	}
+	for i, column := range prefix {
+		if column != columns[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package mysql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+// TestIndexDesign 测试索引设计检查
+func TestIndexDesign(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   any
+		statement string
+		expected  []string
+	}{
+		{
+			name:      "Good indexes",
+			statement: "CREATE TABLE orders (id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY, user_id BIGINT, status TINYINT, created_at DATETIME, UNIQUE KEY uk_orders_user (user_id, created_at));\nCREATE INDEX idx_orders_status_created ON orders (status, created_at);",
+		},
+		{
+			name:      "Too many indexes",
+			payload:   map[string]any{"max_index_count": 2},
+			statement: "CREATE TABLE t (id INT PRIMARY KEY, a INT, b INT, c INT, d INT, KEY idx_a (a), KEY idx_b (b));\nALTER TABLE t ADD INDEX idx_c (c);\nCREATE INDEX idx_d ON t (d);",
+			expected:  []string{"2:19 1104"},
+		},
+		{
+			name:      "Too many index columns",
+			payload:   map[string]any{"max_index_columns": 2},
+			statement: "CREATE INDEX idx_t_abc ON t (a, b, c);",
+			expected:  []string{"1:1 1105"},
+		},
+		{
+			name:      "Duplicate and redundant indexes",
+			payload:   map[string]any{"max_index_count": 10},
+			statement: "CREATE TABLE t (id INT PRIMARY KEY, a INT, b INT, KEY idx_ab (a, b), KEY idx_a (a), UNIQUE KEY uk_a (a));\nCREATE INDEX idx_ab2 ON t (a, b);\nALTER TABLE t ADD INDEX idx_id (id), ADD INDEX idx_b (b), ADD INDEX idx_b_a (b, a);",
+			expected:  []string{"1:70 1107", "1:85 1106", "2:1 1106", "3:19 1106", "3:63 1107"},
+		},
+		{
+			name:      "Duplicate of an index that made another redundant",
+			payload:   map[string]any{"max_index_count": 10},
+			statement: "CREATE TABLE t (id INT PRIMARY KEY, a INT, b INT, c INT, KEY i1 (a));\nCREATE INDEX i2 ON t (a, b);\nCREATE INDEX i3 ON t (a, b);\nCREATE INDEX i4 ON t (a, b, c);",
+			expected:  []string{"2:1 1107", "3:1 1106", "4:1 1107", "4:1 1107", "4:1 1107"},
+		},
+		{
+			name:      "Dropped index is not redundant",
+			statement: "CREATE TABLE t (id INT PRIMARY KEY, a INT, KEY idx_a (a));\nDROP INDEX idx_a ON t;\nCREATE INDEX idx_a2 ON t (a);",
+		},
+		{
+			name:      "Indexes follow renamed and recreated tables",
+			statement: "CREATE TABLE t (id INT PRIMARY KEY, a INT, KEY idx_a (a));\nRENAME TABLE t TO t2;\nCREATE INDEX idx_a2 ON t2 (a);\nDROP TABLE t2;\nCREATE TABLE t2 (id INT PRIMARY KEY, a INT);\nCREATE INDEX idx_a3 ON t2 (a);",
+			expected:  []string{"3:1 1106"},
+		},
+		{
+			name:      "Low selectivity index",
+			statement: "CREATE TABLE t (id INT PRIMARY KEY, deleted BOOLEAN, status ENUM('a', 'b'), created_at DATETIME, KEY idx_deleted (deleted), KEY idx_status_created (status, created_at));\nALTER TABLE t ADD INDEX idx_status_deleted (status, deleted);",
+			expected:  []string{"1:98 1108", "2:19 1108"},
+		},
+		{
+			name:      "Primary key types",
+			payload:   map[string]any{"primary_key_types": []string{"bigint unsigned"}, "require_primary_key_auto_increment": true},
+			statement: "CREATE TABLE a (id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY);\nCREATE TABLE b (id BIGINT PRIMARY KEY);\nCREATE TABLE c (code VARCHAR(32), PRIMARY KEY (code));",
+			expected:  []string{"2:27 1109", "2:27 1109", "3:35 1109", "3:35 1109", "3:35 1109"},
+		},
+		{
+			name:      "Foreign keys",
+			statement: "CREATE TABLE orders (id INT PRIMARY KEY, user_id INT, CONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users (id));\nALTER TABLE orders ADD COLUMN item_id INT REFERENCES items (id);",
+			expected:  []string{"1:55 1110", "2:43 1110"},
+		},
+		{
+			name:      "Foreign keys allowed",
+			payload:   map[string]any{"forbid_foreign_key": false},
+			statement: "ALTER TABLE orders ADD FOREIGN KEY (user_id) REFERENCES users (id);",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checkRule(t, advisor.MySQLIndexDesign, tt.payload, tt.statement)
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
+
+// TestIndexDesignExistingIndexes 测试与元数据中已有索引的比较
+func TestIndexDesignExistingIndexes(t *testing.T) {
+	rule := &advisor.Rule{Type: advisor.MySQLIndexDesign, Level: advisor.LevelWarning, Engine: advisor.MySQL}
+	checkCtx := advisor.Context{
+		SQL:    "CREATE INDEX idx_users_email ON users (email);\nALTER TABLE users ADD INDEX idx_users_active (active);",
+		Engine: advisor.MySQL,
+		Rule:   rule,
+		Metadata: &advisor.DatabaseMetadata{Tables: map[string]*advisor.TableMetadata{
+			"users": {
+				Name: "users",
+				Columns: map[string]*advisor.ColumnMetadata{
+					"id":     {Name: "id", Type: "bigint(20) unsigned", IsPrimaryKey: true, IsAutoIncr: true},
+					"email":  {Name: "email", Type: "varchar(255)"},
+					"active": {Name: "active", Type: "tinyint(1)"},
+				},
+				Indexes: map[string]*advisor.IndexMetadata{
+					"PRIMARY":  {Name: "PRIMARY", Type: "PRIMARY", Columns: []string{"id"}},
+					"uk_email": {Name: "uk_email", Type: "UNIQUE", Columns: []string{"email"}},
+				},
+			},
+		}},
+	}
+
+	advices, err := advisor.CheckByType(context.Background(), advisor.MySQL, advisor.MySQLIndexDesign, checkCtx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(advices) != 1 || advices[0].Code != advisor.CodeIndexDuplicate || advices[0].StartPosition.Line != 1 {
+		t.Errorf("Expected one duplicate index advice on line 1, got %v", advices)
+	}
+}
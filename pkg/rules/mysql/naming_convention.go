@@ -0,0 +1,397 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/antlr4-go/antlr/v4"
+	mysql "github.com/bytebase/parser/mysql"
+	"github.com/pkg/errors"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+func init() {
+	advisor.Register(advisor.MySQL, advisor.MySQLNamingConvention, &NamingConventionAdvisor{})
+}
+
+// Templates available in index, unique key and foreign key patterns. They
+// are replaced with the names of the index being checked before matching.
+const (
+	templateTable             = "{{table}}"
+	templateColumns           = "{{columns}}"
+	templateReferencedTable   = "{{referenced_table}}"
+	templateReferencedColumns = "{{referenced_columns}}"
+)
+
+var templateRegexp = regexp.MustCompile(`{{[^{}]*}}`)
+
+// NamingConventionPayload is the payload of the naming convention rule.
+// Index, unique key and foreign key patterns may use the templates
+// {{table}} and {{columns}}; foreign key patterns may also use
+// {{referenced_table}} and {{referenced_columns}}. Multiple columns are
+// joined with "_".
+type NamingConventionPayload struct {
+	TablePattern      string `json:"table_pattern" yaml:"table_pattern"`
+	ColumnPattern     string `json:"column_pattern" yaml:"column_pattern"`
+	IndexPattern      string `json:"index_pattern" yaml:"index_pattern"`
+	UniqueKeyPattern  string `json:"unique_key_pattern" yaml:"unique_key_pattern"`
+	ForeignKeyPattern string `json:"foreign_key_pattern" yaml:"foreign_key_pattern"`
+	// MaxLength is the maximum identifier length, 0 disables the check.
+	MaxLength int `json:"max_length" yaml:"max_length" validate:"min=0,max=64"`
+}
+
+// DefaultNamingConventionPayload returns the payload used for options that
+// are not configured.
+func DefaultNamingConventionPayload() *NamingConventionPayload {
+	return &NamingConventionPayload{
+		TablePattern:      "^[a-z]{2,}[a-z0-9]*(_[a-z0-9]+)*$",
+		ColumnPattern:     "^[a-z]{2,}[a-z0-9]*(_[a-z0-9]+)*$",
+		IndexPattern:      "^idx_{{table}}_[a-z0-9_]+$",
+		UniqueKeyPattern:  "^uk_{{table}}_[a-z0-9_]+$",
+		ForeignKeyPattern: "^fk_{{table}}_[a-z0-9_]+$",
+		MaxLength:         64,
+	}
+}
+
+// Validate implements the advisor.PayloadValidator interface. Patterns must
+// only use known templates and compile once the templates are expanded.
+func (p *NamingConventionPayload) Validate() error {
+	for _, pattern := range []string{
+		p.TablePattern,
+		p.ColumnPattern,
+		p.IndexPattern,
+		p.UniqueKeyPattern,
+		p.ForeignKeyPattern,
+	} {
+		if err := validateNamePattern(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NamingConventionAdvisor checks table, column, index, unique key and
+// foreign key names against the configured patterns.
+type NamingConventionAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *NamingConventionAdvisor) Title() string {
+	return "命名规范检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *NamingConventionAdvisor) Description() string {
+	return "表名、列名、索引名、唯一键名和外键名需要符合配置的命名规则，且不能超过最大长度"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *NamingConventionAdvisor) DefaultPayload() any {
+	return DefaultNamingConventionPayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *NamingConventionAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *NamingConventionAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultNamingConventionPayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+
+	if err := payload.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid payload of rule %s", checkCtx.Rule.Type)
+	}
+
+	// Patterns without templates are the same for every name, compile them
+	// once. Templated patterns are compiled per expansion in checkName.
+	patterns := make(map[string]*regexp.Regexp)
+	for _, pattern := range []string{
+		payload.TablePattern,
+		payload.ColumnPattern,
+		payload.IndexPattern,
+		payload.UniqueKeyPattern,
+		payload.ForeignKeyPattern,
+	} {
+		if pattern != "" && !templateRegexp.MatchString(pattern) {
+			patterns[pattern] = regexp.MustCompile(pattern)
+		}
+	}
+
+	return &namingConventionRule{
+		BaseRule: advisor.NewBaseRule(checkCtx.Rule),
+		payload:  payload,
+		patterns: patterns,
+	}, nil
+}
+
+// nameKind describes one kind of checked name.
+type nameKind struct {
+	label string
+	title string
+	code  int32
+}
+
+var (
+	tableNameKind      = nameKind{label: "Table name", title: "Table naming convention", code: advisor.CodeTableNamingInvalid}
+	columnNameKind     = nameKind{label: "Column name", title: "Column naming convention", code: advisor.CodeColumnNamingInvalid}
+	indexNameKind      = nameKind{label: "Index name", title: "Index naming convention", code: advisor.CodeIndexNamingInvalid}
+	uniqueKeyNameKind  = nameKind{label: "Unique key name", title: "Unique key naming convention", code: advisor.CodeUniqueKeyNamingInvalid}
+	foreignKeyNameKind = nameKind{label: "Foreign key name", title: "Foreign key naming convention", code: advisor.CodeForeignKeyNamingInvalid}
+)
+
+// namingConventionRule checks the names introduced by CREATE TABLE, ALTER
+// TABLE, CREATE INDEX and RENAME TABLE.
+type namingConventionRule struct {
+	advisor.BaseRule
+
+	payload *NamingConventionPayload
+	// patterns caches the compiled patterns by their expanded text, so a
+	// templated pattern is compiled once per table or column value.
+	patterns map[string]*regexp.Regexp
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *namingConventionRule) NodeTypes() []string {
+	return []string{
+		nodeTypeCreateTable,
+		nodeTypeColumnDefinition,
+		nodeTypeTableConstraintDef,
+		nodeTypeAlterListItem,
+		nodeTypeCreateIndex,
+		nodeTypeRenamePair,
+	}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *namingConventionRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	switch ctx := ctx.(type) {
+	case *mysql.CreateTableContext:
+		r.checkTableName(ctx.TableName())
+	case *mysql.ColumnDefinitionContext:
+		if ctx.ColumnName() != nil {
+			r.checkName(columnNameKind, r.payload.ColumnPattern, mysqlparser.NormalizeMySQLColumnName(ctx.ColumnName()), nil, ctx.ColumnName().GetStart())
+		}
+	case *mysql.TableConstraintDefContext:
+		r.checkConstraint(ctx)
+	case *mysql.AlterListItemContext:
+		r.checkAlterListItem(ctx)
+	case *mysql.CreateIndexContext:
+		r.checkCreateIndex(ctx)
+	case *mysql.RenamePairContext:
+		r.checkTableName(ctx.TableName())
+	}
+	return nil
+}
+
+func (r *namingConventionRule) checkTableName(ctx mysql.ITableNameContext) {
+	if ctx == nil {
+		return
+	}
+	_, table := mysqlparser.NormalizeMySQLTableName(ctx)
+	r.checkName(tableNameKind, r.payload.TablePattern, table, nil, ctx.GetStart())
+}
+
+// checkAlterListItem checks the names added or renamed by an ALTER TABLE
+// item. ADD COLUMN (...) and ADD constraint items are handled by the
+// ColumnDefinition and TableConstraintDef nodes.
+func (r *namingConventionRule) checkAlterListItem(ctx *mysql.AlterListItemContext) {
+	switch {
+	// ADD COLUMN c INT, CHANGE COLUMN a c INT
+	case (ctx.ADD_SYMBOL() != nil || ctx.CHANGE_SYMBOL() != nil) && ctx.Identifier() != nil && ctx.FieldDefinition() != nil:
+		r.checkName(columnNameKind, r.payload.ColumnPattern, mysqlparser.NormalizeMySQLIdentifier(ctx.Identifier()), nil, ctx.Identifier().GetStart())
+	// RENAME COLUMN a TO c
+	case ctx.RENAME_SYMBOL() != nil && ctx.COLUMN_SYMBOL() != nil && ctx.Identifier() != nil:
+		r.checkName(columnNameKind, r.payload.ColumnPattern, mysqlparser.NormalizeMySQLIdentifier(ctx.Identifier()), nil, ctx.Identifier().GetStart())
+	// RENAME TO t
+	case ctx.RENAME_SYMBOL() != nil && ctx.TableName() != nil:
+		r.checkTableName(ctx.TableName())
+	// RENAME INDEX a TO b, the columns of the index are unknown here.
+	case ctx.RENAME_SYMBOL() != nil && ctx.IndexName() != nil:
+		values := map[string]string{templateTable: enclosingTableName(ctx)}
+		r.checkName(indexNameKind, r.payload.IndexPattern, mysqlparser.NormalizeMySQLIdentifier(ctx.IndexName().Identifier()), values, ctx.IndexName().GetStart())
+	}
+}
+
+func (r *namingConventionRule) checkConstraint(ctx *mysql.TableConstraintDefContext) {
+	if ctx.GetType_() == nil {
+		return
+	}
+
+	values := map[string]string{
+		templateTable:   enclosingTableName(ctx),
+		templateColumns: strings.Join(mysqlparser.NormalizeMySQLKeyListVariants(ctx.KeyListVariants()), "_"),
+	}
+
+	switch ctx.GetType_().GetTokenType() {
+	case mysql.MySQLParserKEY_SYMBOL, mysql.MySQLParserINDEX_SYMBOL, mysql.MySQLParserFULLTEXT_SYMBOL, mysql.MySQLParserSPATIAL_SYMBOL:
+		if name := constraintIndexName(ctx); name != nil {
+			r.checkName(indexNameKind, r.payload.IndexPattern, mysqlparser.NormalizeMySQLIdentifier(name), values, name.GetStart())
+		}
+	case mysql.MySQLParserUNIQUE_SYMBOL:
+		if name := constraintIndexName(ctx); name != nil {
+			r.checkName(uniqueKeyNameKind, r.payload.UniqueKeyPattern, mysqlparser.NormalizeMySQLIdentifier(name), values, name.GetStart())
+		}
+	case mysql.MySQLParserFOREIGN_SYMBOL:
+		var columns []string
+		if ctx.KeyList() != nil {
+			for _, keyPart := range ctx.KeyList().AllKeyPart() {
+				columns = append(columns, mysqlparser.NormalizeMySQLIdentifier(keyPart.Identifier()))
+			}
+		}
+		values[templateColumns] = strings.Join(columns, "_")
+		if references := ctx.References(); references != nil {
+			_, values[templateReferencedTable] = mysqlparser.NormalizeMySQLTableRef(references.TableRef())
+			if references.IdentifierListWithParentheses() != nil {
+				values[templateReferencedColumns] = strings.Join(mysqlparser.NormalizeMySQLIdentifierList(references.IdentifierListWithParentheses().IdentifierList()), "_")
+			}
+		}
+		if name := constraintIndexName(ctx); name != nil {
+			r.checkName(foreignKeyNameKind, r.payload.ForeignKeyPattern, mysqlparser.NormalizeMySQLIdentifier(name), values, name.GetStart())
+		}
+	}
+}
+
+func (r *namingConventionRule) checkCreateIndex(ctx *mysql.CreateIndexContext) {
+	var name mysql.IIdentifierContext
+	switch {
+	case ctx.IndexName() != nil:
+		name = ctx.IndexName().Identifier()
+	case ctx.IndexNameAndType() != nil:
+		name = ctx.IndexNameAndType().IndexName().Identifier()
+	}
+	if name == nil || ctx.CreateIndexTarget() == nil {
+		return
+	}
+
+	_, table := mysqlparser.NormalizeMySQLTableRef(ctx.CreateIndexTarget().TableRef())
+	values := map[string]string{
+		templateTable:   table,
+		templateColumns: strings.Join(mysqlparser.NormalizeMySQLKeyListVariants(ctx.CreateIndexTarget().KeyListVariants()), "_"),
+	}
+	if ctx.UNIQUE_SYMBOL() != nil {
+		r.checkName(uniqueKeyNameKind, r.payload.UniqueKeyPattern, mysqlparser.NormalizeMySQLIdentifier(name), values, name.GetStart())
+		return
+	}
+	r.checkName(indexNameKind, r.payload.IndexPattern, mysqlparser.NormalizeMySQLIdentifier(name), values, name.GetStart())
+}
+
+// checkName checks the length of name and matches it against pattern with
+// the templates replaced by values. A pattern using a template without a
+// value is skipped.
+func (r *namingConventionRule) checkName(kind nameKind, pattern, name string, values map[string]string, token antlr.Token) {
+	if name == "" {
+		return
+	}
+
+	if r.payload.MaxLength > 0 && utf8.RuneCountInString(name) > r.payload.MaxLength {
+		r.AddAdvice(kind.code, kind.title, fmt.Sprintf("%s `%s` is longer than %d characters", kind.label, name, r.payload.MaxLength), token)
+	}
+
+	if pattern == "" {
+		return
+	}
+	expanded, ok := expandNamePattern(pattern, values)
+	if !ok {
+		return
+	}
+	if !r.compile(expanded).MatchString(name) {
+		r.AddAdvice(kind.code, kind.title, fmt.Sprintf("%s `%s` mismatches the naming convention %q", kind.label, name, expanded), token)
+	}
+}
+
+// compile returns the compiled expanded pattern, compiling it on first use.
+// Patterns are validated in NewListener, and template values are quoted.
+func (r *namingConventionRule) compile(expanded string) *regexp.Regexp {
+	re, ok := r.patterns[expanded]
+	if !ok {
+		re = regexp.MustCompile(expanded)
+		r.patterns[expanded] = re
+	}
+	return re
+}
+
+// expandNamePattern replaces the templates in pattern with the quoted
+// values. It reports false if a template has no value.
+func expandNamePattern(pattern string, values map[string]string) (string, bool) {
+	ok := true
+	expanded := templateRegexp.ReplaceAllStringFunc(pattern, func(template string) string {
+		value, found := values[template]
+		if !found || value == "" {
+			ok = false
+			return template
+		}
+		return regexp.QuoteMeta(value)
+	})
+	return expanded, ok
+}
+
+// validateNamePattern reports unknown templates and invalid regular
+// expressions in pattern.
+func validateNamePattern(pattern string) error {
+	values := map[string]string{
+		templateTable:             "t",
+		templateColumns:           "c",
+		templateReferencedTable:   "t",
+		templateReferencedColumns: "c",
+	}
+	expanded, ok := expandNamePattern(pattern, values)
+	if !ok {
+		return errors.Errorf("unknown template in pattern %q", pattern)
+	}
+	if _, err := regexp.Compile(expanded); err != nil {
+		return errors.Wrapf(err, "invalid pattern %q", pattern)
+	}
+	return nil
+}
+
+// constraintIndexName returns the name identifier of an index or key
+// constraint. The index name wins over the CONSTRAINT symbol, except for
+// foreign keys where the symbol names the key.
+func constraintIndexName(ctx *mysql.TableConstraintDefContext) mysql.IIdentifierContext {
+	var symbol mysql.IIdentifierContext
+	if ctx.ConstraintName() != nil {
+		symbol = ctx.ConstraintName().Identifier()
+	}
+	if symbol != nil && ctx.GetType_().GetTokenType() == mysql.MySQLParserFOREIGN_SYMBOL {
+		return symbol
+	}
+	if ctx.IndexNameAndType() != nil {
+		return ctx.IndexNameAndType().IndexName().Identifier()
+	}
+	if ctx.IndexName() != nil {
+		return ctx.IndexName().Identifier()
+	}
+	return symbol
+}
+
+// enclosingTableName returns the name of the table created or altered by
+// the statement that contains ctx.
+func enclosingTableName(ctx antlr.ParserRuleContext) string {
+	for node := ctx; node != nil; {
+		switch node := node.(type) {
+		case *mysql.CreateTableContext:
+			_, table := mysqlparser.NormalizeMySQLTableName(node.TableName())
+			return table
+		case *mysql.AlterTableContext:
+			_, table := mysqlparser.NormalizeMySQLTableRef(node.TableRef())
+			return table
+		}
+		parent, ok := node.GetParent().(antlr.ParserRuleContext)
+		if !ok {
+			break
+		}
+		node = parent
+	}
+	return ""
+}
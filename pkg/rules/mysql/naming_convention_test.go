@@ -0,0 +1,83 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+// TestNamingConvention 测试命名规范检查
+func TestNamingConvention(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   any
+		statement string
+		expected  []string
+	}{
+		{
+			name:      "Good names",
+			statement: "CREATE TABLE user_profiles (id INT PRIMARY KEY, user_name VARCHAR(50), INDEX idx_user_profiles_name (user_name));",
+		},
+		{
+			name:      "Bad table and column names",
+			statement: "CREATE TABLE t1 (\n    id INT PRIMARY KEY,\n    n VARCHAR(50),\n    e VARCHAR(100)\n);",
+			expected:  []string{"1:14 802", "3:5 902", "4:5 902"},
+		},
+		{
+			name:      "Create index",
+			statement: "CREATE INDEX ix1 ON bad_users(email);\nCREATE UNIQUE INDEX uk_bad_users_email ON bad_users(email);",
+			expected:  []string{"1:14 1101"},
+		},
+		{
+			name:      "Alter table",
+			statement: "ALTER TABLE users ADD COLUMN Nick VARCHAR(20), ADD UNIQUE KEY email_uk (email), RENAME COLUMN nick TO x1, RENAME TO U;",
+			expected:  []string{"1:30 902", "1:63 1102", "1:103 902", "1:117 802"},
+		},
+		{
+			name:      "Rename table",
+			statement: "RENAME TABLE users TO Users, orders TO order_list;",
+			expected:  []string{"1:23 802"},
+		},
+		{
+			name:      "Foreign key templates",
+			payload:   map[string]any{"foreign_key_pattern": "^fk_{{table}}_{{columns}}_{{referenced_table}}_{{referenced_columns}}$"},
+			statement: "CREATE TABLE orders (id INT PRIMARY KEY, user_id INT,\n  CONSTRAINT fk_orders_user_id_users_id FOREIGN KEY (user_id) REFERENCES users (id),\n  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id));",
+			expected:  []string{"3:14 1103"},
+		},
+		{
+			name:      "Index templates",
+			payload:   map[string]any{"index_pattern": "^idx_{{table}}_{{columns}}$"},
+			statement: "CREATE INDEX idx_orders_user_id_created_at ON orders (user_id, created_at);\nCREATE INDEX idx_orders_user ON orders (user_id);",
+			expected:  []string{"2:14 1101"},
+		},
+		{
+			name:      "Max length",
+			payload:   map[string]any{"max_length": 10},
+			statement: "CREATE TABLE very_long_table_name (id INT PRIMARY KEY);",
+			expected:  []string{"1:14 802"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checkRule(t, advisor.MySQLNamingConvention, tt.payload, tt.statement)
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
+
+// TestNamingConventionInvalidPattern 测试非法的命名规则配置
+func TestNamingConventionInvalidPattern(t *testing.T) {
+	rule := &advisor.Rule{
+		Type:    advisor.MySQLNamingConvention,
+		Level:   advisor.LevelWarning,
+		Engine:  advisor.MySQL,
+		Payload: map[string]any{"index_pattern": "^idx_{{tables}}$"},
+	}
+	if _, err := (&NamingConventionAdvisor{}).NewListener(advisor.Context{Rule: rule}); err == nil {
+		t.Errorf("Expected error for unknown template")
+	}
+}
@@ -0,0 +1,46 @@
+package mysql
+
+import (
+	"github.com/antlr4-go/antlr/v4"
+	mysql "github.com/bytebase/parser/mysql"
+)
+
+// Node types the MySQL rules listen to, see advisor.NodeType.
+const (
+	nodeTypeAlterListItem          = "AlterListItem"
+	nodeTypeColumnDefinition       = "ColumnDefinition"
+	nodeTypeCreateIndex            = "CreateIndex"
+	nodeTypeCreateTable            = "CreateTable"
+	nodeTypeCreateTableOption      = "CreateTableOption"
+	nodeTypeDeleteStatement        = "DeleteStatement"
+	nodeTypeDropDatabase           = "DropDatabase"
+	nodeTypeDropIndex              = "DropIndex"
+	nodeTypeInsertStatement        = "InsertStatement"
+	nodeTypeOrderExpression        = "OrderExpression"
+	nodeTypePartitionClause        = "PartitionClause"
+	nodeTypeQueryExpression        = "QueryExpression"
+	nodeTypeRenamePair             = "RenamePair"
+	nodeTypeReplaceStatement       = "ReplaceStatement"
+	nodeTypeSelectItemList         = "SelectItemList"
+	nodeTypeTableConstraintDef     = "TableConstraintDef"
+	nodeTypeTruncateTableStatement = "TruncateTableStatement"
+	nodeTypeUpdateStatement        = "UpdateStatement"
+	nodeTypeWhereClause            = "WhereClause"
+)
+
+// statementStart returns the first token of the statement that contains ctx,
+// e.g. the CREATE keyword of a CREATE TABLE node.
+func statementStart(ctx antlr.ParserRuleContext) antlr.Token {
+	node := ctx
+	for node != nil {
+		if _, ok := node.(*mysql.SimpleStatementContext); ok {
+			return node.GetStart()
+		}
+		parent, ok := node.GetParent().(antlr.ParserRuleContext)
+		if !ok {
+			break
+		}
+		node = parent
+	}
+	return ctx.GetStart()
+}
@@ -0,0 +1,28 @@
+package mysql
+
+import (
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+// DefaultRules returns the MySQL rules enabled by default with their levels.
+func DefaultRules() []*advisor.Rule {
+	return []*advisor.Rule{
+		{Type: advisor.MySQLTableRequirePK, Level: advisor.LevelError, Engine: advisor.MySQL},
+		{Type: advisor.MySQLNamingConvention, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLStatementSafety, Level: advisor.LevelError, Engine: advisor.MySQL},
+		{Type: advisor.MySQLColumnTypeCheck, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLSelectPerformance, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLStatementInsert, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLIndexDesign, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLColumnConstraint, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLTableEngine, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLTableCharset, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLTableComment, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLTablePartition, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLTableNamePrefix, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLTableCreateAsSelect, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLTableCreateLike, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+		{Type: advisor.MySQLSchemaConsistency, Level: advisor.LevelError, Engine: advisor.MySQL},
+		{Type: advisor.MySQLSchemaWalkThrough, Level: advisor.LevelError, Engine: advisor.MySQL},
+	}
+}
@@ -0,0 +1,138 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/antlr4-go/antlr/v4"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+// checkRule 对 SQL 执行单个规则，返回 "行:列 错误码" 形式的结果
+func checkRule(t *testing.T, ruleType advisor.Type, payload any, statement string) []string {
+	t.Helper()
+
+	rule := &advisor.Rule{Type: ruleType, Level: advisor.LevelWarning, Engine: advisor.MySQL, Payload: payload}
+	advices, err := advisor.CheckByType(context.Background(), advisor.MySQL, ruleType, advisor.Context{
+		SQL:    statement,
+		Engine: advisor.MySQL,
+		Rule:   rule,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var results []string
+	for _, advice := range advices {
+		results = append(results, fmt.Sprintf("%d:%d %d", advice.StartPosition.Line, advice.StartPosition.Column, advice.Code))
+	}
+	return results
+}
+
+// TestRulesStopWhenContextDone 测试规则的回调在 context 结束后返回 context 的错误
+func TestRulesStopWhenContextDone(t *testing.T) {
+	stmts, err := mysqlparser.ParseMySQL("CREATE TABLE t (id INT);")
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := stmts[0].Tree.(antlr.ParserRuleContext)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	advisors := map[advisor.Type]advisor.ListenerAdvisor{
+		advisor.MySQLColumnConstraint:    &ColumnConstraintAdvisor{},
+		advisor.MySQLColumnTypeCheck:     &ColumnTypeCheckAdvisor{},
+		advisor.MySQLIndexDesign:         &IndexDesignAdvisor{},
+		advisor.MySQLNamingConvention:    &NamingConventionAdvisor{},
+		advisor.MySQLSchemaConsistency:   &SchemaConsistencyAdvisor{},
+		advisor.MySQLSelectPerformance:   &SelectPerformanceAdvisor{},
+		advisor.MySQLStatementInsert:     &StatementInsertAdvisor{},
+		advisor.MySQLStatementSafety:     &StatementSafetyAdvisor{},
+		advisor.MySQLTableEngine:         &TableEngineAdvisor{},
+		advisor.MySQLTableCharset:        &TableCharsetAdvisor{},
+		advisor.MySQLTableComment:        &TableCommentAdvisor{},
+		advisor.MySQLTablePartition:      &TablePartitionAdvisor{},
+		advisor.MySQLTableNamePrefix:     &TableNamePrefixAdvisor{},
+		advisor.MySQLTableCreateAsSelect: &TableCreateAsSelectAdvisor{},
+		advisor.MySQLTableCreateLike:     &TableCreateLikeAdvisor{},
+		advisor.MySQLTableRequirePK:      &TableRequirePKAdvisor{},
+	}
+	for ruleType, listenerAdvisor := range advisors {
+		t.Run(string(ruleType), func(t *testing.T) {
+			rule := &advisor.Rule{Type: ruleType, Level: advisor.LevelWarning, Engine: advisor.MySQL}
+			listener, err := listenerAdvisor.NewListener(advisor.Context{Engine: advisor.MySQL, Rule: rule})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			listener.SetContext(ctx)
+			listener.SetBaseLine(0)
+			if err := listener.OnEnter(node, advisor.NodeType(node)); !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected context.Canceled, got %v", err)
+			}
+		})
+	}
+}
+
+// TestTableRequirePK 测试主键检查基于语法树：注释和字符串中的 PRIMARY KEY 不算主键，CREATE TABLE ... LIKE 跳过
+func TestTableRequirePK(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		expected  []string
+	}{
+		{
+			name:      "Column primary key",
+			statement: "CREATE TABLE t (id INT PRIMARY KEY);",
+		},
+		{
+			name:      "Table constraint",
+			statement: "CREATE TABLE t (id INT, CONSTRAINT pk_t PRIMARY KEY (id));",
+		},
+		{
+			name:      "Bare KEY attribute",
+			statement: "CREATE TABLE t (id INT KEY);",
+		},
+		{
+			name:      "PRIMARY KEY in a column comment",
+			statement: "CREATE TABLE t (id INT COMMENT 'PRIMARY KEY');",
+			expected:  []string{"1:1 801"},
+		},
+		{
+			name:      "PRIMARY KEY in a default value",
+			statement: "CREATE TABLE t (id INT, note VARCHAR(20) DEFAULT 'primary key');",
+			expected:  []string{"1:1 801"},
+		},
+		{
+			name:      "Unique key only",
+			statement: "CREATE TABLE t (id INT, UNIQUE KEY uk_id (id));",
+			expected:  []string{"1:1 801"},
+		},
+		{
+			name:      "CREATE TABLE LIKE",
+			statement: "CREATE TABLE t_copy LIKE t;",
+		},
+		{
+			name:      "CREATE TABLE LIKE in parentheses",
+			statement: "CREATE TABLE t_copy (LIKE t);",
+		},
+		{
+			name:      "Position of the second statement",
+			statement: "CREATE TABLE a (id INT PRIMARY KEY);\n  CREATE TABLE b (id INT);",
+			expected:  []string{"2:3 801"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checkRule(t, advisor.MySQLTableRequirePK, nil, tt.statement)
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+	mysql "github.com/bytebase/parser/mysql"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/catalog"
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+func init() {
+	advisor.Register(advisor.MySQL, advisor.MySQLSchemaConsistency, &SchemaConsistencyAdvisor{})
+}
+
+// SchemaConsistencyPayload is the payload of the schema consistency rule.
+type SchemaConsistencyPayload struct {
+	// CheckDropIndexedColumn flags DROP COLUMN of a column that is part of
+	// an index.
+	CheckDropIndexedColumn bool `json:"check_drop_indexed_column" yaml:"check_drop_indexed_column"`
+	// CheckAddNotNullColumn flags adding a NOT NULL column without DEFAULT
+	// to a table that has rows.
+	CheckAddNotNullColumn bool `json:"check_add_not_null_column" yaml:"check_add_not_null_column"`
+}
+
+// DefaultSchemaConsistencyPayload returns the payload used for options that
+// are not configured.
+func DefaultSchemaConsistencyPayload() *SchemaConsistencyPayload {
+	return &SchemaConsistencyPayload{
+		CheckDropIndexedColumn: true,
+		CheckAddNotNullColumn:  true,
+	}
+}
+
+// SchemaConsistencyAdvisor checks DDL against the schema of the database as
+// of each statement, see advisor.Context.Catalog. Statements that cannot
+// run at all, such as ALTER TABLE on a table that does not exist, are
+// reported by the catalog walk-through instead.
+type SchemaConsistencyAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *SchemaConsistencyAdvisor) Title() string {
+	return "Schema 一致性检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *SchemaConsistencyAdvisor) Description() string {
+	return "对照当前语句执行前的 schema 检查 DDL：删除索引中的列、向非空表添加无默认值的 NOT NULL 列"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *SchemaConsistencyAdvisor) DefaultPayload() any {
+	return DefaultSchemaConsistencyPayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *SchemaConsistencyAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *SchemaConsistencyAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultSchemaConsistencyPayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+	return &schemaConsistencyRule{
+		BaseRule: advisor.NewBaseRule(checkCtx.Rule),
+		payload:  payload,
+	}, nil
+}
+
+// schemaConsistencyRule checks ALTER TABLE against the catalog.
+type schemaConsistencyRule struct {
+	advisor.BaseRule
+
+	payload *SchemaConsistencyPayload
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *schemaConsistencyRule) NodeTypes() []string {
+	return []string{nodeTypeAlterListItem}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *schemaConsistencyRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	item, ok := ctx.(*mysql.AlterListItemContext)
+	if !ok || r.Catalog() == nil {
+		return nil
+	}
+	table := r.Catalog().Table("", enclosingTableName(item))
+	if table == nil {
+		return nil
+	}
+
+	switch {
+	// DROP COLUMN c
+	case item.DROP_SYMBOL() != nil && item.FOREIGN_SYMBOL() == nil && item.ColumnInternalRef() != nil:
+		r.checkDropColumn(table, mysqlparser.NormalizeMySQLIdentifier(item.ColumnInternalRef().Identifier()), item.GetStart())
+	// ADD COLUMN c INT NOT NULL
+	case item.ADD_SYMBOL() != nil && item.Identifier() != nil && item.FieldDefinition() != nil:
+		r.checkAddColumn(table, mysqlparser.NormalizeMySQLIdentifier(item.Identifier()), item.FieldDefinition(), item.GetStart())
+	// ADD COLUMN (c INT NOT NULL, ...)
+	case item.ADD_SYMBOL() != nil && item.TableElementList() != nil:
+		for _, element := range item.TableElementList().AllTableElement() {
+			if column := element.ColumnDefinition(); column != nil {
+				r.checkAddColumn(table, mysqlparser.NormalizeMySQLColumnName(column.ColumnName()), column.FieldDefinition(), column.GetStart())
+			}
+		}
+	}
+	return nil
+}
+
+func (r *schemaConsistencyRule) checkDropColumn(table *catalog.Table, column string, token antlr.Token) {
+	if !r.payload.CheckDropIndexedColumn {
+		return
+	}
+	var indexes []string
+	for _, index := range table.IndexesOf(column) {
+		indexes = append(indexes, index.Name)
+	}
+	if len(indexes) == 0 {
+		return
+	}
+	sort.Strings(indexes)
+	r.AddAdvice(
+		advisor.CodeDropIndexedColumn,
+		"Drop indexed column",
+		fmt.Sprintf("Column `%s`.`%s` is part of index %s, dropping it changes or drops the index", table.Name, column, strings.Join(indexes, ", ")),
+		token,
+	)
+}
+
+func (r *schemaConsistencyRule) checkAddColumn(table *catalog.Table, column string, definition mysql.IFieldDefinitionContext, token antlr.Token) {
+	if !r.payload.CheckAddNotNullColumn || table.RowCount == 0 || definition == nil {
+		return
+	}
+	attributes := columnAttributesOf(definition)
+	if !attributes.notNull || attributes.hasDefault || attributes.autoIncrement || definition.AS_SYMBOL() != nil {
+		return
+	}
+	r.AddAdvice(
+		advisor.CodeAddNotNullColumnToNonEmptyTable,
+		"NOT NULL column without default",
+		fmt.Sprintf("Adding NOT NULL column `%s` without DEFAULT to table `%s` with about %d rows fills the existing rows with an implicit default", column, table.Name, table.RowCount),
+		token,
+	)
+}
@@ -0,0 +1,92 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+// TestSchemaConsistency 测试对照数据库元数据的 DDL 检查
+func TestSchemaConsistency(t *testing.T) {
+	metadata := &advisor.DatabaseMetadata{Tables: map[string]*advisor.TableMetadata{
+		"users": {
+			Name: "users",
+			Columns: map[string]*advisor.ColumnMetadata{
+				"id":    {Name: "id", Type: "bigint", IsPrimaryKey: true, IsAutoIncr: true},
+				"email": {Name: "email", Type: "varchar(255)"},
+				"name":  {Name: "name", Type: "varchar(50)"},
+			},
+			Indexes: map[string]*advisor.IndexMetadata{
+				"PRIMARY":  {Name: "PRIMARY", Type: "PRIMARY", Columns: []string{"id"}},
+				"uk_email": {Name: "uk_email", Type: "UNIQUE", Columns: []string{"email"}},
+			},
+			RowCount: 1000,
+		},
+		"logs": {
+			Name:    "logs",
+			Columns: map[string]*advisor.ColumnMetadata{"id": {Name: "id", Type: "bigint"}},
+			Indexes: map[string]*advisor.IndexMetadata{},
+		},
+	}}
+
+	tests := []struct {
+		name      string
+		metadata  *advisor.DatabaseMetadata
+		statement string
+		expected  []string
+	}{
+		{
+			name:      "Without metadata",
+			statement: "ALTER TABLE users DROP COLUMN email;\nALTER TABLE users ADD COLUMN a INT NOT NULL;",
+		},
+		{
+			name:      "Table created by the script",
+			statement: "CREATE TABLE t (id INT PRIMARY KEY, a INT, KEY idx_a (a));\nALTER TABLE t DROP COLUMN a;\nALTER TABLE t DROP COLUMN a;",
+			expected:  []string{"2:15 1303"},
+		},
+		{
+			name:      "Table renamed by the script",
+			metadata:  metadata,
+			statement: "RENAME TABLE users TO members;\nALTER TABLE members DROP COLUMN email, ADD COLUMN a INT NOT NULL;",
+			expected:  []string{"2:21 1303", "2:40 1304"},
+		},
+		{
+			name:      "Drop indexed column",
+			metadata:  metadata,
+			statement: "ALTER TABLE users DROP COLUMN email, DROP COLUMN name;\nALTER TABLE users DROP id;",
+			expected:  []string{"1:19 1303", "2:19 1303"},
+		},
+		{
+			name:      "Add NOT NULL column to non-empty table",
+			metadata:  metadata,
+			statement: "ALTER TABLE users ADD COLUMN a INT NOT NULL, ADD COLUMN b INT NOT NULL DEFAULT 0, ADD COLUMN c INT;\nALTER TABLE users ADD (d INT NOT NULL, e INT AS (id + 1) NOT NULL);\nALTER TABLE logs ADD COLUMN a INT NOT NULL;",
+			expected:  []string{"1:19 1304", "2:24 1304"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkCtx := advisor.Context{
+				SQL:          tt.statement,
+				Engine:       advisor.MySQL,
+				DatabaseName: "app",
+				Rule:         &advisor.Rule{Type: advisor.MySQLSchemaConsistency, Level: advisor.LevelWarning, Engine: advisor.MySQL},
+				Metadata:     tt.metadata,
+			}
+			advices, err := advisor.CheckByType(context.Background(), advisor.MySQL, advisor.MySQLSchemaConsistency, checkCtx)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var results []string
+			for _, advice := range advices {
+				results = append(results, fmt.Sprintf("%d:%d %d", advice.StartPosition.Line, advice.StartPosition.Column, advice.Code))
+			}
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
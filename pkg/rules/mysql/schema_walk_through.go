@@ -0,0 +1,81 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/catalog"
+)
+
+func init() {
+	advisor.Register(advisor.MySQL, advisor.MySQLSchemaWalkThrough, &SchemaWalkThroughAdvisor{})
+}
+
+// SchemaWalkThroughPayload is the payload of the schema walk-through rule.
+type SchemaWalkThroughPayload struct {
+	// CheckTableExists flags statements on tables that do not exist, such
+	// as ALTER TABLE and CREATE INDEX.
+	CheckTableExists bool `json:"check_table_exists" yaml:"check_table_exists"`
+	// CheckTableNotExists flags CREATE TABLE without IF NOT EXISTS on a
+	// table that already exists.
+	CheckTableNotExists bool `json:"check_table_not_exists" yaml:"check_table_not_exists"`
+}
+
+// DefaultSchemaWalkThroughPayload returns the payload used for options that
+// are not configured.
+func DefaultSchemaWalkThroughPayload() *SchemaWalkThroughPayload {
+	return &SchemaWalkThroughPayload{
+		CheckTableExists:    true,
+		CheckTableNotExists: true,
+	}
+}
+
+// SchemaWalkThroughAdvisor reports the statements that fail when the script
+// is applied to the catalog, see advisor.WalkThroughAdvisor. Errors on
+// columns, indexes and primary keys are always reported; errors on tables
+// can be turned off in the payload.
+type SchemaWalkThroughAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *SchemaWalkThroughAdvisor) Title() string {
+	return "Schema 推演检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *SchemaWalkThroughAdvisor) Description() string {
+	return "将脚本逐条应用到 schema catalog，报告无法执行的语句：表不存在、表已存在、列或索引已存在或不存在、重复定义主键"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *SchemaWalkThroughAdvisor) DefaultPayload() any {
+	return DefaultSchemaWalkThroughPayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *SchemaWalkThroughAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWalkThrough(ctx, a, checkCtx)
+}
+
+// WalkThroughAdvices implements the advisor.WalkThroughAdvisor interface.
+func (a *SchemaWalkThroughAdvisor) WalkThroughAdvices(rule *advisor.Rule, walkThroughErrors []*catalog.WalkThroughError) ([]*advisor.Advice, error) {
+	payload := DefaultSchemaWalkThroughPayload()
+	if err := rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+
+	var reported []*catalog.WalkThroughError
+	for _, walkThroughErr := range walkThroughErrors {
+		switch walkThroughErr.Type {
+		case catalog.ErrorTableNotExists:
+			if !payload.CheckTableExists {
+				continue
+			}
+		case catalog.ErrorTableExists:
+			if !payload.CheckTableNotExists {
+				continue
+			}
+		}
+		reported = append(reported, walkThroughErr)
+	}
+	return advisor.NewWalkThroughAdvices(rule, reported), nil
+}
@@ -0,0 +1,42 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+// TestSchemaWalkThrough 测试推演错误按规则参数报告，表是否存在的检查可以关闭
+func TestSchemaWalkThrough(t *testing.T) {
+	statement := "CREATE TABLE t (id INT);\nCREATE TABLE t (id INT);\nALTER TABLE t ADD COLUMN id INT;\nDROP TABLE t;\nALTER TABLE t ADD COLUMN a INT;"
+	tests := []struct {
+		name     string
+		payload  any
+		expected []string
+	}{
+		{
+			name:     "Default",
+			expected: []string{"2:1 1302", "3:15 1305", "5:1 1301"},
+		},
+		{
+			name:     "Table exists not checked",
+			payload:  map[string]any{"check_table_not_exists": false},
+			expected: []string{"3:15 1305", "5:1 1301"},
+		},
+		{
+			name:     "Missing table not checked",
+			payload:  map[string]any{"check_table_exists": false},
+			expected: []string{"2:1 1302", "3:15 1305"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checkRule(t, advisor.MySQLSchemaWalkThrough, tt.payload, statement)
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
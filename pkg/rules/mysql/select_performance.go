@@ -0,0 +1,369 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+	mysql "github.com/bytebase/parser/mysql"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+func init() {
+	advisor.Register(advisor.MySQL, advisor.MySQLSelectPerformance, &SelectPerformanceAdvisor{})
+}
+
+// SelectPerformancePayload is the payload of the SELECT performance rule.
+// Numeric limits of 0 disable the check.
+type SelectPerformancePayload struct {
+	ForbidSelectStar bool `json:"forbid_select_star" yaml:"forbid_select_star"`
+	MaxLimit         int  `json:"max_limit" yaml:"max_limit" validate:"min=0"`
+	// RequireLimit flags top-level SELECTs that read a table without WHERE
+	// and without LIMIT.
+	RequireLimit              bool `json:"require_limit" yaml:"require_limit"`
+	MaxOffset                 int  `json:"max_offset" yaml:"max_offset" validate:"min=0"`
+	ForbidLeadingWildcardLike bool `json:"forbid_leading_wildcard_like" yaml:"forbid_leading_wildcard_like"`
+	ForbidOrderByRand         bool `json:"forbid_order_by_rand" yaml:"forbid_order_by_rand"`
+	// ForbidFunctionOnColumn flags functions applied to columns in WHERE,
+	// e.g. DATE(created_at) = ..., and RAND() in WHERE.
+	ForbidFunctionOnColumn bool `json:"forbid_function_on_column" yaml:"forbid_function_on_column"`
+	// ForbidOrAcrossColumns flags OR conditions on different columns.
+	ForbidOrAcrossColumns bool `json:"forbid_or_across_columns" yaml:"forbid_or_across_columns"`
+	MaxInListSize         int  `json:"max_in_list_size" yaml:"max_in_list_size" validate:"min=0"`
+}
+
+// DefaultSelectPerformancePayload returns the payload used for options that
+// are not configured.
+func DefaultSelectPerformancePayload() *SelectPerformancePayload {
+	return &SelectPerformancePayload{
+		ForbidSelectStar:          true,
+		MaxLimit:                  1000,
+		RequireLimit:              true,
+		MaxOffset:                 10000,
+		ForbidLeadingWildcardLike: true,
+		ForbidOrderByRand:         true,
+		ForbidFunctionOnColumn:    true,
+		ForbidOrAcrossColumns:     true,
+		MaxInListSize:             100,
+	}
+}
+
+// SelectPerformanceAdvisor checks SELECT statements for patterns that read
+// more rows or columns than needed or cannot use an index.
+type SelectPerformanceAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *SelectPerformanceAdvisor) Title() string {
+	return "SELECT 性能检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *SelectPerformanceAdvisor) Description() string {
+	return "检查 SELECT *、LIMIT 过大或缺失、深分页、前导通配符 LIKE、ORDER BY RAND()、WHERE 中对列使用函数、跨列 OR 和过长的 IN 列表"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *SelectPerformanceAdvisor) DefaultPayload() any {
+	return DefaultSelectPerformancePayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *SelectPerformanceAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *SelectPerformanceAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultSelectPerformancePayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+	return &selectPerformanceRule{
+		BaseRule: advisor.NewBaseRule(checkCtx.Rule),
+		payload:  payload,
+	}, nil
+}
+
+// selectPerformanceRule checks select lists, LIMIT clauses, ORDER BY and
+// the WHERE clauses of queries.
+type selectPerformanceRule struct {
+	advisor.BaseRule
+
+	payload *SelectPerformancePayload
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *selectPerformanceRule) NodeTypes() []string {
+	return []string{
+		nodeTypeSelectItemList,
+		nodeTypeQueryExpression,
+		nodeTypeOrderExpression,
+		nodeTypeWhereClause,
+	}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *selectPerformanceRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	switch ctx := ctx.(type) {
+	case *mysql.SelectItemListContext:
+		r.checkSelectStar(ctx)
+	case *mysql.QueryExpressionContext:
+		r.checkLimit(ctx)
+	case *mysql.OrderExpressionContext:
+		if r.payload.ForbidOrderByRand && ctx.Expr() != nil && strings.HasPrefix(strings.ToUpper(ctx.Expr().GetText()), "RAND(") {
+			r.addPerformanceAdvice("ORDER BY RAND()", "ORDER BY RAND() sorts the whole result set", ctx.GetStart())
+		}
+	case *mysql.WhereClauseContext:
+		// WHERE of UPDATE and DELETE is left to the statement safety rule.
+		if _, ok := ctx.GetParent().(*mysql.QuerySpecificationContext); ok && ctx.Expr() != nil {
+			r.checkCondition(ctx.Expr())
+		}
+	}
+	return nil
+}
+
+func (r *selectPerformanceRule) checkSelectStar(ctx *mysql.SelectItemListContext) {
+	if !r.payload.ForbidSelectStar || inExistsSubquery(ctx) {
+		return
+	}
+	if star := ctx.MULT_OPERATOR(); star != nil {
+		r.addSelectAllAdvice(star.GetSymbol())
+	}
+	for _, item := range ctx.AllSelectItem() {
+		if item.TableWild() != nil {
+			r.addSelectAllAdvice(item.GetStart())
+		}
+	}
+}
+
+func (r *selectPerformanceRule) addSelectAllAdvice(token antlr.Token) {
+	r.AddAdvice(advisor.CodeStatementSelectAll, "SELECT *", "SELECT * returns all columns, list the needed columns instead", token)
+}
+
+// checkLimit checks the LIMIT and OFFSET of a query, and the missing
+// LIMIT of a top-level SELECT that reads a whole table.
+func (r *selectPerformanceRule) checkLimit(ctx *mysql.QueryExpressionContext) {
+	if ctx.LimitClause() == nil {
+		if r.payload.RequireLimit && isTopLevelQuery(ctx) && readsWholeTable(ctx) {
+			r.addPerformanceAdvice("Require LIMIT", "SELECT without WHERE or LIMIT reads the whole table", ctx.GetStart())
+		}
+		return
+	}
+
+	options := ctx.LimitClause().LimitOptions()
+	if options == nil {
+		return
+	}
+	limitOptions := options.AllLimitOption()
+	countOption, offsetOption := limitOptions[0], mysql.ILimitOptionContext(nil)
+	if len(limitOptions) == 2 {
+		if options.COMMA_SYMBOL() != nil {
+			// LIMIT offset, count
+			countOption, offsetOption = limitOptions[1], limitOptions[0]
+		} else {
+			// LIMIT count OFFSET offset
+			offsetOption = limitOptions[1]
+		}
+	}
+
+	if count, ok := limitValue(countOption); ok && r.payload.MaxLimit > 0 && count > r.payload.MaxLimit {
+		r.addPerformanceAdvice("LIMIT too large", fmt.Sprintf("LIMIT %d exceeds the maximum %d", count, r.payload.MaxLimit), countOption.GetStart())
+	}
+	if offsetOption == nil {
+		return
+	}
+	if offset, ok := limitValue(offsetOption); ok && r.payload.MaxOffset > 0 && offset > r.payload.MaxOffset {
+		r.addPerformanceAdvice(
+			"Deep OFFSET pagination",
+			fmt.Sprintf("OFFSET %d exceeds the maximum %d, use keyset pagination instead", offset, r.payload.MaxOffset),
+			offsetOption.GetStart(),
+		)
+	}
+}
+
+// checkCondition walks a WHERE condition for leading-wildcard LIKE, large
+// IN lists, functions on columns and OR across columns. Subqueries are
+// checked when the walker enters their own WHERE clause.
+func (r *selectPerformanceRule) checkCondition(node antlr.Tree) {
+	switch node := node.(type) {
+	case *mysql.SubqueryContext:
+		return
+	case *mysql.PredicateExprLikeContext:
+		r.checkLike(node)
+	case *mysql.PredicateExprInContext:
+		if node.ExprList() != nil && r.payload.MaxInListSize > 0 {
+			if size := len(node.ExprList().AllExpr()); size > r.payload.MaxInListSize {
+				r.addPerformanceAdvice("Large IN list", fmt.Sprintf("IN list has %d values, exceeding the maximum %d", size, r.payload.MaxInListSize), node.GetStart())
+			}
+		}
+	case *mysql.FunctionCallContext, *mysql.RuntimeFunctionCallContext:
+		if r.payload.ForbidFunctionOnColumn {
+			function := node.(antlr.ParserRuleContext)
+			if strings.HasPrefix(strings.ToUpper(function.GetText()), "RAND(") {
+				r.addPerformanceAdvice("Function in WHERE", "RAND() in WHERE is evaluated for every row", function.GetStart())
+				return
+			}
+			if containsColumnRef(function) {
+				r.addPerformanceAdvice(
+					"Function on column in WHERE",
+					fmt.Sprintf("Function `%s` applied to a column in WHERE prevents index use", mysqlparser.GetOriginalText(function)),
+					function.GetStart(),
+				)
+				// Nested functions on the same column are not reported again.
+				return
+			}
+		}
+	case *mysql.ExprOrContext:
+		if _, nested := node.GetParent().(*mysql.ExprOrContext); !nested && r.payload.ForbidOrAcrossColumns {
+			r.checkOr(node)
+		}
+	}
+
+	for i := 0; i < node.GetChildCount(); i++ {
+		r.checkCondition(node.GetChild(i))
+	}
+}
+
+func (r *selectPerformanceRule) checkLike(ctx *mysql.PredicateExprLikeContext) {
+	if !r.payload.ForbidLeadingWildcardLike || len(ctx.AllSimpleExpr()) == 0 {
+		return
+	}
+	pattern, ok := ctx.SimpleExpr(0).(*mysql.SimpleExprLiteralContext)
+	if !ok || pattern.Literal() == nil || pattern.Literal().TextLiteral() == nil {
+		return
+	}
+	text := pattern.Literal().TextLiteral().GetText()
+	if i := strings.IndexAny(text, `'"`); i >= 0 && i+1 < len(text) && (text[i+1] == '%' || text[i+1] == '_') {
+		r.addPerformanceAdvice(
+			"Leading wildcard LIKE",
+			fmt.Sprintf("LIKE pattern %s starts with a wildcard and cannot use an index", text),
+			pattern.GetStart(),
+		)
+	}
+}
+
+// checkOr reports an OR chain whose branches compare different columns.
+func (r *selectPerformanceRule) checkOr(ctx *mysql.ExprOrContext) {
+	var columns []string
+	seen := make(map[string]bool)
+	for _, branch := range flattenOr(ctx) {
+		for _, column := range columnRefsOf(branch) {
+			if !seen[strings.ToLower(column)] {
+				seen[strings.ToLower(column)] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+	if len(columns) > 1 {
+		r.addPerformanceAdvice(
+			"OR across columns",
+			fmt.Sprintf("OR across columns `%s` may prevent index use, consider UNION", strings.Join(columns, "`, `")),
+			ctx.GetStart(),
+		)
+	}
+}
+
+func (r *selectPerformanceRule) addPerformanceAdvice(title, content string, token antlr.Token) {
+	r.AddAdvice(advisor.CodeStatementPerformanceIssue, title, content, token)
+}
+
+// flattenOr returns the branches of an OR chain.
+func flattenOr(ctx mysql.IExprContext) []mysql.IExprContext {
+	or, ok := ctx.(*mysql.ExprOrContext)
+	if !ok {
+		return []mysql.IExprContext{ctx}
+	}
+	return append(flattenOr(or.Expr(0)), flattenOr(or.Expr(1))...)
+}
+
+// columnRefsOf returns the names of the columns referenced by a node,
+// outside of subqueries.
+func columnRefsOf(node antlr.Tree) []string {
+	switch node := node.(type) {
+	case *mysql.SubqueryContext:
+		return nil
+	case *mysql.ColumnRefContext:
+		_, table, column := mysqlparser.NormalizeMySQLColumnRef(node)
+		if table != "" {
+			return []string{table + "." + column}
+		}
+		return []string{column}
+	}
+	var columns []string
+	for i := 0; i < node.GetChildCount(); i++ {
+		columns = append(columns, columnRefsOf(node.GetChild(i))...)
+	}
+	return columns
+}
+
+func containsColumnRef(node antlr.Tree) bool {
+	return len(columnRefsOf(node)) > 0
+}
+
+// limitValue returns the value of a numeric LIMIT option. Placeholders and
+// variables have no value.
+func limitValue(ctx mysql.ILimitOptionContext) (int, bool) {
+	if ctx == nil || ctx.Identifier() != nil || ctx.PARAM_MARKER() != nil {
+		return 0, false
+	}
+	value, err := strconv.Atoi(ctx.GetText())
+	return value, err == nil
+}
+
+// isTopLevelQuery reports whether a query expression is a SELECT statement
+// of its own, not a subquery or the query of INSERT ... SELECT.
+func isTopLevelQuery(ctx *mysql.QueryExpressionContext) bool {
+	parent, ok := ctx.GetParent().(*mysql.SelectStatementContext)
+	if !ok {
+		return false
+	}
+	_, ok = parent.GetParent().(*mysql.SimpleStatementContext)
+	return ok
+}
+
+// readsWholeTable reports whether a query is a single SELECT from tables
+// without WHERE, GROUP BY or aggregate functions.
+func readsWholeTable(ctx *mysql.QueryExpressionContext) bool {
+	body := ctx.QueryExpressionBody()
+	if body == nil || len(body.AllQueryPrimary()) != 1 || len(body.AllQueryExpressionParens()) != 0 {
+		return false
+	}
+	spec := body.QueryPrimary(0).QuerySpecification()
+	if spec == nil || spec.FromClause() == nil || spec.FromClause().TableReferenceList() == nil {
+		return false
+	}
+	if spec.WhereClause() != nil || spec.GroupByClause() != nil {
+		return false
+	}
+	return !containsAggregate(spec.SelectItemList())
+}
+
+func containsAggregate(node antlr.Tree) bool {
+	if _, ok := node.(*mysql.SumExprContext); ok {
+		return true
+	}
+	for i := 0; i < node.GetChildCount(); i++ {
+		if containsAggregate(node.GetChild(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+// inExistsSubquery reports whether ctx is part of an EXISTS subquery, where
+// SELECT * is idiomatic.
+func inExistsSubquery(ctx antlr.ParserRuleContext) bool {
+	for node := ctx.GetParent(); node != nil; node = node.GetParent() {
+		if subquery, ok := node.(*mysql.SimpleExprSubQueryContext); ok && subquery.EXISTS_SYMBOL() != nil {
+			return true
+		}
+	}
+	return false
+}
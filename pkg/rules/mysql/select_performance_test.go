@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+// TestSelectPerformance 测试 SELECT 性能检查
+func TestSelectPerformance(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   any
+		statement string
+		expected  []string
+	}{
+		{
+			name:      "Good select",
+			statement: "SELECT id, name FROM users WHERE email = 'a@b.c' ORDER BY id LIMIT 50;\nSELECT COUNT(*) FROM users;\nSELECT id FROM users WHERE EXISTS (SELECT * FROM orders WHERE orders.user_id = users.id) LIMIT 10;",
+		},
+		{
+			name:      "SELECT star",
+			statement: "SELECT * FROM bad_users WHERE id = 1;\nSELECT u.*, o.id FROM u JOIN o ON u.id = o.uid WHERE u.id = 1;",
+			expected:  []string{"1:8 1001", "2:8 1001"},
+		},
+		{
+			name:      "LIMIT and OFFSET",
+			statement: "SELECT id FROM users LIMIT 5000;\nSELECT id FROM users LIMIT 100 OFFSET 50000;\nSELECT id FROM users LIMIT 20000, 10;\nSELECT id FROM users;\nINSERT INTO t SELECT id FROM users;",
+			expected:  []string{"1:28 1004", "2:39 1004", "3:28 1004", "4:1 1004"},
+		},
+		{
+			name:      "WHERE conditions",
+			statement: "SELECT id FROM users WHERE name LIKE '%john%' LIMIT 10;\nSELECT id FROM users WHERE DATE(created_at) = '2024-01-01' AND RAND() > 0.5 LIMIT 10;\nSELECT id FROM users WHERE a = 1 OR b = 2 LIMIT 10;\nSELECT id FROM users WHERE a = 1 OR a = 2 LIMIT 10;",
+			expected:  []string{"1:38 1004", "2:28 1004", "2:64 1004", "3:28 1004"},
+		},
+		{
+			name:      "ORDER BY RAND and IN list",
+			payload:   map[string]any{"max_in_list_size": 3},
+			statement: "SELECT id FROM users WHERE id IN (1, 2, 3, 4) ORDER BY RAND() LIMIT 1;",
+			expected:  []string{"1:31 1004", "1:56 1004"},
+		},
+		{
+			name:      "Options disabled",
+			payload:   map[string]any{"forbid_select_star": false, "require_limit": false, "max_limit": 0},
+			statement: "SELECT * FROM users;\nSELECT id FROM users LIMIT 100000;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checkRule(t, advisor.MySQLSelectPerformance, tt.payload, tt.statement)
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
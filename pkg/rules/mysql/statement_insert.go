@@ -0,0 +1,245 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/antlr4-go/antlr/v4"
+	mysql "github.com/bytebase/parser/mysql"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+func init() {
+	advisor.Register(advisor.MySQL, advisor.MySQLStatementInsert, &StatementInsertAdvisor{})
+}
+
+// StatementInsertPayload is the payload of the INSERT rule. Numeric limits
+// of 0 disable the check.
+type StatementInsertPayload struct {
+	RequireColumnList bool `json:"require_column_list" yaml:"require_column_list"`
+	// MaxValuesRows caps the rows of a multi-row VALUES list.
+	MaxValuesRows    int  `json:"max_values_rows" yaml:"max_values_rows" validate:"min=0"`
+	ForbidSelectStar bool `json:"forbid_select_star" yaml:"forbid_select_star"`
+	// RequireSelectBound flags INSERT ... SELECT without WHERE or LIMIT,
+	// unless every source table is known to have fewer rows than
+	// LargeTableRows.
+	RequireSelectBound bool  `json:"require_select_bound" yaml:"require_select_bound"`
+	LargeTableRows     int64 `json:"large_table_rows" yaml:"large_table_rows" validate:"min=0"`
+	ForbidReplace      bool  `json:"forbid_replace" yaml:"forbid_replace"`
+	// ForbidOnDuplicateKeyUpdate flags INSERT ... ON DUPLICATE KEY UPDATE.
+	ForbidOnDuplicateKeyUpdate bool `json:"forbid_on_duplicate_key_update" yaml:"forbid_on_duplicate_key_update"`
+}
+
+// DefaultStatementInsertPayload returns the payload used for options that
+// are not configured.
+func DefaultStatementInsertPayload() *StatementInsertPayload {
+	return &StatementInsertPayload{
+		RequireColumnList:  true,
+		MaxValuesRows:      1000,
+		ForbidSelectStar:   true,
+		RequireSelectBound: true,
+		LargeTableRows:     100000,
+	}
+}
+
+// StatementInsertAdvisor checks INSERT and REPLACE statements.
+type StatementInsertAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *StatementInsertAdvisor) Title() string {
+	return "INSERT 语句检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *StatementInsertAdvisor) Description() string {
+	return "INSERT 必须指定列名，限制 VALUES 行数，禁止 INSERT ... SELECT * 和无条件的 INSERT ... SELECT，可按策略禁止 REPLACE 和 ON DUPLICATE KEY UPDATE"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *StatementInsertAdvisor) DefaultPayload() any {
+	return DefaultStatementInsertPayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *StatementInsertAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *StatementInsertAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultStatementInsertPayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+	return &statementInsertRule{
+		BaseRule: advisor.NewBaseRule(checkCtx.Rule),
+		payload:  payload,
+	}, nil
+}
+
+// statementInsertRule checks INSERT and REPLACE statements.
+type statementInsertRule struct {
+	advisor.BaseRule
+
+	payload *StatementInsertPayload
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *statementInsertRule) NodeTypes() []string {
+	return []string{nodeTypeInsertStatement, nodeTypeReplaceStatement}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *statementInsertRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	switch ctx := ctx.(type) {
+	case *mysql.InsertStatementContext:
+		r.checkInsert("INSERT", ctx, ctx.InsertFromConstructor(), ctx.InsertQueryExpression())
+		if r.payload.ForbidOnDuplicateKeyUpdate && ctx.InsertUpdateList() != nil {
+			r.AddAdvice(
+				advisor.CodeInsertOnDuplicateKeyDisallowed,
+				"Disallow ON DUPLICATE KEY UPDATE",
+				"INSERT ... ON DUPLICATE KEY UPDATE is not allowed",
+				ctx.InsertUpdateList().GetStart(),
+			)
+		}
+	case *mysql.ReplaceStatementContext:
+		if r.payload.ForbidReplace {
+			r.AddAdvice(advisor.CodeInsertReplaceDisallowed, "Disallow REPLACE", "REPLACE INTO is not allowed", ctx.GetStart())
+		}
+		r.checkInsert("REPLACE", ctx, ctx.InsertFromConstructor(), ctx.InsertQueryExpression())
+	}
+	return nil
+}
+
+// checkInsert checks the column list and the VALUES or SELECT source of an
+// INSERT or REPLACE statement.
+func (r *statementInsertRule) checkInsert(statement string, ctx antlr.ParserRuleContext, constructor mysql.IInsertFromConstructorContext, query mysql.IInsertQueryExpressionContext) {
+	if r.payload.RequireColumnList {
+		if (constructor != nil && constructor.Fields() == nil) || (query != nil && query.Fields() == nil) {
+			r.AddAdvice(
+				advisor.CodeInsertColumnListRequired,
+				"Require column list",
+				fmt.Sprintf("%s statement requires an explicit column list", statement),
+				ctx.GetStart(),
+			)
+		}
+	}
+
+	if constructor != nil && constructor.InsertValues() != nil && r.payload.MaxValuesRows > 0 {
+		valueList := constructor.InsertValues().ValueList()
+		if rows := len(valueList.AllOPEN_PAR_SYMBOL()); rows > r.payload.MaxValuesRows {
+			r.AddAdvice(
+				advisor.CodeInsertTooManyRows,
+				"Too many VALUES rows",
+				fmt.Sprintf("%s statement inserts %d rows, exceeding the maximum %d", statement, rows, r.payload.MaxValuesRows),
+				constructor.InsertValues().GetStart(),
+			)
+		}
+	}
+
+	if query != nil {
+		for _, spec := range querySpecificationsOf(query) {
+			r.checkInsertSelect(statement, spec)
+		}
+	}
+}
+
+// checkInsertSelect checks one SELECT of INSERT ... SELECT.
+func (r *statementInsertRule) checkInsertSelect(statement string, spec *mysql.QuerySpecificationContext) {
+	if r.payload.ForbidSelectStar && spec.SelectItemList() != nil {
+		if star := spec.SelectItemList().MULT_OPERATOR(); star != nil {
+			r.AddAdvice(
+				advisor.CodeInsertSelectAll,
+				"INSERT ... SELECT *",
+				fmt.Sprintf("%s ... SELECT * depends on the column order of the source, list the columns instead", statement),
+				star.GetSymbol(),
+			)
+		}
+	}
+
+	if !r.payload.RequireSelectBound || spec.WhereClause() != nil || hasLimit(spec) {
+		return
+	}
+	if spec.FromClause() == nil || spec.FromClause().TableReferenceList() == nil {
+		return
+	}
+	var sources []string
+	large := false
+	for _, ref := range spec.FromClause().TableReferenceList().AllTableReference() {
+		for _, table := range tablesOf(ref) {
+			sources = append(sources, table)
+			if rows := r.rowCount(table); rows == 0 || rows >= r.payload.LargeTableRows {
+				large = true
+			}
+		}
+	}
+	if large {
+		r.AddAdvice(
+			advisor.CodeInsertSelectUnbounded,
+			"Unbounded INSERT ... SELECT",
+			fmt.Sprintf("%s ... SELECT without WHERE or LIMIT copies all rows of `%s`", statement, sources[0]),
+			spec.GetStart(),
+		)
+	}
+}
+
+// querySpecificationsOf returns the SELECTs of a query, including each
+// branch of a UNION, but not the SELECTs of subqueries.
+func querySpecificationsOf(node antlr.Tree) []*mysql.QuerySpecificationContext {
+	switch node := node.(type) {
+	case *mysql.SubqueryContext:
+		return nil
+	case *mysql.QuerySpecificationContext:
+		return []*mysql.QuerySpecificationContext{node}
+	}
+	var specs []*mysql.QuerySpecificationContext
+	for i := 0; i < node.GetChildCount(); i++ {
+		specs = append(specs, querySpecificationsOf(node.GetChild(i))...)
+	}
+	return specs
+}
+
+// hasLimit reports whether the query expression that contains a SELECT has
+// a LIMIT clause.
+func hasLimit(spec *mysql.QuerySpecificationContext) bool {
+	for node := spec.GetParent(); node != nil; node = node.GetParent() {
+		if query, ok := node.(*mysql.QueryExpressionContext); ok {
+			return query.LimitClause() != nil
+		}
+	}
+	return false
+}
+
+// tablesOf returns the names of the base tables of a table reference,
+// without the tables of derived tables.
+func tablesOf(node antlr.Tree) []string {
+	switch node := node.(type) {
+	case *mysql.SubqueryContext:
+		return nil
+	case *mysql.SingleTableContext:
+		_, table := mysqlparser.NormalizeMySQLTableRef(node.TableRef())
+		return []string{table}
+	}
+	var tables []string
+	for i := 0; i < node.GetChildCount(); i++ {
+		tables = append(tables, tablesOf(node.GetChild(i))...)
+	}
+	return tables
+}
+
+// rowCount returns the estimated number of rows of a table in the catalog,
+// 0 when it is unknown.
+func (r *statementInsertRule) rowCount(name string) int64 {
+	if r.Catalog() == nil {
+		return 0
+	}
+	if table := r.Catalog().Table("", name); table != nil {
+		return table.RowCount
+	}
+	return 0
+}
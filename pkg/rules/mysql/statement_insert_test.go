@@ -0,0 +1,77 @@
+package mysql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+// TestStatementInsert 测试 INSERT 语句检查
+func TestStatementInsert(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   any
+		statement string
+		expected  []string
+	}{
+		{
+			name:      "Good insert",
+			statement: "INSERT INTO users (name, email) VALUES ('a', 'b'), ('c', 'd');\nINSERT INTO archive (id, name) SELECT id, name FROM users WHERE created_at < '2020-01-01';\nINSERT INTO users SET name = 'a';",
+		},
+		{
+			name:      "Missing column list",
+			statement: "INSERT INTO bad_users VALUES ('John', 'john@example.com');\nREPLACE INTO t VALUES (1);",
+			expected:  []string{"1:1 1201", "2:1 1201"},
+		},
+		{
+			name:      "Too many rows",
+			payload:   map[string]any{"max_values_rows": 2},
+			statement: "INSERT INTO t (id) VALUES (1), (2), (3);",
+			expected:  []string{"1:20 1202"},
+		},
+		{
+			name:      "INSERT ... SELECT",
+			statement: "INSERT INTO archive (id) SELECT * FROM users WHERE id < 10;\nINSERT INTO archive (id) SELECT id FROM users;\nINSERT INTO archive (id) SELECT id FROM users LIMIT 100;",
+			expected:  []string{"1:33 1203", "2:26 1204"},
+		},
+		{
+			name:      "REPLACE and ON DUPLICATE KEY UPDATE",
+			payload:   map[string]any{"forbid_replace": true, "forbid_on_duplicate_key_update": true},
+			statement: "REPLACE INTO t (id) VALUES (1);\nINSERT INTO t (id) VALUES (1) ON DUPLICATE KEY UPDATE id = 2;",
+			expected:  []string{"1:1 1205", "2:31 1206"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checkRule(t, advisor.MySQLStatementInsert, tt.payload, tt.statement)
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
+
+// TestStatementInsertSmallSource 测试已知行数较少的源表不需要 WHERE/LIMIT
+func TestStatementInsertSmallSource(t *testing.T) {
+	rule := &advisor.Rule{Type: advisor.MySQLStatementInsert, Level: advisor.LevelWarning, Engine: advisor.MySQL}
+	checkCtx := advisor.Context{
+		SQL:    "INSERT INTO archive (id) SELECT id FROM dict;\nINSERT INTO archive (id) SELECT id FROM events;",
+		Engine: advisor.MySQL,
+		Rule:   rule,
+		Metadata: &advisor.DatabaseMetadata{Tables: map[string]*advisor.TableMetadata{
+			"dict":   {Name: "dict", RowCount: 100},
+			"events": {Name: "events", RowCount: 5000000},
+		}},
+	}
+
+	advices, err := advisor.CheckByType(context.Background(), advisor.MySQL, advisor.MySQLStatementInsert, checkCtx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(advices) != 1 || advices[0].StartPosition.Line != 2 {
+		t.Errorf("Expected one advice on line 2, got %v", advices)
+	}
+}
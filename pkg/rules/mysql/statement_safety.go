@@ -0,0 +1,351 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+	mysql "github.com/bytebase/parser/mysql"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+func init() {
+	advisor.Register(advisor.MySQL, advisor.MySQLStatementSafety, &StatementSafetyAdvisor{})
+}
+
+// StatementSafetyPayload is the payload of the statement safety rule.
+type StatementSafetyPayload struct {
+	ForbidDropDatabase bool `json:"forbid_drop_database" yaml:"forbid_drop_database"`
+	ForbidTruncate     bool `json:"forbid_truncate" yaml:"forbid_truncate"`
+	// RequireWhereForUpdate also requires a join condition between the
+	// tables of a multi-table UPDATE.
+	RequireWhereForUpdate bool `json:"require_where_for_update" yaml:"require_where_for_update"`
+	// RequireWhereForDelete also requires a join condition between the
+	// tables of a multi-table DELETE.
+	RequireWhereForDelete bool `json:"require_where_for_delete" yaml:"require_where_for_delete"`
+}
+
+// DefaultStatementSafetyPayload returns the payload used for options that
+// are not configured.
+func DefaultStatementSafetyPayload() *StatementSafetyPayload {
+	return &StatementSafetyPayload{
+		ForbidDropDatabase:    true,
+		ForbidTruncate:        true,
+		RequireWhereForUpdate: true,
+		RequireWhereForDelete: true,
+	}
+}
+
+// StatementSafetyAdvisor checks statements that can destroy or rewrite a
+// whole table or database by accident.
+type StatementSafetyAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *StatementSafetyAdvisor) Title() string {
+	return "语句安全检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *StatementSafetyAdvisor) Description() string {
+	return "禁止 DROP DATABASE 和 TRUNCATE，UPDATE/DELETE 必须有有效的 WHERE 条件，多表 UPDATE/DELETE 必须有关联条件"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *StatementSafetyAdvisor) DefaultPayload() any {
+	return DefaultStatementSafetyPayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *StatementSafetyAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *StatementSafetyAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultStatementSafetyPayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+	return &statementSafetyRule{
+		BaseRule: advisor.NewBaseRule(checkCtx.Rule),
+		payload:  payload,
+	}, nil
+}
+
+// statementSafetyRule checks UPDATE, DELETE, DROP DATABASE and TRUNCATE
+// statements.
+type statementSafetyRule struct {
+	advisor.BaseRule
+
+	payload *StatementSafetyPayload
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *statementSafetyRule) NodeTypes() []string {
+	return []string{
+		nodeTypeUpdateStatement,
+		nodeTypeDeleteStatement,
+		nodeTypeDropDatabase,
+		nodeTypeTruncateTableStatement,
+	}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *statementSafetyRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	switch ctx := ctx.(type) {
+	case *mysql.UpdateStatementContext:
+		if r.payload.RequireWhereForUpdate {
+			r.checkWhere("UPDATE", ctx, ctx.WhereClause())
+			r.checkJoinCondition("UPDATE", ctx, ctx.TableReferenceList(), ctx.WhereClause())
+		}
+	case *mysql.DeleteStatementContext:
+		if r.payload.RequireWhereForDelete {
+			r.checkWhere("DELETE", ctx, ctx.WhereClause())
+			if ctx.TableReferenceList() != nil {
+				r.checkJoinCondition("DELETE", ctx, ctx.TableReferenceList(), ctx.WhereClause())
+			}
+		}
+	case *mysql.DropDatabaseContext:
+		if r.payload.ForbidDropDatabase {
+			r.AddAdvice(
+				advisor.CodeStatementUnsafeOperation,
+				"Forbid DROP DATABASE",
+				fmt.Sprintf("DROP DATABASE `%s` is forbidden", mysqlparser.NormalizeMySQLIdentifier(schemaRefIdentifier(ctx.SchemaRef()))),
+				statementStart(ctx),
+			)
+		}
+	case *mysql.TruncateTableStatementContext:
+		if r.payload.ForbidTruncate {
+			_, table := mysqlparser.NormalizeMySQLTableRef(ctx.TableRef())
+			r.AddAdvice(
+				advisor.CodeStatementUnsafeOperation,
+				"Forbid TRUNCATE",
+				fmt.Sprintf("TRUNCATE TABLE `%s` is forbidden", table),
+				ctx.GetStart(),
+			)
+		}
+	}
+	return nil
+}
+
+// checkWhere reports a missing WHERE clause and WHERE clauses that are
+// always true.
+func (r *statementSafetyRule) checkWhere(statement string, ctx antlr.ParserRuleContext, where mysql.IWhereClauseContext) {
+	if where == nil || where.Expr() == nil {
+		r.AddAdvice(
+			advisor.CodeStatementNoWhere,
+			"Require WHERE clause",
+			fmt.Sprintf("%s statement requires a WHERE clause", statement),
+			ctx.GetStart(),
+		)
+		return
+	}
+
+	if isAlwaysTrue(where.Expr()) {
+		r.AddAdvice(
+			advisor.CodeStatementNoWhere,
+			"Always true WHERE clause",
+			fmt.Sprintf("WHERE clause `%s` of the %s statement is always true", mysqlparser.GetOriginalText(where.Expr()), statement),
+			where.Expr().GetStart(),
+		)
+	}
+}
+
+// checkJoinCondition reports multi-table statements whose tables are not
+// all connected by a join condition, which turns them into a cross join.
+func (r *statementSafetyRule) checkJoinCondition(statement string, ctx antlr.ParserRuleContext, tables mysql.ITableReferenceListContext, where mysql.IWhereClauseContext) {
+	if tables == nil {
+		return
+	}
+
+	graph := newJoinGraph()
+	for _, ref := range tables.AllTableReference() {
+		graph.addTableReference(ref)
+	}
+	if len(graph.names) < 2 {
+		return
+	}
+	if where != nil && where.Expr() != nil {
+		graph.addConditions(where.Expr())
+	}
+
+	if left, right, ok := graph.disconnected(); ok {
+		r.AddAdvice(
+			advisor.CodeStatementUnsafeOperation,
+			"Missing join condition",
+			fmt.Sprintf("%s statement has no join condition between `%s` and `%s`", statement, left, right),
+			ctx.GetStart(),
+		)
+	}
+}
+
+// schemaRefIdentifier returns the identifier of a schema reference.
+func schemaRefIdentifier(ctx mysql.ISchemaRefContext) mysql.IIdentifierContext {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Identifier()
+}
+
+// joinGraph tracks which tables of a statement are connected by join
+// conditions. Tables are keyed by their alias, or by their name when they
+// have no alias.
+type joinGraph struct {
+	parent map[string]string
+	// names holds the table names in statement order with their original
+	// case, for messages.
+	names []string
+}
+
+func newJoinGraph() *joinGraph {
+	return &joinGraph{parent: make(map[string]string)}
+}
+
+func (g *joinGraph) add(name string) {
+	key := strings.ToLower(name)
+	if _, ok := g.parent[key]; ok {
+		return
+	}
+	g.parent[key] = key
+	g.names = append(g.names, name)
+}
+
+func (g *joinGraph) find(key string) string {
+	for g.parent[key] != key {
+		key = g.parent[key]
+	}
+	return key
+}
+
+func (g *joinGraph) union(a, b string) {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if _, ok := g.parent[a]; !ok {
+		return
+	}
+	if _, ok := g.parent[b]; !ok {
+		return
+	}
+	g.parent[g.find(a)] = g.find(b)
+}
+
+// disconnected returns two tables that are not connected, if any.
+func (g *joinGraph) disconnected() (string, string, bool) {
+	root := g.find(strings.ToLower(g.names[0]))
+	for _, name := range g.names[1:] {
+		if g.find(strings.ToLower(name)) != root {
+			return g.names[0], name, true
+		}
+	}
+	return "", "", false
+}
+
+// addTableReference adds the tables of a table reference and connects the
+// tables joined with ON, USING or NATURAL. It returns the added tables.
+func (g *joinGraph) addTableReference(ctx mysql.ITableReferenceContext) []string {
+	if ctx.TableFactor() != nil {
+		return g.addJoinedTables(g.addTableFactor(ctx.TableFactor()), ctx.AllJoinedTable())
+	}
+	if escaped := ctx.EscapedTableReference(); escaped != nil {
+		return g.addJoinedTables(g.addTableFactor(escaped.TableFactor()), escaped.AllJoinedTable())
+	}
+	return nil
+}
+
+func (g *joinGraph) addJoinedTables(names []string, joinedTables []mysql.IJoinedTableContext) []string {
+	for _, joined := range joinedTables {
+		var joinedNames []string
+		if joined.TableReference() != nil {
+			joinedNames = g.addTableReference(joined.TableReference())
+		} else if joined.TableFactor() != nil {
+			joinedNames = g.addTableFactor(joined.TableFactor())
+		}
+
+		if joined.ON_SYMBOL() != nil || joined.USING_SYMBOL() != nil || joined.NaturalJoinType() != nil {
+			for _, name := range joinedNames {
+				if len(names) > 0 {
+					g.union(names[0], name)
+				}
+			}
+		}
+		names = append(names, joinedNames...)
+	}
+	return names
+}
+
+func (g *joinGraph) addTableFactor(ctx mysql.ITableFactorContext) []string {
+	if ctx == nil {
+		return nil
+	}
+	switch {
+	case ctx.SingleTable() != nil:
+		return g.addSingleTable(ctx.SingleTable())
+	case ctx.SingleTableParens() != nil:
+		parens := ctx.SingleTableParens()
+		for parens.SingleTable() == nil && parens.SingleTableParens() != nil {
+			parens = parens.SingleTableParens()
+		}
+		return g.addSingleTable(parens.SingleTable())
+	case ctx.DerivedTable() != nil:
+		if alias := ctx.DerivedTable().TableAlias(); alias != nil {
+			name := mysqlparser.NormalizeMySQLIdentifier(alias.Identifier())
+			g.add(name)
+			return []string{name}
+		}
+	case ctx.TableReferenceListParens() != nil:
+		parens := ctx.TableReferenceListParens()
+		for parens.TableReferenceList() == nil && parens.TableReferenceListParens() != nil {
+			parens = parens.TableReferenceListParens()
+		}
+		var names []string
+		if parens.TableReferenceList() != nil {
+			for _, ref := range parens.TableReferenceList().AllTableReference() {
+				names = append(names, g.addTableReference(ref)...)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+func (g *joinGraph) addSingleTable(ctx mysql.ISingleTableContext) []string {
+	if ctx == nil {
+		return nil
+	}
+	_, name := mysqlparser.NormalizeMySQLTableRef(ctx.TableRef())
+	if ctx.TableAlias() != nil {
+		name = mysqlparser.NormalizeMySQLIdentifier(ctx.TableAlias().Identifier())
+	}
+	g.add(name)
+	return []string{name}
+}
+
+// addConditions connects the tables compared by the equality conditions
+// in a WHERE clause, e.g. `a.id = b.a_id`. Subqueries are not searched.
+func (g *joinGraph) addConditions(node antlr.Tree) {
+	switch node := node.(type) {
+	case *mysql.SubqueryContext:
+		return
+	case *mysql.PrimaryExprCompareContext:
+		if node.CompOp() != nil {
+			switch node.CompOp().GetStart().GetTokenType() {
+			case mysql.MySQLParserEQUAL_OPERATOR, mysql.MySQLParserNULL_SAFE_EQUAL_OPERATOR:
+				left := columnRefOf(simpleExprOfBoolPri(node.BoolPri()))
+				right := columnRefOf(simpleExprOfPredicate(node.Predicate()))
+				if left != nil && right != nil {
+					_, leftTable, _ := mysqlparser.NormalizeMySQLColumnRef(left)
+					_, rightTable, _ := mysqlparser.NormalizeMySQLColumnRef(right)
+					g.union(leftTable, rightTable)
+				}
+			}
+		}
+	}
+	for i := 0; i < node.GetChildCount(); i++ {
+		g.addConditions(node.GetChild(i))
+	}
+}
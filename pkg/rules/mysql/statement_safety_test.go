@@ -0,0 +1,57 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+// TestStatementSafety 测试语句安全检查
+func TestStatementSafety(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   any
+		statement string
+		expected  []string
+	}{
+		{
+			name:      "Safe statements",
+			statement: "UPDATE users SET name = 'a' WHERE id = 1;\nDELETE FROM users WHERE created_at < NOW();",
+		},
+		{
+			name:      "Missing WHERE",
+			statement: "UPDATE bad_users SET name = 'Updated';\nDELETE FROM bad_users;",
+			expected:  []string{"1:1 1002", "2:1 1002"},
+		},
+		{
+			name:      "Always true WHERE",
+			statement: "DELETE FROM users WHERE 1=1;\nUPDATE users SET a = 1 WHERE id = id;\nUPDATE users SET a = 1 WHERE (TRUE) OR id = 3;\nDELETE FROM users WHERE 1 = 2 OR id = 1;",
+			expected:  []string{"1:25 1002", "2:30 1002", "3:30 1002"},
+		},
+		{
+			name:      "Multi-table join condition",
+			statement: "UPDATE a, b SET a.x = b.x WHERE a.flag = 1;\nUPDATE a, b SET a.x = b.x WHERE a.id = b.a_id;\nDELETE t1 FROM t1 JOIN t2 WHERE t1.flag = 1;\nDELETE t1 FROM t1 JOIN t2 ON t1.id = t2.id WHERE t1.flag = 1;\nUPDATE a x JOIN b y USING (id) JOIN c z SET x.v = 1 WHERE z.id = 2;",
+			expected:  []string{"1:1 1003", "3:1 1003", "5:1 1003"},
+		},
+		{
+			name:      "DROP DATABASE and TRUNCATE",
+			statement: "DROP DATABASE prod;\nTRUNCATE TABLE users;",
+			expected:  []string{"1:1 1003", "2:1 1003"},
+		},
+		{
+			name:      "Options disabled",
+			payload:   map[string]any{"forbid_drop_database": false, "forbid_truncate": false, "require_where_for_update": false, "require_where_for_delete": false},
+			statement: "DROP DATABASE prod;\nTRUNCATE TABLE users;\nUPDATE users SET a = 1;\nDELETE FROM users;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checkRule(t, advisor.MySQLStatementSafety, tt.payload, tt.statement)
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
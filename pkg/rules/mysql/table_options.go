@@ -0,0 +1,737 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/antlr4-go/antlr/v4"
+	mysql "github.com/bytebase/parser/mysql"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+func init() {
+	advisor.Register(advisor.MySQL, advisor.MySQLTableEngine, &TableEngineAdvisor{})
+	advisor.Register(advisor.MySQL, advisor.MySQLTableCharset, &TableCharsetAdvisor{})
+	advisor.Register(advisor.MySQL, advisor.MySQLTableComment, &TableCommentAdvisor{})
+	advisor.Register(advisor.MySQL, advisor.MySQLTablePartition, &TablePartitionAdvisor{})
+	advisor.Register(advisor.MySQL, advisor.MySQLTableNamePrefix, &TableNamePrefixAdvisor{})
+	advisor.Register(advisor.MySQL, advisor.MySQLTableCreateAsSelect, &TableCreateAsSelectAdvisor{})
+	advisor.Register(advisor.MySQL, advisor.MySQLTableCreateLike, &TableCreateLikeAdvisor{})
+}
+
+// TableEnginePayload is the payload of the table engine rule.
+type TableEnginePayload struct {
+	// AllowedEngines lists the storage engines tables may use, empty allows
+	// all engines.
+	AllowedEngines []string `json:"allowed_engines" yaml:"allowed_engines"`
+}
+
+// DefaultTableEnginePayload returns the payload used for options that are
+// not configured.
+func DefaultTableEnginePayload() *TableEnginePayload {
+	return &TableEnginePayload{AllowedEngines: []string{"InnoDB"}}
+}
+
+// TableEngineAdvisor checks the ENGINE of CREATE TABLE and ALTER TABLE.
+type TableEngineAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *TableEngineAdvisor) Title() string {
+	return "表存储引擎检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *TableEngineAdvisor) Description() string {
+	return "表只能使用允许的存储引擎，默认只允许 InnoDB"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *TableEngineAdvisor) DefaultPayload() any {
+	return DefaultTableEnginePayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *TableEngineAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *TableEngineAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultTableEnginePayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+	return &tableEngineRule{
+		BaseRule: advisor.NewBaseRule(checkCtx.Rule),
+		payload:  payload,
+		allowed:  toLowerSet(payload.AllowedEngines),
+	}, nil
+}
+
+type tableEngineRule struct {
+	advisor.BaseRule
+
+	payload *TableEnginePayload
+	allowed map[string]bool
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *tableEngineRule) NodeTypes() []string {
+	return []string{nodeTypeCreateTableOption}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *tableEngineRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	option, ok := ctx.(*mysql.CreateTableOptionContext)
+	if !ok || option.EngineRef() == nil || len(r.allowed) == 0 {
+		return nil
+	}
+	engine := mysqlparser.NormalizeMySQLTextOrIdentifier(option.EngineRef().TextOrIdentifier())
+	if !r.allowed[strings.ToLower(engine)] {
+		r.AddAdvice(
+			advisor.CodeTableEngineDisallowed,
+			"Table engine",
+			fmt.Sprintf("Table `%s` uses storage engine %s, allowed engines are %s", enclosingTableName(option), engine, strings.Join(r.payload.AllowedEngines, ", ")),
+			option.GetStart(),
+		)
+	}
+	return nil
+}
+
+// TableCharsetPayload is the payload of the table charset rule.
+type TableCharsetPayload struct {
+	// AllowedCharsets and AllowedCollations list the charsets and collations
+	// tables may use, empty allows all.
+	AllowedCharsets   []string `json:"allowed_charsets" yaml:"allowed_charsets"`
+	AllowedCollations []string `json:"allowed_collations" yaml:"allowed_collations"`
+	// RequireCharset requires CREATE TABLE to set the charset or collation
+	// instead of relying on the database default.
+	RequireCharset bool `json:"require_charset" yaml:"require_charset"`
+}
+
+// DefaultTableCharsetPayload returns the payload used for options that are
+// not configured.
+func DefaultTableCharsetPayload() *TableCharsetPayload {
+	return &TableCharsetPayload{
+		AllowedCharsets:   []string{"utf8mb4"},
+		AllowedCollations: []string{"utf8mb4_general_ci", "utf8mb4_unicode_ci", "utf8mb4_0900_ai_ci", "utf8mb4_bin"},
+		RequireCharset:    true,
+	}
+}
+
+// TableCharsetAdvisor checks the charset and collation of tables.
+type TableCharsetAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *TableCharsetAdvisor) Title() string {
+	return "表字符集检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *TableCharsetAdvisor) Description() string {
+	return "建表时必须指定字符集，表的字符集和排序规则必须在允许的列表中"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *TableCharsetAdvisor) DefaultPayload() any {
+	return DefaultTableCharsetPayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *TableCharsetAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *TableCharsetAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultTableCharsetPayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+	return &tableCharsetRule{
+		BaseRule:   advisor.NewBaseRule(checkCtx.Rule),
+		payload:    payload,
+		charsets:   toLowerSet(payload.AllowedCharsets),
+		collations: toLowerSet(payload.AllowedCollations),
+	}, nil
+}
+
+type tableCharsetRule struct {
+	advisor.BaseRule
+
+	payload    *TableCharsetPayload
+	charsets   map[string]bool
+	collations map[string]bool
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *tableCharsetRule) NodeTypes() []string {
+	return []string{nodeTypeCreateTable, nodeTypeCreateTableOption, nodeTypeAlterListItem}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *tableCharsetRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	switch ctx := ctx.(type) {
+	case *mysql.CreateTableContext:
+		if r.payload.RequireCharset && ctx.TableRef() == nil && !hasCharsetOption(ctx.CreateTableOptions()) {
+			_, table := mysqlparser.NormalizeMySQLTableName(ctx.TableName())
+			r.AddAdvice(
+				advisor.CodeTableRequireCharset,
+				"Require table charset",
+				fmt.Sprintf("Table `%s` requires an explicit DEFAULT CHARSET", table),
+				statementStart(ctx),
+			)
+		}
+	case *mysql.CreateTableOptionContext:
+		if ctx.DefaultCharset() != nil {
+			r.checkCharset(enclosingTableName(ctx), ctx.DefaultCharset().CharsetName(), ctx.GetStart())
+		}
+		if ctx.DefaultCollation() != nil {
+			r.checkCollation(enclosingTableName(ctx), ctx.DefaultCollation().CollationName(), ctx.GetStart())
+		}
+	case *mysql.AlterListItemContext:
+		// CONVERT TO CHARACTER SET ... COLLATE ...
+		if ctx.CONVERT_SYMBOL() == nil {
+			return nil
+		}
+		if ctx.CharsetName() != nil {
+			r.checkCharset(enclosingTableName(ctx), ctx.CharsetName(), ctx.GetStart())
+		}
+		if ctx.Collate() != nil {
+			r.checkCollation(enclosingTableName(ctx), ctx.Collate().CollationName(), ctx.Collate().GetStart())
+		}
+	}
+	return nil
+}
+
+func (r *tableCharsetRule) checkCharset(table string, ctx mysql.ICharsetNameContext, token antlr.Token) {
+	if ctx == nil || ctx.TextOrIdentifier() == nil || len(r.charsets) == 0 {
+		return
+	}
+	charset := mysqlparser.NormalizeMySQLTextOrIdentifier(ctx.TextOrIdentifier())
+	if !r.charsets[strings.ToLower(charset)] {
+		r.AddAdvice(
+			advisor.CodeTableCharsetDisallowed,
+			"Table charset",
+			fmt.Sprintf("Table `%s` uses charset %s, allowed charsets are %s", table, charset, strings.Join(r.payload.AllowedCharsets, ", ")),
+			token,
+		)
+	}
+}
+
+func (r *tableCharsetRule) checkCollation(table string, ctx mysql.ICollationNameContext, token antlr.Token) {
+	if ctx == nil || ctx.TextOrIdentifier() == nil || len(r.collations) == 0 {
+		return
+	}
+	collation := mysqlparser.NormalizeMySQLTextOrIdentifier(ctx.TextOrIdentifier())
+	if !r.collations[strings.ToLower(collation)] {
+		r.AddAdvice(
+			advisor.CodeTableCollationDisallowed,
+			"Table collation",
+			fmt.Sprintf("Table `%s` uses collation %s, allowed collations are %s", table, collation, strings.Join(r.payload.AllowedCollations, ", ")),
+			token,
+		)
+	}
+}
+
+// hasCharsetOption reports whether table options set the charset, either
+// directly or through the collation.
+func hasCharsetOption(ctx mysql.ICreateTableOptionsContext) bool {
+	if ctx == nil {
+		return false
+	}
+	for _, option := range ctx.AllCreateTableOption() {
+		if option.DefaultCharset() != nil || option.DefaultCollation() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// TableCommentPayload is the payload of the table comment rule.
+type TableCommentPayload struct {
+	Required bool `json:"required" yaml:"required"`
+	// MaxLength caps table comments in characters, 0 disables the check.
+	MaxLength int `json:"max_length" yaml:"max_length" validate:"min=0,max=2048"`
+}
+
+// DefaultTableCommentPayload returns the payload used for options that are
+// not configured.
+func DefaultTableCommentPayload() *TableCommentPayload {
+	return &TableCommentPayload{Required: true, MaxLength: 256}
+}
+
+// TableCommentAdvisor checks the COMMENT of tables.
+type TableCommentAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *TableCommentAdvisor) Title() string {
+	return "表注释检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *TableCommentAdvisor) Description() string {
+	return "建表时必须添加非空的表注释，注释长度不能超过上限"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *TableCommentAdvisor) DefaultPayload() any {
+	return DefaultTableCommentPayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *TableCommentAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *TableCommentAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultTableCommentPayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+	return &tableCommentRule{
+		BaseRule: advisor.NewBaseRule(checkCtx.Rule),
+		payload:  payload,
+	}, nil
+}
+
+type tableCommentRule struct {
+	advisor.BaseRule
+
+	payload *TableCommentPayload
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *tableCommentRule) NodeTypes() []string {
+	return []string{nodeTypeCreateTable, nodeTypeCreateTableOption}
+}
+
+// OnEnter implements the advisor.NodeListener interface. CREATE TABLE is
+// checked for a missing COMMENT, the COMMENT options of CREATE TABLE and
+// ALTER TABLE for their value.
+func (r *tableCommentRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	switch ctx := ctx.(type) {
+	case *mysql.CreateTableContext:
+		if r.payload.Required && ctx.TableRef() == nil && commentOption(ctx.CreateTableOptions()) == nil {
+			_, table := mysqlparser.NormalizeMySQLTableName(ctx.TableName())
+			r.addRequireCommentAdvice(table, statementStart(ctx))
+		}
+	case *mysql.CreateTableOptionContext:
+		if ctx.GetOption() == nil || ctx.GetOption().GetTokenType() != mysql.MySQLParserCOMMENT_SYMBOL {
+			return nil
+		}
+		table := enclosingTableName(ctx)
+		comment := mysqlparser.NormalizeMySQLTextStringLiteral(ctx.TextStringLiteral())
+		if r.payload.Required && strings.TrimSpace(comment) == "" {
+			r.addRequireCommentAdvice(table, ctx.GetStart())
+		}
+		if length := utf8.RuneCountInString(comment); r.payload.MaxLength > 0 && length > r.payload.MaxLength {
+			r.AddAdvice(
+				advisor.CodeTableCommentTooLong,
+				"Table comment too long",
+				fmt.Sprintf("Comment of table `%s` has %d characters, exceeding the maximum %d", table, length, r.payload.MaxLength),
+				ctx.GetStart(),
+			)
+		}
+	}
+	return nil
+}
+
+func (r *tableCommentRule) addRequireCommentAdvice(table string, token antlr.Token) {
+	r.AddAdvice(advisor.CodeTableRequireComment, "Require table comment", fmt.Sprintf("Table `%s` requires a COMMENT", table), token)
+}
+
+// commentOption returns the COMMENT option of table options.
+func commentOption(ctx mysql.ICreateTableOptionsContext) mysql.ICreateTableOptionContext {
+	if ctx == nil {
+		return nil
+	}
+	for _, option := range ctx.AllCreateTableOption() {
+		if option.GetOption() != nil && option.GetOption().GetTokenType() == mysql.MySQLParserCOMMENT_SYMBOL {
+			return option
+		}
+	}
+	return nil
+}
+
+// TablePartitionPayload is the payload of the table partition rule.
+type TablePartitionPayload struct {
+	// AllowedPartitionTypes lists the partitioning types tables may use,
+	// among "range", "list", "hash" and "key". Empty forbids partitioning.
+	AllowedPartitionTypes []string `json:"allowed_partition_types" yaml:"allowed_partition_types" validate:"enum=range|list|hash|key"`
+}
+
+// DefaultTablePartitionPayload returns the payload used for options that
+// are not configured.
+func DefaultTablePartitionPayload() *TablePartitionPayload {
+	return &TablePartitionPayload{}
+}
+
+// TablePartitionAdvisor checks PARTITION BY of CREATE TABLE and ALTER
+// TABLE.
+type TablePartitionAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *TablePartitionAdvisor) Title() string {
+	return "表分区检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *TablePartitionAdvisor) Description() string {
+	return "默认禁止分区表，可以按分区类型放开"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *TablePartitionAdvisor) DefaultPayload() any {
+	return DefaultTablePartitionPayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *TablePartitionAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *TablePartitionAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultTablePartitionPayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+	return &tablePartitionRule{
+		BaseRule: advisor.NewBaseRule(checkCtx.Rule),
+		allowed:  toLowerSet(payload.AllowedPartitionTypes),
+	}, nil
+}
+
+type tablePartitionRule struct {
+	advisor.BaseRule
+
+	allowed map[string]bool
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *tablePartitionRule) NodeTypes() []string {
+	return []string{nodeTypePartitionClause}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *tablePartitionRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	clause, ok := ctx.(*mysql.PartitionClauseContext)
+	if !ok {
+		return nil
+	}
+	var partitionType string
+	switch def := clause.PartitionTypeDef().(type) {
+	case *mysql.PartitionDefKeyContext:
+		partitionType = "key"
+	case *mysql.PartitionDefHashContext:
+		partitionType = "hash"
+	case *mysql.PartitionDefRangeListContext:
+		partitionType = "list"
+		if def.RANGE_SYMBOL() != nil {
+			partitionType = "range"
+		}
+	}
+	if r.allowed[partitionType] {
+		return nil
+	}
+	content := fmt.Sprintf("Table `%s` must not be partitioned", enclosingTableName(clause))
+	if len(r.allowed) > 0 {
+		content = fmt.Sprintf("Table `%s` uses %s partitioning which is not allowed", enclosingTableName(clause), strings.ToUpper(partitionType))
+	}
+	r.AddAdvice(advisor.CodeTablePartitionDisallowed, "Disallow partitioning", content, clause.GetStart())
+	return nil
+}
+
+// TableNamePrefixPayload is the payload of the table name prefix rule.
+type TableNamePrefixPayload struct {
+	// SchemaPrefixes maps schema names to the prefix of their table names.
+	SchemaPrefixes map[string]string `json:"schema_prefixes" yaml:"schema_prefixes"`
+	// DefaultPrefix is used for schemas without a prefix, empty disables
+	// the check for them.
+	DefaultPrefix string `json:"default_prefix" yaml:"default_prefix"`
+}
+
+// DefaultTableNamePrefixPayload returns the payload used for options that
+// are not configured.
+func DefaultTableNamePrefixPayload() *TableNamePrefixPayload {
+	return &TableNamePrefixPayload{SchemaPrefixes: map[string]string{}}
+}
+
+// TableNamePrefixAdvisor checks that new table names start with the prefix
+// of their schema.
+type TableNamePrefixAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *TableNamePrefixAdvisor) Title() string {
+	return "表名前缀检查"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *TableNamePrefixAdvisor) Description() string {
+	return "新建或重命名的表名必须以所在 schema 配置的前缀开头"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *TableNamePrefixAdvisor) DefaultPayload() any {
+	return DefaultTableNamePrefixPayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *TableNamePrefixAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *TableNamePrefixAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultTableNamePrefixPayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+	prefixes := make(map[string]string, len(payload.SchemaPrefixes))
+	for schema, prefix := range payload.SchemaPrefixes {
+		prefixes[strings.ToLower(schema)] = prefix
+	}
+	return &tableNamePrefixRule{
+		BaseRule:     advisor.NewBaseRule(checkCtx.Rule),
+		payload:      payload,
+		prefixes:     prefixes,
+		databaseName: checkCtx.DatabaseName,
+	}, nil
+}
+
+type tableNamePrefixRule struct {
+	advisor.BaseRule
+
+	payload *TableNamePrefixPayload
+	// prefixes maps lowercase schema names to prefixes.
+	prefixes     map[string]string
+	databaseName string
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *tableNamePrefixRule) NodeTypes() []string {
+	return []string{nodeTypeCreateTable, nodeTypeRenamePair, nodeTypeAlterListItem}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *tableNamePrefixRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	switch ctx := ctx.(type) {
+	case *mysql.CreateTableContext:
+		r.checkTableName(ctx.TableName())
+	case *mysql.RenamePairContext:
+		r.checkTableName(ctx.TableName())
+	case *mysql.AlterListItemContext:
+		// RENAME TO t
+		if ctx.RENAME_SYMBOL() != nil && ctx.TableName() != nil {
+			r.checkTableName(ctx.TableName())
+		}
+	}
+	return nil
+}
+
+func (r *tableNamePrefixRule) checkTableName(ctx mysql.ITableNameContext) {
+	if ctx == nil {
+		return
+	}
+	schema, table := mysqlparser.NormalizeMySQLTableName(ctx)
+	if schema == "" {
+		schema = r.databaseName
+	}
+	prefix, ok := r.prefixes[strings.ToLower(schema)]
+	if !ok {
+		prefix = r.payload.DefaultPrefix
+	}
+	if prefix == "" || strings.HasPrefix(strings.ToLower(table), strings.ToLower(prefix)) {
+		return
+	}
+	content := fmt.Sprintf("Table `%s` should start with prefix `%s`", table, prefix)
+	if schema != "" {
+		content = fmt.Sprintf("Table `%s` in schema `%s` should start with prefix `%s`", table, schema, prefix)
+	}
+	r.AddAdvice(advisor.CodeTableNamePrefixInvalid, "Table name prefix", content, ctx.GetStart())
+}
+
+// TableCreateAsSelectPayload is the payload of the CREATE TABLE ... AS
+// SELECT rule.
+type TableCreateAsSelectPayload struct {
+	// AllowTemporary allows CREATE TEMPORARY TABLE ... AS SELECT.
+	AllowTemporary bool `json:"allow_temporary" yaml:"allow_temporary"`
+}
+
+// DefaultTableCreateAsSelectPayload returns the payload used for options
+// that are not configured.
+func DefaultTableCreateAsSelectPayload() *TableCreateAsSelectPayload {
+	return &TableCreateAsSelectPayload{}
+}
+
+// TableCreateAsSelectAdvisor disallows CREATE TABLE ... AS SELECT, which
+// copies no indexes or constraints and locks the source rows.
+type TableCreateAsSelectAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *TableCreateAsSelectAdvisor) Title() string {
+	return "禁止 CREATE TABLE ... AS SELECT"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *TableCreateAsSelectAdvisor) Description() string {
+	return "CREATE TABLE ... AS SELECT 不会复制索引和约束，且会锁住源表数据，应先建表再 INSERT ... SELECT"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *TableCreateAsSelectAdvisor) DefaultPayload() any {
+	return DefaultTableCreateAsSelectPayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *TableCreateAsSelectAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *TableCreateAsSelectAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultTableCreateAsSelectPayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+	return &tableCreateAsSelectRule{
+		BaseRule: advisor.NewBaseRule(checkCtx.Rule),
+		payload:  payload,
+	}, nil
+}
+
+type tableCreateAsSelectRule struct {
+	advisor.BaseRule
+
+	payload *TableCreateAsSelectPayload
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *tableCreateAsSelectRule) NodeTypes() []string {
+	return []string{nodeTypeCreateTable}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *tableCreateAsSelectRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	createTable, ok := ctx.(*mysql.CreateTableContext)
+	if !ok || createTable.DuplicateAsQueryExpression() == nil {
+		return nil
+	}
+	if r.payload.AllowTemporary && createTable.TEMPORARY_SYMBOL() != nil {
+		return nil
+	}
+	_, table := mysqlparser.NormalizeMySQLTableName(createTable.TableName())
+	r.AddAdvice(
+		advisor.CodeTableCreateAsSelectDisallowed,
+		"Disallow CREATE TABLE ... AS SELECT",
+		fmt.Sprintf("Table `%s` is created from a query, create the table first and then INSERT ... SELECT", table),
+		statementStart(createTable),
+	)
+	return nil
+}
+
+// TableCreateLikePayload is the payload of the CREATE TABLE ... LIKE rule.
+type TableCreateLikePayload struct {
+	// AllowTemporary allows CREATE TEMPORARY TABLE ... LIKE.
+	AllowTemporary bool `json:"allow_temporary" yaml:"allow_temporary"`
+}
+
+// DefaultTableCreateLikePayload returns the payload used for options that
+// are not configured.
+func DefaultTableCreateLikePayload() *TableCreateLikePayload {
+	return &TableCreateLikePayload{}
+}
+
+// TableCreateLikeAdvisor disallows CREATE TABLE ... LIKE, whose columns
+// cannot be reviewed from the statement.
+type TableCreateLikeAdvisor struct{}
+
+// Title implements the advisor.Describer interface.
+func (a *TableCreateLikeAdvisor) Title() string {
+	return "禁止 CREATE TABLE ... LIKE"
+}
+
+// Description implements the advisor.Describer interface.
+func (a *TableCreateLikeAdvisor) Description() string {
+	return "CREATE TABLE ... LIKE 的表结构无法从语句中审查，应显式写出建表语句"
+}
+
+// DefaultPayload implements the advisor.PayloadAdvisor interface.
+func (a *TableCreateLikeAdvisor) DefaultPayload() any {
+	return DefaultTableCreateLikePayload()
+}
+
+// Check implements the advisor.Advisor interface.
+func (a *TableCreateLikeAdvisor) Check(ctx context.Context, checkCtx advisor.Context) ([]*advisor.Advice, error) {
+	return advisor.CheckWithListener(ctx, a, checkCtx)
+}
+
+// NewListener implements the advisor.ListenerAdvisor interface.
+func (a *TableCreateLikeAdvisor) NewListener(checkCtx advisor.Context) (advisor.NodeListener, error) {
+	payload := DefaultTableCreateLikePayload()
+	if err := checkCtx.Rule.UnmarshalPayload(payload); err != nil {
+		return nil, err
+	}
+	return &tableCreateLikeRule{
+		BaseRule: advisor.NewBaseRule(checkCtx.Rule),
+		payload:  payload,
+	}, nil
+}
+
+type tableCreateLikeRule struct {
+	advisor.BaseRule
+
+	payload *TableCreateLikePayload
+}
+
+// NodeTypes implements the advisor.NodeListener interface.
+func (r *tableCreateLikeRule) NodeTypes() []string {
+	return []string{nodeTypeCreateTable}
+}
+
+// OnEnter implements the advisor.NodeListener interface.
+func (r *tableCreateLikeRule) OnEnter(ctx antlr.ParserRuleContext, nodeType string) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	createTable, ok := ctx.(*mysql.CreateTableContext)
+	if !ok || createTable.TableRef() == nil {
+		return nil
+	}
+	if r.payload.AllowTemporary && createTable.TEMPORARY_SYMBOL() != nil {
+		return nil
+	}
+	_, table := mysqlparser.NormalizeMySQLTableName(createTable.TableName())
+	_, source := mysqlparser.NormalizeMySQLTableRef(createTable.TableRef())
+	r.AddAdvice(
+		advisor.CodeTableCreateLikeDisallowed,
+		"Disallow CREATE TABLE ... LIKE",
+		fmt.Sprintf("Table `%s` is created LIKE `%s`, write out its definition instead", table, source),
+		statementStart(createTable),
+	)
+	return nil
+}
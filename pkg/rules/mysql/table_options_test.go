@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+// TestTableOptions 测试表级选项相关的规则
+func TestTableOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		ruleType  advisor.Type
+		payload   any
+		statement string
+		expected  []string
+	}{
+		{
+			name:      "Allowed engine",
+			ruleType:  advisor.MySQLTableEngine,
+			statement: "CREATE TABLE t (id INT) ENGINE=InnoDB;\nALTER TABLE t ENGINE = 'innodb';",
+		},
+		{
+			name:      "Disallowed engine",
+			ruleType:  advisor.MySQLTableEngine,
+			statement: "CREATE TABLE t (id INT) ENGINE=MyISAM;\nALTER TABLE t ENGINE = MEMORY;",
+			expected:  []string{"1:25 805", "2:15 805"},
+		},
+		{
+			name:      "Missing charset",
+			ruleType:  advisor.MySQLTableCharset,
+			statement: "CREATE TABLE t (id INT);\nCREATE TABLE t2 (id INT) COLLATE utf8mb4_bin;\nCREATE TABLE t3 LIKE t;",
+			expected:  []string{"1:1 806"},
+		},
+		{
+			name:      "Disallowed charset and collation",
+			ruleType:  advisor.MySQLTableCharset,
+			statement: "CREATE TABLE t (id INT) DEFAULT CHARSET=latin1 COLLATE=latin1_swedish_ci;\nALTER TABLE t CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;\nALTER TABLE t CONVERT TO CHARACTER SET utf8 COLLATE utf8_general_ci;",
+			expected:  []string{"1:25 807", "1:48 808", "3:15 807", "3:45 808"},
+		},
+		{
+			name:      "Table comment",
+			ruleType:  advisor.MySQLTableComment,
+			payload:   map[string]any{"max_length": 10},
+			statement: "CREATE TABLE a (id INT) COMMENT 'users';\nCREATE TABLE b (id INT);\nCREATE TABLE c (id INT) COMMENT = '';\nALTER TABLE a COMMENT 'a very long comment';",
+			expected:  []string{"2:1 809", "3:25 809", "4:15 810"},
+		},
+		{
+			name:      "Partitioning",
+			ruleType:  advisor.MySQLTablePartition,
+			statement: "CREATE TABLE t (id INT) PARTITION BY HASH(id) PARTITIONS 4;\nALTER TABLE t PARTITION BY RANGE (id) (PARTITION p0 VALUES LESS THAN (10));",
+			expected:  []string{"1:25 811", "2:15 811"},
+		},
+		{
+			name:      "Allowed partition types",
+			ruleType:  advisor.MySQLTablePartition,
+			payload:   map[string]any{"allowed_partition_types": []string{"range"}},
+			statement: "CREATE TABLE t (id INT) PARTITION BY RANGE (id) (PARTITION p0 VALUES LESS THAN (10));\nCREATE TABLE t2 (id INT) PARTITION BY KEY (id);",
+			expected:  []string{"2:26 811"},
+		},
+		{
+			name:      "Table name prefix",
+			ruleType:  advisor.MySQLTableNamePrefix,
+			payload:   map[string]any{"schema_prefixes": map[string]string{"orders": "ord_"}, "default_prefix": "t_"},
+			statement: "CREATE TABLE orders.ord_items (id INT);\nCREATE TABLE orders.items (id INT);\nCREATE TABLE t_users (id INT);\nRENAME TABLE t_users TO users;\nALTER TABLE orders.ord_items RENAME TO orders.line_items;",
+			expected:  []string{"2:14 812", "4:25 812", "5:40 812"},
+		},
+		{
+			name:      "No prefix configured",
+			ruleType:  advisor.MySQLTableNamePrefix,
+			statement: "CREATE TABLE users (id INT);",
+		},
+		{
+			name:      "CREATE TABLE ... AS SELECT",
+			ruleType:  advisor.MySQLTableCreateAsSelect,
+			payload:   map[string]any{"allow_temporary": true},
+			statement: "CREATE TABLE t AS SELECT id FROM users;\nCREATE TEMPORARY TABLE tmp AS SELECT id FROM users;\nCREATE TABLE t2 (id INT);",
+			expected:  []string{"1:1 813"},
+		},
+		{
+			name:      "CREATE TABLE ... LIKE",
+			ruleType:  advisor.MySQLTableCreateLike,
+			statement: "CREATE TABLE t LIKE users;\nCREATE TEMPORARY TABLE tmp LIKE users;",
+			expected:  []string{"1:1 814", "2:1 814"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checkRule(t, tt.ruleType, tt.payload, tt.statement)
+			if !reflect.DeepEqual(results, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, results)
+			}
+		})
+	}
+}
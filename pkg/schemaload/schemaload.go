@@ -0,0 +1,165 @@
+// Package schemaload builds advisor.DatabaseMetadata from a schema given
+// offline, so that schema-aware rules also run where there is no database
+// to connect to, such as CI.
+package schemaload
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+	"github.com/shenbo/sql-review-learning-demo/pkg/catalog"
+	"github.com/shenbo/sql-review-learning-demo/pkg/database"
+	mysqlparser "github.com/shenbo/sql-review-learning-demo/pkg/parser/mysql"
+)
+
+// Load reads a baseline schema from path, which is one of
+//   - a directory of .sql files with CREATE statements, applied in file
+//     name order,
+//   - a .sql file with CREATE statements,
+//   - a .json file holding a database.SchemaInfo, as returned by
+//     GET /api/schema/:connection_id.
+func Load(path string) (*advisor.DatabaseMetadata, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		files, err := filepath.Glob(filepath.Join(path, "*.sql"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		return fromSQLFiles(files)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var schema database.SchemaInfo
+		if err := json.Unmarshal(content, &schema); err != nil {
+			return nil, fmt.Errorf("%s: invalid schema dump: %w", path, err)
+		}
+		return FromSchemaInfo(&schema), nil
+	}
+	return fromSQLFiles([]string{path})
+}
+
+// FromSQL builds the metadata of the schema created by the statements.
+func FromSQL(statement string) (*advisor.DatabaseMetadata, error) {
+	c := catalog.New("")
+	if err := walkThrough(c, statement); err != nil {
+		return nil, err
+	}
+	return fromCatalog(c), nil
+}
+
+// FromSchemaInfo converts the schema read by database.SchemaManager.
+func FromSchemaInfo(schema *database.SchemaInfo) *advisor.DatabaseMetadata {
+	metadata := &advisor.DatabaseMetadata{Tables: make(map[string]*advisor.TableMetadata, len(schema.Tables))}
+	for _, table := range schema.Tables {
+		tableMetadata := &advisor.TableMetadata{
+			Name:     table.Name,
+			Columns:  make(map[string]*advisor.ColumnMetadata, len(table.Columns)),
+			Indexes:  make(map[string]*advisor.IndexMetadata, len(table.Indexes)),
+			RowCount: table.RowCount,
+		}
+		for _, column := range table.Columns {
+			tableMetadata.Columns[column.Name] = &advisor.ColumnMetadata{
+				Name:         column.Name,
+				Type:         column.Type,
+				IsNullable:   column.IsNullable,
+				IsPrimaryKey: column.IsPrimaryKey,
+				IsAutoIncr:   column.IsAutoIncr,
+			}
+		}
+		for _, index := range table.Indexes {
+			tableMetadata.Indexes[index.Name] = &advisor.IndexMetadata{
+				Name:    index.Name,
+				Type:    index.Type,
+				Columns: index.Columns,
+			}
+		}
+		metadata.Tables[table.Name] = tableMetadata
+	}
+	return metadata
+}
+
+func fromSQLFiles(files []string) (*advisor.DatabaseMetadata, error) {
+	c := catalog.New("")
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := walkThrough(c, string(content)); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return fromCatalog(c), nil
+}
+
+// walkThrough applies the statements to the catalog. A schema that does not
+// parse or does not apply cleanly is rejected rather than half loaded.
+func walkThrough(c *catalog.Catalog, statement string) error {
+	stmts, err := mysqlparser.ParseMySQL(statement)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if errs := c.WalkThrough(stmt); len(errs) > 0 {
+			return errs[0]
+		}
+	}
+	return nil
+}
+
+// fromCatalog converts the tables of a catalog, with indexes typed
+// PRIMARY, UNIQUE or INDEX like database.SchemaManager reads them, and
+// FULLTEXT and SPATIAL indexes typed FULLTEXT.
+func fromCatalog(c *catalog.Catalog) *advisor.DatabaseMetadata {
+	metadata := &advisor.DatabaseMetadata{Tables: make(map[string]*advisor.TableMetadata)}
+	for _, table := range c.Tables() {
+		tableMetadata := &advisor.TableMetadata{
+			Name:     table.Name,
+			Columns:  make(map[string]*advisor.ColumnMetadata, len(table.Columns)),
+			Indexes:  make(map[string]*advisor.IndexMetadata, len(table.Indexes)),
+			RowCount: table.RowCount,
+		}
+		primaryKey := table.PrimaryKey()
+		for _, column := range table.Columns {
+			tableMetadata.Columns[column.Name] = &advisor.ColumnMetadata{
+				Name:         column.Name,
+				Type:         column.Type,
+				IsNullable:   column.Nullable,
+				IsPrimaryKey: primaryKey != nil && primaryKey.HasColumn(column.Name),
+				IsAutoIncr:   column.AutoIncrement,
+			}
+		}
+		for _, index := range table.Indexes {
+			indexType := "INDEX"
+			switch {
+			case index.Primary:
+				indexType = "PRIMARY"
+			case index.Unique:
+				indexType = "UNIQUE"
+			case index.Fulltext:
+				indexType = "FULLTEXT"
+			}
+			tableMetadata.Indexes[index.Name] = &advisor.IndexMetadata{
+				Name:    index.Name,
+				Type:    indexType,
+				Columns: index.Columns,
+			}
+		}
+		metadata.Tables[table.Name] = tableMetadata
+	}
+	return metadata
+}
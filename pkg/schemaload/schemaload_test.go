@@ -0,0 +1,112 @@
+package schemaload
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shenbo/sql-review-learning-demo/pkg/advisor"
+)
+
+// TestLoadDirectory 测试从 CREATE 语句目录构建元数据
+func TestLoadDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"001_users.sql":  "CREATE TABLE users (\n  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n  email VARCHAR(255) NOT NULL,\n  PRIMARY KEY (id),\n  UNIQUE KEY uk_email (email)\n);",
+		"002_orders.sql": "CREATE TABLE orders (id BIGINT PRIMARY KEY, user_id BIGINT, KEY (user_id));\nALTER TABLE users ADD COLUMN name VARCHAR(50);",
+		"README.md":      "not a schema file",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	metadata, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	users := metadata.GetTable("users")
+	if users == nil {
+		t.Fatal("Expected table users")
+	}
+	if id := users.Columns["id"]; id == nil || id.Type != "bigint unsigned" || id.IsNullable || !id.IsPrimaryKey || !id.IsAutoIncr {
+		t.Errorf("Unexpected column id: %+v", id)
+	}
+	if name := users.Columns["name"]; name == nil || !name.IsNullable {
+		t.Errorf("Unexpected column name: %+v", name)
+	}
+	if index := users.Indexes["uk_email"]; index == nil || index.Type != "UNIQUE" || !reflect.DeepEqual(index.Columns, []string{"email"}) {
+		t.Errorf("Unexpected index uk_email: %+v", index)
+	}
+
+	orders := metadata.GetTable("orders")
+	if orders == nil {
+		t.Fatal("Expected table orders")
+	}
+	if index := orders.Indexes["user_id"]; index == nil || index.Type != "INDEX" {
+		t.Errorf("Expected unnamed index user_id, got %+v", orders.Indexes)
+	}
+	if index := orders.Indexes["PRIMARY"]; index == nil || index.Type != "PRIMARY" {
+		t.Errorf("Expected primary key, got %+v", orders.Indexes)
+	}
+}
+
+// TestFromSQLFulltextRoundTrip 测试 FULLTEXT 索引经元数据构建的 catalog 仍是全文索引
+func TestFromSQLFulltextRoundTrip(t *testing.T) {
+	metadata, err := FromSQL("CREATE TABLE posts (id BIGINT PRIMARY KEY, title VARCHAR(100), body TEXT, KEY idx_title (title), FULLTEXT KEY ft_body (body));")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if index := metadata.GetTable("posts").Indexes["ft_body"]; index == nil || index.Type != "FULLTEXT" {
+		t.Errorf("Expected FULLTEXT index ft_body, got %+v", index)
+	}
+
+	posts := advisor.NewCatalog("", metadata).Table("", "posts")
+	if posts == nil {
+		t.Fatal("Expected table posts")
+	}
+	if index := posts.Index("ft_body"); index == nil || !index.Fulltext || index.Unique {
+		t.Errorf("Expected fulltext index ft_body, got %+v", index)
+	}
+	if index := posts.Index("idx_title"); index == nil || index.Fulltext {
+		t.Errorf("Expected plain index idx_title, got %+v", index)
+	}
+}
+
+// TestLoadJSON 测试从 SchemaInfo 的 JSON 导出构建元数据
+func TestLoadJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	content := `{"database_name": "app", "tables": [{"name": "users", "row_count": 42,
+		"columns": [{"name": "id", "type": "bigint", "is_primary_key": true}],
+		"indexes": [{"name": "PRIMARY", "type": "PRIMARY", "columns": ["id"]}]}]}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	users := metadata.GetTable("users")
+	if users == nil || users.RowCount != 42 || users.Columns["id"] == nil || users.Indexes["PRIMARY"] == nil {
+		t.Errorf("Unexpected table users: %+v", users)
+	}
+}
+
+// TestLoadInvalidSchema 测试无法应用的 schema 文件会报错并指出文件
+func TestLoadInvalidSchema(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "001.sql")
+	if err := os.WriteFile(path, []byte("CREATE TABLE t (id INT);\nCREATE TABLE t (id INT);"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(dir)
+	if err == nil || !strings.Contains(err.Error(), "001.sql") || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected error on 001.sql, got %v", err)
+	}
+}